@@ -2,16 +2,21 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/eugener/clix/config"
 	"github.com/eugener/clix/core"
+	"github.com/eugener/clix/internal/complete"
 	"github.com/eugener/clix/internal/configfile"
 	"github.com/eugener/clix/internal/help"
 	"github.com/eugener/clix/internal/interactive"
+	"github.com/eugener/clix/internal/posix"
 )
 
 // Application represents a complete CLI application
@@ -23,6 +28,17 @@ type Application struct {
 	errorFormat  *help.ErrorFormatter
 	suggestions  *help.SuggestionEngine
 	prompter     *interactive.SmartPrompter
+	services     *core.Services
+	globalFlags  []globalFlag
+	helpTopics   []core.HelpTopic
+	
+	// Per-run state set from global flags
+	profile      string
+	profileChain []string
+	configPath   string
+	
+	inShell     bool         // Whether the shell is running, which cannot be nested
+	shellConfig *shellConfig // Config layers loaded for the lines of the shell
 }
 
 // NewApplication creates a new CLI application with the given configuration
@@ -40,8 +56,26 @@ func NewApplication(cfg *config.CLIConfig) *Application {
 		executor.SetLogger(cfg.Logger)
 	}
 	
+	executor.SetAbbreviations(cfg.Abbreviations)
+	executor.SetLenientFlags(cfg.LenientFlags)
+	if cfg.Dialect != nil {
+		executor.SetDialect(cfg.Dialect)
+	}
+	
+	services := cfg.Services
+	if services == nil {
+		services = core.NewServices()
+	}
+	executor.SetServices(services)
+	
+	for scheme, provider := range cfg.SecretProviders {
+		executor.SetSecretProvider(scheme, provider)
+	}
+	
 	// Create help generator
 	helpGen := help.NewGenerator(cfg.HelpConfig)
+	helpGen.AddGlobalFlags(globalFlagsHelp(builtinGlobalFlags)...)
+	helpGen.SetDialect(executor.Dialect(""))
 	
 	// Create error formatter and suggestion engine
 	errorFormat := help.NewErrorFormatter(cfg.Name, cfg.HelpConfig.ColorEnabled)
@@ -50,7 +84,7 @@ func NewApplication(cfg *config.CLIConfig) *Application {
 	// Create interactive prompter
 	prompter := interactive.NewSmartPrompter()
 	
-	return &Application{
+	app := &Application{
 		config:      cfg,
 		registry:    registry,
 		executor:    executor,
@@ -58,7 +92,16 @@ func NewApplication(cfg *config.CLIConfig) *Application {
 		errorFormat: errorFormat,
 		suggestions: suggestions,
 		prompter:    prompter,
+		services:    services,
+		globalFlags: slices.Clone(builtinGlobalFlags),
 	}
+	
+	// Reload config files for long-running commands when enabled
+	if cfg.ConfigReload > 0 && cfg.AutoLoadConfig {
+		executor.SetReloadWatcher(app.watchConfig)
+	}
+	
+	return app
 }
 
 // NewApplicationWithOptions creates a new CLI application with functional options
@@ -73,6 +116,24 @@ func (app *Application) Register(cmd any) error {
 	return app.registry.Register(cmd)
 }
 
+// Register adds a command to the application with its config type known at compile
+// time, e.g. app.Register[DeployConfig](application, &DeployCommand{})
+func Register[T any](app *Application, cmd core.Command[T]) error {
+	return core.Register(app.registry, cmd)
+}
+
+// Provide registers a lazy provider of a service shared by commands, e.g.
+// app.Provide(application, openDB); commands get it with core.Service or inject fields
+func Provide[T any](app *Application, provider func(ctx context.Context) (T, error)) {
+	core.Provide(app.services, provider)
+}
+
+// RegisterLazy adds a command by name and description, built by the factory only
+// when it runs, e.g. app.RegisterLazy(application, "deploy", "Deploy", newDeploy)
+func RegisterLazy[T any](app *Application, name, description string, factory func() core.Command[T]) error {
+	return core.RegisterLazy(app.registry, name, description, factory)
+}
+
 // RegisterCommands adds multiple commands to the application
 func (app *Application) RegisterCommands(commands ...any) error {
 	for _, cmd := range commands {
@@ -85,9 +146,70 @@ func (app *Application) RegisterCommands(commands ...any) error {
 
 // Run executes the CLI application with the given arguments
 func (app *Application) Run(ctx context.Context, args []string) int {
+	// Expand @file arguments before anything else reads them
+	if app.config.ArgsFiles {
+		expanded, err := app.expandArgsFiles(args)
+		if err != nil {
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+			return app.config.ErrorHandler(err)
+		}
+		args = expanded
+	}
+	
+	// Extract application-level flags such as --profile
+	args, err := app.extractGlobalFlags(args)
+	if err != nil {
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	
+	// Hooks only ever see the arguments with secret values redacted
+	hookArgs, prepared, err := app.prepareArgs(ctx, args)
+	if err != nil {
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	
+	return app.session(ctx, hookArgs, func(ctx context.Context) int {
+		return app.dispatch(ctx, args, prepared)
+	})
+}
+
+// prepareArgs resolves the secret values of the registered command named by args once,
+// returning the arguments with those values redacted along with the prepared command
+// arguments. Other arguments, such as built-in commands, are returned unchanged.
+func (app *Application) prepareArgs(ctx context.Context, args []string) ([]string, *core.PreparedArgs, error) {
+	if len(args) == 0 {
+		return args, nil, nil
+	}
+	
+	commandName := args[0]
+	if app.config.Abbreviations {
+		if name, err := app.resolveCommandName(commandName); err == nil {
+			commandName = name
+		}
+	}
+	if _, exists := app.registry.GetCommand(commandName); !exists || app.isCommandHelpRequest(commandName, args[1:]) {
+		return args, nil, nil
+	}
+	
+	prepared, err := app.executor.PrepareArgs(ctx, commandName, args[1:])
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	return append([]string{args[0]}, prepared.Redacted...), prepared, nil
+}
+
+// session runs body between the before and after all hooks, with the services in
+// its context. Services built in the session are closed when it ends.
+func (app *Application) session(ctx context.Context, args []string, body func(ctx context.Context) int) int {
+	// Hooks and commands get their services through the context
+	ctx = core.WithServices(ctx, app.services)
+	
 	// Apply before all hook
 	if app.config.BeforeAll != nil {
-		execCtx := core.NewExecutionContext(ctx, "", args)
+		execCtx := app.newExecutionContext(ctx, "", args)
 		if err := app.config.BeforeAll(execCtx); err != nil {
 			fmt.Fprintf(os.Stderr, "Before all hook failed: %v\n", err)
 			return app.config.ErrorHandler(err)
@@ -97,26 +219,67 @@ func (app *Application) Run(ctx context.Context, args []string) int {
 	defer func() {
 		// Apply after all hook
 		if app.config.AfterAll != nil {
-			execCtx := core.NewExecutionContext(ctx, "", args)
+			execCtx := app.newExecutionContext(ctx, "", args)
 			if err := app.config.AfterAll(execCtx); err != nil {
 				fmt.Fprintf(os.Stderr, "After all hook failed: %v\n", err)
 			}
 		}
+		
+		// Services built for this run are closed after the hooks that may use them
+		if err := app.services.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Closing services failed: %v\n", err)
+		}
 	}()
 	
+	return body(ctx)
+}
+
+// dispatch runs the built-in or registered command named by the first argument,
+// with its arguments from prepareArgs when they were prepared already
+func (app *Application) dispatch(ctx context.Context, args []string, prepared *core.PreparedArgs) int {
 	// Handle no arguments - show main help
 	if len(args) == 0 {
 		app.showMainHelp()
 		return 0
 	}
 	
+	// Resolve an abbreviated command name
+	if app.config.Abbreviations {
+		name, err := app.resolveCommandName(args[0])
+		if err != nil {
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+			return app.config.ErrorHandler(err)
+		}
+		args[0] = name
+	}
+	
 	// Handle global help requests
 	if app.isHelpRequest(args[0]) {
 		return app.handleHelp(args)
 	}
 	
-	// Check if command exists before proceeding
+	// Handle the built-in config command
+	if app.isConfigCommand(args[0]) {
+		return app.handleConfigCommand(ctx, args[1:])
+	}
+	
+	// Handle the built-in schema command
+	if app.isSchemaCommand(args[0]) {
+		return app.handleSchemaCommand(args[1:])
+	}
+	
+	// Handle the built-in shell command
+	if app.isShellCommand(args[0]) {
+		return app.handleShellCommand(ctx, args[1:])
+	}
+	
+	// Run an external plugin for a command that is not registered
 	commandName := args[0]
+	if path, plugin := app.findPlugin(commandName); plugin {
+		return app.runPlugin(ctx, path, args[1:])
+	}
+	
+	// Check if command exists before proceeding
 	if _, exists := app.registry.GetCommand(commandName); !exists {
 		// Unknown command error with suggestions
 		allCommands := app.getAllCommandNames()
@@ -146,9 +309,22 @@ func (app *Application) Run(ctx context.Context, args []string) int {
 	// Execute command  
 	commandArgs := args[1:]
 	
+	// Show command help for --help among the command's flags
+	if app.isCommandHelpRequest(commandName, commandArgs) {
+		return app.handleHelp([]string{"help", commandName})
+	}
+	
+	if prepared == nil {
+		var err error
+		if prepared, err = app.executor.PrepareArgs(ctx, commandName, commandArgs); err != nil {
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, app.buildErrorContext(err, commandName, nil)))
+			return app.config.ErrorHandler(err)
+		}
+	}
+	
 	// Apply before each hook
 	if app.config.BeforeEach != nil {
-		execCtx := core.NewExecutionContext(ctx, commandName, commandArgs)
+		execCtx := app.newExecutionContext(ctx, commandName, prepared.Redacted)
 		if err := app.config.BeforeEach(execCtx); err != nil {
 			fmt.Fprintf(os.Stderr, "Before each hook failed: %v\n", err)
 			return app.config.ErrorHandler(err)
@@ -158,7 +334,7 @@ func (app *Application) Run(ctx context.Context, args []string) int {
 	defer func() {
 		// Apply after each hook
 		if app.config.AfterEach != nil {
-			execCtx := core.NewExecutionContext(ctx, commandName, commandArgs)
+			execCtx := app.newExecutionContext(ctx, commandName, prepared.Redacted)
 			if err := app.config.AfterEach(execCtx); err != nil {
 				fmt.Fprintf(os.Stderr, "After each hook failed: %v\n", err)
 			}
@@ -168,28 +344,30 @@ func (app *Application) Run(ctx context.Context, args []string) int {
 	// Load configuration file if enabled
 	var baseConfig any
 	if app.config.AutoLoadConfig {
+		// Unknown keys are only warnings unless strict; any error here is a file the
+		// command cannot run with, e.g. a value of the wrong type
 		config, err := app.loadConfigurationFile(commandName, commandArgs)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to load configuration file: %v\n", err)
-		} else {
-			baseConfig = config
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+			return app.config.ErrorHandler(err)
 		}
+		baseConfig = config
 	}
 	
 	// Execute the command with base config
-	if err := app.executor.ExecuteWithConfig(ctx, commandName, commandArgs, baseConfig); err != nil {
+	if err := app.executor.ExecutePrepared(ctx, prepared, baseConfig); err != nil {
 		// Check if this is a missing required field error and interactive mode is enabled
 		if app.config.InteractiveMode && app.isMissingRequiredFieldError(err) {
 			if interactiveErr := app.handleInteractivePrompt(ctx, commandName, commandArgs, err); interactiveErr == nil {
 				// Successfully prompted and got values, try again
-				if retryErr := app.executor.ExecuteWithConfig(ctx, commandName, commandArgs, baseConfig); retryErr == nil {
+				if retryErr := app.executor.ExecutePrepared(ctx, prepared, baseConfig); retryErr == nil {
 					return 0 // Success after interactive prompting
 				}
 			}
 		}
 		
 		// Enhanced error formatting
-		errorCtx := app.buildErrorContext(err, commandName, commandArgs)
+		errorCtx := app.buildErrorContext(err, commandName, prepared.Redacted)
 		formattedError := app.errorFormat.FormatError(err, errorCtx)
 		fmt.Fprint(os.Stderr, formattedError)
 		return app.config.ErrorHandler(err)
@@ -198,6 +376,13 @@ func (app *Application) Run(ctx context.Context, args []string) int {
 	return 0
 }
 
+// newExecutionContext creates an execution context for hooks carrying the active profile
+func (app *Application) newExecutionContext(ctx context.Context, commandName string, args []string) *core.ExecutionContext {
+	execCtx := core.NewExecutionContext(ctx, commandName, args)
+	execCtx.Profile = app.profile
+	return execCtx
+}
+
 // RunWithArgs executes the CLI application with os.Args
 func (app *Application) RunWithArgs(ctx context.Context) int {
 	return app.Run(ctx, os.Args[1:])
@@ -213,6 +398,30 @@ func (app *Application) showMainHelp() {
 			ConfigType:  desc.GetConfigType(),
 		}
 	}
+	for name := range app.discoverPlugins() {
+		commands[name] = help.CommandInfo{
+			Name:        name,
+			Description: "External plugin",
+		}
+	}
+	if app.isConfigCommand(configCommandName) {
+		commands[configCommandName] = help.CommandInfo{
+			Name:        configCommandName,
+			Description: "Inspect and modify configuration",
+		}
+	}
+	if app.isSchemaCommand(schemaCommandName) {
+		commands[schemaCommandName] = help.CommandInfo{
+			Name:        schemaCommandName,
+			Description: "Print the JSON Schema of the configuration file",
+		}
+	}
+	if app.isShellCommand(shellCommandName) {
+		commands[shellCommandName] = help.CommandInfo{
+			Name:        shellCommandName,
+			Description: "Run commands interactively",
+		}
+	}
 	fmt.Print(app.helpGen.GenerateMainHelp(commands))
 }
 
@@ -226,6 +435,7 @@ func (app *Application) handleHelp(args []string) int {
 				Name:        desc.GetName(),
 				Description: desc.GetDescription(),
 				ConfigType:  desc.GetConfigType(),
+				Dialect:     app.executor.Dialect(cmdName),
 				Examples: []string{
 					fmt.Sprintf("%s %s [options]", app.config.Name, cmdName),
 				},
@@ -236,6 +446,10 @@ func (app *Application) handleHelp(args []string) int {
 				return 1
 			}
 			fmt.Print(helpText)
+		} else if topic, found := app.findHelpTopic(cmdName); found {
+			fmt.Println(strings.TrimRight(topic.Text, "\n"))
+		} else if path, plugin := app.findPlugin(cmdName); plugin {
+			return app.runPlugin(context.Background(), path, []string{"--help"})
 		} else {
 			fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmdName)
 			return 1
@@ -252,6 +466,25 @@ func (app *Application) isHelpRequest(arg string) bool {
 	return arg == "help" || arg == "--help" || arg == "-h"
 }
 
+// isCommandHelpRequest reports whether the command's flags include --help or -h,
+// unless the command defines flags of that name itself
+func (app *Application) isCommandHelpRequest(commandName string, args []string) bool {
+	metadata := app.commandMetadata(commandName)
+	if metadata == nil {
+		return false
+	}
+	
+	for _, arg := range args[:app.executor.FlagsEnd(commandName, args)] {
+		if _, defined := metadata.FieldMap["help"]; arg == "--help" && !defined {
+			return true
+		}
+		if _, defined := metadata.ShortMap["h"]; arg == "-h" && !defined {
+			return true
+		}
+	}
+	return false
+}
+
 // isVersionRequest checks if the argument is a version request
 func (app *Application) isVersionRequest(arg string) bool {
 	return arg == "version" || arg == "--version" || arg == "-v"
@@ -277,6 +510,21 @@ func (app *Application) GetHelpGenerator() *help.Generator {
 	return app.helpGen
 }
 
+// GetCompletionGenerator returns a completion generator for the commands of the
+// application, including discovered plugins
+func (app *Application) GetCompletionGenerator() *complete.Generator {
+	generator := complete.NewGenerator(app.registry)
+	generator.SetArgsFiles(app.config.ArgsFiles)
+	generator.SetExternalCommands(func() map[string]string {
+		commands := make(map[string]string)
+		for name := range app.discoverPlugins() {
+			commands[name] = "External plugin"
+		}
+		return commands
+	})
+	return generator
+}
+
 // buildErrorContext builds error context for better error messages
 func (app *Application) buildErrorContext(err error, commandName string, args []string) *help.ErrorContext {
 	errorMsg := err.Error()
@@ -297,6 +545,10 @@ func (app *Application) buildErrorContext(err error, commandName string, args []
 	if strings.Contains(errorMsg, "unknown flag") {
 		// Extract flag from error message
 		flag := app.extractFlagFromError(errorMsg)
+		var unknownFlag *posix.UnknownFlagError
+		if errors.As(err, &unknownFlag) {
+			flag = unknownFlag.Flag
+		}
 		allFlags := app.getAllFlagsForCommand(commandName)
 		suggestions := app.suggestions.SuggestFlags(flag, allFlags)
 		
@@ -309,6 +561,14 @@ func (app *Application) buildErrorContext(err error, commandName string, args []
 			Build()
 	}
 	
+	// Positional argument errors name the argument themselves
+	if strings.Contains(errorMsg, "missing argument") || strings.Contains(errorMsg, "unexpected argument") {
+		return help.NewErrorContext().
+			Type(help.ErrorTypeGeneric).
+			Command(commandName).
+			Build()
+	}
+	
 	if strings.Contains(errorMsg, "required field") || strings.Contains(errorMsg, "missing") {
 		// Extract field from error message
 		field := app.extractFieldFromError(errorMsg)
@@ -341,20 +601,75 @@ func (app *Application) buildErrorContext(err error, commandName string, args []
 		Build()
 }
 
+// resolveCommandName expands a unique prefix of a command name, including the built-in
+// commands. Names that match nothing are returned unchanged.
+func (app *Application) resolveCommandName(name string) (string, error) {
+	if strings.HasPrefix(name, "-") {
+		return name, nil
+	}
+	
+	// A full name needs no plugin lookup in the PATH
+	if _, exists := app.registry.GetCommand(name); exists || app.isConfigCommand(name) || app.isSchemaCommand(name) || app.isShellCommand(name) {
+		return name, nil
+	}
+	
+	names := app.getAllCommandNames()
+	for _, builtin := range []string{configCommandName, schemaCommandName, shellCommandName} {
+		if app.isConfigCommand(builtin) || app.isSchemaCommand(builtin) || app.isShellCommand(builtin) {
+			names = append(names, builtin)
+		}
+	}
+	
+	match, candidates := posix.MatchPrefix(name, names)
+	if len(candidates) > 0 {
+		return "", &posix.AmbiguousError{Input: "command " + name, Candidates: candidates}
+	}
+	if match == "" {
+		return name, nil
+	}
+	return match, nil
+}
+
 // getAllCommandNames returns all available command names
 func (app *Application) getAllCommandNames() []string {
 	var commands []string
 	for name := range app.registry.ListCommands() {
 		commands = append(commands, name)
 	}
+	for name := range app.discoverPlugins() {
+		commands = append(commands, name)
+	}
+	return commands
+}
+
+// getRegisteredCommandNames returns the sorted names of the registered commands, without plugins
+func (app *Application) getRegisteredCommandNames() []string {
+	commands := slices.Collect(maps.Keys(app.registry.ListCommands()))
+	slices.Sort(commands)
 	return commands
 }
 
 // getAllFlagsForCommand returns all flags for a command
 func (app *Application) getAllFlagsForCommand(commandName string) []string {
-	// This would need to be implemented based on command metadata
-	// For now, return common flags
-	return []string{"--help", "--verbose", "--debug", "--config"}
+	// Flags are named in the command's dialect, like the unknown flag in its error
+	dialect := app.executor.Dialect(commandName)
+	flags := []string{posix.POSIXFlag(dialect, "--help")}
+	for _, flag := range app.globalFlags {
+		flags = append(flags, posix.POSIXFlag(dialect, "--"+flag.Name))
+	}
+	
+	metadata := app.commandMetadata(commandName)
+	if metadata == nil {
+		return flags
+	}
+	
+	for _, field := range metadata.Fields {
+		if field.Positional || field.Hidden {
+			continue
+		}
+		flags = append(flags, posix.POSIXFlag(dialect, "--"+field.Long))
+	}
+	return flags
 }
 
 // getRequiredFlagsForCommand returns required flags for a command
@@ -413,36 +728,19 @@ func (app *Application) loadConfigurationFile(commandName string, args []string)
 		return nil, nil // Command doesn't exist, skip config loading
 	}
 	
-	// Create config loader
-	configFileName := app.config.ConfigFile
-	if configFileName == "" {
-		configFileName = app.config.Name // Use app name as default
-	}
-	
-	searchPaths := app.config.ConfigPaths
-	if len(searchPaths) == 0 {
-		// Default search paths
-		searchPaths = []string{
-			".",
-			"$HOME/.config/" + app.config.Name,
-			"/etc/" + app.config.Name,
-		}
-	}
-	
-	loader := configfile.NewLoader(configFileName, searchPaths...)
-	
 	// Create instance of config struct for this command
 	configType := descriptor.GetConfigType()
 	configPtr := reflect.New(configType)
 	config := configPtr.Interface()
 	
-	// Load configuration from file
-	if err := loader.Load(config); err != nil {
+	fields, found, err := app.loadConfigIntoStruct(commandName, config)
+	if err != nil || !found {
 		return nil, err
 	}
 	
-	// Return the loaded config for merging with CLI arguments
-	return config, nil
+	// Return the loaded config for merging with CLI arguments; only the fields the
+	// files set override defaults, including when they are set to zero values
+	return &core.PartialConfig{Config: config, Fields: fields}, nil
 }
 
 // GenerateConfigFile generates an example configuration file
@@ -488,7 +786,7 @@ func (app *Application) handleInteractivePrompt(ctx context.Context, commandName
 	
 	// Load any existing configuration from files
 	if app.config.AutoLoadConfig {
-		if err := app.loadConfigIntoStruct(commandName, config); err != nil {
+		if _, _, err := app.loadConfigIntoStruct(commandName, config); err != nil {
 			// Non-fatal, continue with prompting
 		}
 	}
@@ -520,26 +818,6 @@ func (app *Application) handleInteractivePrompt(ctx context.Context, commandName
 	return nil
 }
 
-// loadConfigIntoStruct loads configuration file into struct
-func (app *Application) loadConfigIntoStruct(commandName string, config any) error {
-	configFileName := app.config.ConfigFile
-	if configFileName == "" {
-		configFileName = app.config.Name
-	}
-	
-	searchPaths := app.config.ConfigPaths
-	if len(searchPaths) == 0 {
-		searchPaths = []string{
-			".",
-			"$HOME/.config/" + app.config.Name,
-			"/etc/" + app.config.Name,
-		}
-	}
-	
-	loader := configfile.NewLoader(configFileName, searchPaths...)
-	return loader.Load(config)
-}
-
 // parseArgsIntoStruct parses command line arguments into struct
 func (app *Application) parseArgsIntoStruct(commandName string, args []string, config any) error {
 	// This would use the enhanced parser to populate the struct with CLI args
@@ -0,0 +1,738 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eugener/clix/config"
+	"github.com/eugener/clix/core"
+)
+
+type testDatabase struct {
+	Host string `posix:",host,Database host"`
+	Port int    `posix:",port,Database port"`
+}
+
+type deployConfig struct {
+	Name     string       `posix:"n,name,Name"`
+	Region   string       `posix:",region,Region"`
+	Replicas int          `posix:",replicas,Replicas"`
+	Verbose  bool         `posix:"v,verbose,Verbose"`
+	Database testDatabase `posix:",database,Database settings"`
+}
+
+// newTestApp creates an application named tool that reads tool.yaml from dir and
+// records the config of each deploy run
+func newTestApp(t *testing.T, dir string, runs *[]deployConfig, opts ...config.Option) *Application {
+	t.Helper()
+	
+	options := append([]config.Option{
+		config.WithName("tool"),
+		config.WithConfigFile("tool"),
+		config.WithConfigPaths([]string{dir}),
+		config.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	}, opts...)
+	application := NewApplicationWithOptions(options...)
+	
+	err := application.Register(core.NewCommand("deploy", "Deploy", func(ctx context.Context, c deployConfig) error {
+		*runs = append(*runs, c)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return application
+}
+
+// writeFile writes content to name in dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestCommandSections(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		args     []string
+		wantCode int
+		want     deployConfig
+	}{
+		{
+			name: "top-level keys",
+			file: "name: top\nreplicas: 2\n",
+			args: []string{"deploy"},
+			want: deployConfig{Name: "top", Replicas: 2},
+		},
+		{
+			name: "global section overrides top-level keys",
+			file: "name: top\nglobal:\n  name: global\n",
+			args: []string{"deploy"},
+			want: deployConfig{Name: "global"},
+		},
+		{
+			name: "command section overrides shared keys",
+			file: "region: eu\ndatabase:\n  host: shared\n  port: 5432\ncommands:\n  deploy:\n    region: us\n    database:\n      host: deploy\n",
+			args: []string{"deploy"},
+			want: deployConfig{Region: "us", Database: testDatabase{Host: "deploy", Port: 5432}},
+		},
+		{
+			name: "flags override the file",
+			file: "commands:\n  deploy:\n    name: file\n    replicas: 3\n",
+			args: []string{"deploy", "--name", "flag"},
+			want: deployConfig{Name: "flag", Replicas: 3},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "tool.yaml", tt.file)
+			
+			var runs []deployConfig
+			code := newTestApp(t, dir, &runs).Run(context.Background(), tt.args)
+			if code != tt.wantCode {
+				t.Fatalf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantCode != 0 {
+				return
+			}
+			if len(runs) != 1 || runs[0] != tt.want {
+				t.Errorf("deploy ran with %+v, want %+v", runs, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	file := `replicas: 1
+profiles:
+  staging:
+    region: eu
+    replicas: 2
+  prod:
+    extends: staging
+    replicas: 5
+`
+	tests := []struct {
+		name     string
+		args     []string
+		opts     []config.Option
+		profile  string
+		wantCode int
+		want     deployConfig
+	}{
+		{name: "no profile", args: []string{"deploy"}, want: deployConfig{Replicas: 1}},
+		{name: "profile flag", args: []string{"--profile", "staging", "deploy"}, want: deployConfig{Region: "eu", Replicas: 2}},
+		{name: "extended profile", args: []string{"deploy", "--profile=prod"}, want: deployConfig{Region: "eu", Replicas: 5}},
+		{name: "default profile", args: []string{"deploy"}, opts: []config.Option{config.WithProfile("prod")}, want: deployConfig{Region: "eu", Replicas: 5}},
+		{name: "profile file", args: []string{"--profile", "qa", "deploy"}, profile: "region: qa\n", want: deployConfig{Region: "qa", Replicas: 1}},
+		{name: "unknown profile", args: []string{"--profile", "nope", "deploy"}, wantCode: 1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "tool.yaml", file)
+			if tt.profile != "" {
+				writeFile(t, dir, "tool.qa.yaml", tt.profile)
+			}
+			
+			var runs []deployConfig
+			code := newTestApp(t, dir, &runs, tt.opts...).Run(context.Background(), tt.args)
+			if code != tt.wantCode {
+				t.Fatalf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantCode != 0 {
+				return
+			}
+			if len(runs) != 1 || runs[0] != tt.want {
+				t.Errorf("deploy ran with %+v, want %+v", runs, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidConfigStopsCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		opts     []config.Option
+		wantCode int
+		wantRuns int
+	}{
+		{name: "valid file", file: "replicas: 2\n", wantRuns: 1},
+		{name: "wrong type", file: "replicas: three\n", wantCode: 1},
+		{name: "wrong type in command section", file: "commands:\n  deploy:\n    database:\n      port: x\n", wantCode: 1},
+		{name: "command section is not a mapping", file: "commands:\n  deploy: yes\n", wantCode: 1},
+		{name: "unknown key is a warning", file: "replica: 2\n", wantRuns: 1},
+		{name: "unknown key in strict mode", file: "replica: 2\n", opts: []config.Option{config.WithStrictConfig(true)}, wantCode: 1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "tool.yaml", tt.file)
+			
+			var runs []deployConfig
+			code := newTestApp(t, dir, &runs, tt.opts...).Run(context.Background(), []string{"deploy"})
+			if code != tt.wantCode {
+				t.Errorf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Errorf("deploy ran %d times, want %d", len(runs), tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestConfigFileLayers(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		args     []string
+		env      string
+		wantCode int
+		want     deployConfig
+	}{
+		{
+			name:  "project file overrides user file",
+			files: map[string]string{"user/tool.yaml": "name: user\nregion: eu\n", "project/tool.yaml": "name: project\n"},
+			args:  []string{"deploy"},
+			want:  deployConfig{Name: "project", Region: "eu"},
+		},
+		{
+			name:  "explicit file overrides searched files",
+			files: map[string]string{"project/tool.yaml": "name: project\nregion: eu\n", "extra.yaml": "name: extra\n"},
+			args:  []string{"--config", "{dir}/extra.yaml", "deploy"},
+			want:  deployConfig{Name: "extra", Region: "eu"},
+		},
+		{
+			name:  "explicit file from the environment",
+			files: map[string]string{"extra.yaml": "name: env\n"},
+			args:  []string{"deploy"},
+			env:   "extra.yaml",
+			want:  deployConfig{Name: "env"},
+		},
+		{
+			name:     "missing explicit file",
+			args:     []string{"deploy", "--config={dir}/missing.yaml"},
+			wantCode: 1,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				writeFile(t, dir, name, content)
+			}
+			
+			// {dir} in arguments is the test directory
+			args := make([]string, len(tt.args))
+			for i, arg := range tt.args {
+				args[i] = strings.ReplaceAll(arg, "{dir}", dir)
+			}
+			if tt.env != "" {
+				t.Setenv("TOOL_CONFIG", filepath.Join(dir, tt.env))
+			}
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs,
+				config.WithConfigPaths([]string{filepath.Join(dir, "project"), filepath.Join(dir, "user")}))
+			
+			code := application.Run(context.Background(), args)
+			if code != tt.wantCode {
+				t.Fatalf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantCode != 0 {
+				return
+			}
+			if len(runs) != 1 || runs[0] != tt.want {
+				t.Errorf("deploy ran with %+v, want %+v", runs, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailingConfigLayer(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{name: "valid layers", files: map[string]string{"project/tool.yaml": "name: p\n", "user/tool.yaml": "replicas: 2\n"}},
+		{name: "user layer fails", files: map[string]string{"project/tool.yaml": "name: p\n", "user/tool.yaml": "replicas: nope\n"}, want: "user/tool.yaml"},
+		{name: "project layer fails", files: map[string]string{"project/tool.yaml": "replicas: nope\n", "user/tool.yaml": "name: u\n"}, want: "project/tool.yaml"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				writeFile(t, dir, name, content)
+			}
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs,
+				config.WithConfigPaths([]string{filepath.Join(dir, "project"), filepath.Join(dir, "user")}))
+			loader := application.newConfigLoader()
+			layers, err := application.loadConfigLayers(loader)
+			if err != nil {
+				t.Fatalf("loadConfigLayers() error = %v", err)
+			}
+			
+			got := failingConfigLayer(loader, layers, "deploy", reflect.TypeOf(&deployConfig{}))
+			if tt.want == "" && got != "" || tt.want != "" && got != filepath.Join(dir, tt.want) {
+				t.Errorf("failingConfigLayer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHooksSeeRedactedSecrets(t *testing.T) {
+	type loginConfig struct {
+		Verbose bool   `posix:"v,verbose,Verbose"`
+		Token   string `posix:"t,token,API token,secret"`
+	}
+	
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "attached value", args: []string{"login", "--token=s3cret", "-v"}, want: []string{"--token=", "-v"}},
+		{name: "separate value", args: []string{"login", "--token", "s3cret"}, want: []string{"--token", ""}},
+		{name: "short bundle", args: []string{"login", "-vts3cret"}, want: []string{"-vt="}},
+		{name: "abbreviated command", args: []string{"log", "-t", "s3cret"}, want: []string{"-t", ""}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := make(map[string][]string)
+			record := func(name string) func(*core.ExecutionContext) error {
+				return func(execCtx *core.ExecutionContext) error {
+					seen[name] = execCtx.Args
+					return nil
+				}
+			}
+			
+			var token string
+			var runs []deployConfig
+			application := newTestApp(t, t.TempDir(), &runs,
+				config.WithAbbreviations(true),
+				config.WithBeforeAll(record("before all")),
+				config.WithAfterAll(record("after all")),
+				config.WithBeforeEach(record("before each")),
+				config.WithAfterEach(record("after each")),
+			)
+			err := application.Register(core.NewCommand("login", "Log in", func(ctx context.Context, c loginConfig) error {
+				token = c.Token
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), tt.args); code != 0 {
+				t.Fatalf("Run() = %d, want 0", code)
+			}
+			if token != "s3cret" {
+				t.Errorf("command got token %q, want s3cret", token)
+			}
+			
+			want := map[string][]string{
+				"before all":  append([]string{tt.args[0]}, tt.want...),
+				"after all":   append([]string{tt.args[0]}, tt.want...),
+				"before each": tt.want,
+				"after each":  tt.want,
+			}
+			if !reflect.DeepEqual(seen, want) {
+				t.Errorf("hooks saw %q, want %q", seen, want)
+			}
+		})
+	}
+}
+
+func TestArgsFiles(t *testing.T) {
+	type tagConfig struct {
+		Verbose bool     `posix:"v,verbose,Verbose"`
+		Token   string   `posix:"t,token,Registry token,secret"`
+		Tags    []string `posix:",,Tags,positional"`
+	}
+	
+	tests := []struct {
+		name    string
+		enabled bool
+		args    []string
+		want    tagConfig
+	}{
+		{name: "disabled by default", args: []string{"tag", "@tags.args"}, want: tagConfig{Tags: []string{"@tags.args"}}},
+		{name: "enabled", enabled: true, args: []string{"tag", "@tags.args"}, want: tagConfig{Verbose: true, Tags: []string{"v1", "latest"}}},
+		{name: "escaped", enabled: true, args: []string{"tag", "@@latest"}, want: tagConfig{Tags: []string{"@latest"}}},
+		{name: "after --", enabled: true, args: []string{"tag", "--", "@latest"}, want: tagConfig{Tags: []string{"@latest"}}},
+		{name: "secret reads the file", enabled: true, args: []string{"tag", "--token", "@token.txt", "@tags.args"}, want: tagConfig{Verbose: true, Token: "s3cret", Tags: []string{"v1", "latest"}}},
+		{name: "short secret in a bundle", enabled: true, args: []string{"tag", "-vt", "@token.txt"}, want: tagConfig{Verbose: true, Token: "s3cret", Tags: []string{}}},
+		{name: "command from a file", enabled: true, args: []string{"@command.args", "-t", "@token.txt"}, want: tagConfig{Token: "s3cret", Tags: []string{"v2"}}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "tags.args", "-v\nv1 latest\n")
+			writeFile(t, dir, "token.txt", "s3cret\n")
+			writeFile(t, dir, "command.args", "tag v2\n")
+			t.Chdir(dir)
+			
+			var opts []config.Option
+			if tt.enabled {
+				opts = append(opts, config.WithArgsFiles(true))
+			}
+			
+			var got tagConfig
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs, opts...)
+			err := application.Register(core.NewCommand("tag", "Tag", func(ctx context.Context, c tagConfig) error {
+				got = c
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), tt.args); code != 0 {
+				t.Fatalf("Run() = %d, want 0", code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tag ran with %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAbbreviations(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		args     []string
+		wantCode int
+		want     []deployConfig
+	}{
+		{name: "full names", enabled: true, args: []string{"deploy", "--verbose"}, want: []deployConfig{{Verbose: true}}},
+		{name: "command and flag prefixes", enabled: true, args: []string{"dep", "--verb", "--rep=2"}, want: []deployConfig{{Verbose: true, Replicas: 2}}},
+		{name: "section flag prefix", enabled: true, args: []string{"deploy", "--database.h", "db"}, want: []deployConfig{{Database: testDatabase{Host: "db"}}}},
+		{name: "ambiguous command", enabled: true, args: []string{"de"}, wantCode: 1},
+		{name: "disabled", args: []string{"dep"}, wantCode: 1},
+		{name: "disabled flag prefix", args: []string{"deploy", "--verb"}, wantCode: 1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs []deployConfig
+			application := newTestApp(t, t.TempDir(), &runs, config.WithAbbreviations(tt.enabled))
+			err := application.Register(core.NewCommand("describe", "Describe", func(ctx context.Context, c deployConfig) error {
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), tt.args); code != tt.wantCode {
+				t.Fatalf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if !reflect.DeepEqual(runs, tt.want) {
+				t.Errorf("deploy ran with %+v, want %+v", runs, tt.want)
+			}
+		})
+	}
+}
+
+// defaultedConfig has a tag default and a computed default
+type defaultedConfig struct {
+	Replicas int  `posix:",replicas,Replicas,default=3"`
+	Verbose  bool `posix:"v,verbose,Verbose"`
+}
+
+// Defaults turns verbose output on
+func (c *defaultedConfig) Defaults() {
+	c.Verbose = true
+}
+
+func TestZeroValuesOverrideDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want defaultedConfig
+	}{
+		{name: "defaults", args: []string{"scale"}, want: defaultedConfig{Replicas: 3, Verbose: true}},
+		{name: "file sets zero values", file: "replicas: 0\nverbose: false\n", args: []string{"scale"}, want: defaultedConfig{}},
+		{name: "flag sets zero value", args: []string{"scale", "--replicas=0", "--verbose=false"}, want: defaultedConfig{}},
+		{name: "flag overrides file", file: "replicas: 5\n", args: []string{"scale", "--replicas=0"}, want: defaultedConfig{Verbose: true}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeFile(t, dir, "tool.yaml", tt.file)
+			}
+			
+			var runs []deployConfig
+			var got []defaultedConfig
+			application := newTestApp(t, dir, &runs)
+			err := application.Register(core.NewCommand("scale", "Scale", func(ctx context.Context, c defaultedConfig) error {
+				got = append(got, c)
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), tt.args); code != 0 {
+				t.Fatalf("Run() = %d, want 0", code)
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("scale ran with %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainConfigShowsDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want map[string][]string
+	}{
+		{name: "defaults", want: map[string][]string{"replicas": {"3", "default"}, "verbose": {"true", "default"}}},
+		{name: "file overrides a default", file: "replicas: 5\n", want: map[string][]string{"replicas": {"5"}, "verbose": {"true", "default"}}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeFile(t, dir, "tool.yaml", tt.file)
+			}
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs)
+			err := application.Register(core.NewCommand("scale", "Scale", func(ctx context.Context, c defaultedConfig) error {
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			text, err := application.ExplainConfig("scale")
+			if err != nil {
+				t.Fatalf("ExplainConfig() error = %v", err)
+			}
+			rows := make(map[string][]string)
+			for _, line := range strings.Split(text, "\n") {
+				if fields := strings.Fields(line); len(fields) >= 3 {
+					rows[fields[0]] = fields[1:]
+				}
+			}
+			for key, want := range tt.want {
+				if got := rows[key]; len(got) < len(want) || !reflect.DeepEqual(got[:len(want)], want) {
+					t.Errorf("%s row = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPassthroughKeepsGlobalFlags(t *testing.T) {
+	type execConfig struct {
+		Command string   `posix:",command,Command to run,positional"`
+		Args    []string `posix:",args,Command arguments,passthrough"`
+	}
+	
+	tests := []struct {
+		name        string
+		args        []string
+		wantArgs    []string
+		wantProfile string
+	}{
+		{name: "global flag before the command", args: []string{"--profile", "prod", "exec", "ls", "-la"}, wantArgs: []string{"-la"}, wantProfile: "prod"},
+		{name: "global flag of the wrapped command", args: []string{"exec", "ls", "--profile", "dev"}, wantArgs: []string{"--profile", "dev"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs []deployConfig
+			var got []execConfig
+			dir := t.TempDir()
+			writeFile(t, dir, "tool.yaml", "profiles:\n  prod: {}\n")
+			application := newTestApp(t, dir, &runs)
+			err := application.Register(core.NewCommand("exec", "Exec", func(ctx context.Context, c execConfig) error {
+				got = append(got, c)
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), tt.args); code != 0 {
+				t.Fatalf("Run() = %d, want 0", code)
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0].Args, tt.wantArgs) {
+				t.Errorf("exec ran with %+v, want args %q", got, tt.wantArgs)
+			}
+			if application.profile != tt.wantProfile {
+				t.Errorf("profile = %q, want %q", application.profile, tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestUnknownFlagSuggestions(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		wantFlag        string
+		wantSuggestions []string
+	}{
+		{name: "command flag", args: []string{"--replics", "2"}, wantFlag: "--replics", wantSuggestions: []string{"--replicas"}},
+		{name: "section flag", args: []string{"--database.hots=db"}, wantFlag: "--database.hots", wantSuggestions: []string{"--database.host"}},
+		{name: "global flag", args: []string{"--profle=prod"}, wantFlag: "--profle", wantSuggestions: []string{"--profile"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs []deployConfig
+			application := newTestApp(t, t.TempDir(), &runs)
+			
+			err := application.executor.Execute(context.Background(), "deploy", tt.args)
+			if err == nil {
+				t.Fatalf("Execute(%q) succeeded, want an unknown flag error", tt.args)
+			}
+			
+			errorContext := application.buildErrorContext(err, "deploy", tt.args)
+			if errorContext.Flag != tt.wantFlag {
+				t.Errorf("error context flag = %q, want %q", errorContext.Flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(errorContext.Suggestions, tt.wantSuggestions) {
+				t.Errorf("suggestions = %q, want %q", errorContext.Suggestions, tt.wantSuggestions)
+			}
+		})
+	}
+}
+
+func TestSlashDialect(t *testing.T) {
+	type copyConfig struct {
+		Count   int      `posix:"c,count,Copies"`
+		Verbose bool     `posix:"v,verbose,Verbose"`
+		Files   []string `posix:",files,Files,positional"`
+	}
+	
+	tests := []struct {
+		name        string
+		args        []string
+		want        copyConfig
+		wantProfile string
+	}{
+		{name: "flags", args: []string{"copy", "/count:5", "/v", "a"}, want: copyConfig{Count: 5, Verbose: true, Files: []string{"a"}}},
+		{name: "paths are positional", args: []string{"copy", "/tmp", "/tmp/dst", "C:/src"}, want: copyConfig{Files: []string{"/tmp", "/tmp/dst", "C:/src"}}},
+		{name: "global flag", args: []string{"/profile:prod", "copy", "/c", "2"}, want: copyConfig{Count: 2, Files: []string{}}, wantProfile: "prod"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "tool.yaml", "profiles:\n  prod: {}\n")
+			
+			var runs []deployConfig
+			var got []copyConfig
+			application := newTestApp(t, dir, &runs, config.WithDialect(core.DialectSlash))
+			err := application.Register(core.NewCommand("copy", "Copy", func(ctx context.Context, c copyConfig) error {
+				got = append(got, c)
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), tt.args); code != 0 {
+				t.Fatalf("Run() = %d, want 0", code)
+			}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("copy ran with %+v, want %+v", got, tt.want)
+			}
+			if application.profile != tt.wantProfile {
+				t.Errorf("profile = %q, want %q", application.profile, tt.wantProfile)
+			}
+		})
+	}
+}
+
+// closeRecorder is a service recording when it is closed
+type closeRecorder struct {
+	events *[]string
+}
+
+func (c *closeRecorder) Close() error {
+	*c.events = append(*c.events, "close")
+	return nil
+}
+
+func TestServicesClosedAfterAll(t *testing.T) {
+	type reportConfig struct {
+		Recorder *closeRecorder `inject:""`
+	}
+	
+	var events []string
+	var runs []deployConfig
+	application := newTestApp(t, t.TempDir(), &runs, config.WithAfterAll(func(ctx *core.ExecutionContext) error {
+		if _, err := core.Service[*closeRecorder](ctx); err != nil {
+			t.Errorf("Service() in AfterAll error = %v", err)
+		}
+		events = append(events, "after all")
+		return nil
+	}))
+	Provide(application, func(ctx context.Context) (*closeRecorder, error) {
+		events = append(events, "build")
+		return &closeRecorder{events: &events}, nil
+	})
+	err := application.Register(core.NewCommand("report", "Report", func(ctx context.Context, c reportConfig) error {
+		events = append(events, "run")
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	if code := application.Run(context.Background(), []string{"deploy"}); code != 0 {
+		t.Fatalf("Run(deploy) = %d, want 0", code)
+	}
+	if want := []string{"build", "after all", "close"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events without the service = %q, want %q", events, want)
+	}
+	
+	events = nil
+	if code := application.Run(context.Background(), []string{"report"}); code != 0 {
+		t.Fatalf("Run(report) = %d, want 0", code)
+	}
+	if want := []string{"build", "run", "after all", "close"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/eugener/clix/internal/posix"
+)
+
+// expandArgsFiles replaces @file arguments with the arguments read from the file.
+// Values of secret flags are kept, since they read @file themselves.
+func (app *Application) expandArgsFiles(args []string) ([]string, error) {
+	var secretFlags map[string]bool
+	var longNames []string
+	
+	// Only the dispatched command's flags are analyzed, every registered command's
+	// when the command name itself comes from a file
+	collectFlags := func() {
+		secretFlags = make(map[string]bool)
+		names := app.getRegisteredCommandNames()
+		if index := app.commandIndex(args); index >= 0 && !posix.IsArgsFile(args[index]) {
+			names = []string{args[index]}
+			if app.config.Abbreviations {
+				if resolved, err := app.resolveCommandName(args[index]); err == nil {
+					names[0] = resolved
+				}
+			}
+		}
+		
+		for _, name := range names {
+			metadata := app.commandMetadata(name)
+			if metadata == nil {
+				continue
+			}
+			for _, field := range metadata.Fields {
+				longNames = append(longNames, field.Long)
+				if !field.Secret {
+					continue
+				}
+				secretFlags["--"+field.Long] = true
+				if field.Short != "" {
+					secretFlags["-"+field.Short] = true
+				}
+			}
+		}
+	}
+	
+	expander := posix.NewArgsFileExpander()
+	expander.Keep = func(args []string, i int) bool {
+		if i == 0 || !strings.HasPrefix(args[i-1], "-") {
+			return false
+		}
+		if secretFlags == nil {
+			collectFlags()
+		}
+		
+		flag := args[i-1]
+		if !secretFlags[flag] && app.config.Abbreviations && strings.HasPrefix(flag, "--") {
+			if match, _ := posix.MatchPrefix(flag[2:], longNames); match != "" {
+				flag = "--" + match
+			}
+		}
+		// A short secret flag may end a bundle of boolean flags, e.g. -vt
+		if !secretFlags[flag] && len(flag) > 2 && !strings.HasPrefix(flag, "--") {
+			flag = "-" + flag[len(flag)-1:]
+		}
+		return secretFlags[flag]
+	}
+	
+	return expander.Expand(args)
+}
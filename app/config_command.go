@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eugener/clix/core"
+	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/configfile"
+)
+
+// configCommandName is the name of the built-in configuration command
+const configCommandName = "config"
+
+// isConfigCommand checks if the argument invokes the built-in config command.
+// A registered command with the same name always takes precedence.
+func (app *Application) isConfigCommand(arg string) bool {
+	if !app.config.ConfigCommand || arg != configCommandName {
+		return false
+	}
+	_, registered := app.registry.GetCommand(configCommandName)
+	return !registered
+}
+
+// handleConfigCommand dispatches the built-in config subcommands
+func (app *Application) handleConfigCommand(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, app.configCommandUsage())
+		return 1
+	}
+	
+	var err error
+	switch args[0] {
+	case "explain":
+		if len(args) != 2 {
+			err = fmt.Errorf("usage: %s config explain <command>", app.config.Name)
+			break
+		}
+		var text string
+		if text, err = app.ExplainConfig(args[1]); err == nil {
+			fmt.Print(text)
+		}
+	case "get", "set", "unset":
+		err = app.handleConfigKeyCommand(args[0], args[1:])
+	case "validate":
+		if len(args) != 1 {
+			err = fmt.Errorf("usage: %s config validate", app.config.Name)
+			break
+		}
+		if err = app.ValidateConfig(); err == nil {
+			fmt.Println("Configuration is valid")
+		}
+	case "edit":
+		if len(args) != 1 {
+			err = fmt.Errorf("usage: %s config edit", app.config.Name)
+			break
+		}
+		var path string
+		if path, err = app.EditConfig(); err == nil {
+			fmt.Printf("Saved %s\n", path)
+		}
+	case "help", "--help", "-h":
+		fmt.Print(app.configCommandUsage())
+		return 0
+	default:
+		err = fmt.Errorf("unknown config subcommand: %s", args[0])
+	}
+	
+	if err != nil {
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	
+	return 0
+}
+
+// handleConfigKeyCommand runs the get, set and unset subcommands
+func (app *Application) handleConfigKeyCommand(subcommand string, args []string) error {
+	commandName, rest, err := parseConfigKeyArgs(args)
+	if err != nil {
+		return err
+	}
+	
+	expected := 1
+	if subcommand == "set" {
+		expected = 2
+	}
+	if len(rest) != expected {
+		usage := "[--command NAME] <key>"
+		if subcommand == "set" {
+			usage += " <value>"
+		}
+		return fmt.Errorf("usage: %s config %s %s", app.config.Name, subcommand, usage)
+	}
+	
+	switch subcommand {
+	case "get":
+		value, found, err := app.GetConfigValue(commandName, rest[0])
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("key %s is not set", configDocumentKey("", commandName, rest[0]))
+		}
+		fmt.Println(formatConfigValue(value))
+	case "set":
+		path, err := app.SetConfigValue(commandName, rest[0], rest[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Set %s in %s\n", rest[0], path)
+	case "unset":
+		path, err := app.UnsetConfigValue(commandName, rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s from %s\n", rest[0], path)
+	}
+	
+	return nil
+}
+
+// configCommandUsage returns the usage text of the built-in config command
+func (app *Application) configCommandUsage() string {
+	var sb strings.Builder
+	sb.WriteString("Inspect and modify configuration\n\n")
+	sb.WriteString("Usage:\n")
+	sb.WriteString(fmt.Sprintf("  %s config <subcommand> [arguments]\n\n", app.config.Name))
+	sb.WriteString("Subcommands:\n")
+	sb.WriteString("  explain <command>                Show effective configuration values and their sources\n")
+	sb.WriteString("  get [-c command] <key>           Print a value stored in the config file\n")
+	sb.WriteString("  set [-c command] <key> <value>   Type-check and store a value in the config file\n")
+	sb.WriteString("  unset [-c command] <key>         Remove a value from the config file\n")
+	sb.WriteString("  validate                         Check the config file against all command schemas\n")
+	sb.WriteString("  edit                             Open the config file in $EDITOR and validate it before saving\n")
+	sb.WriteString("\nWith -c, keys are read from and written to the command's section.\n")
+	return sb.String()
+}
+
+// ExplainConfig describes the effective configuration of a command: the files and
+// profile it was loaded from and, for each setting, its value and source
+func (app *Application) ExplainConfig(commandName string) (string, error) {
+	descriptor, exists := app.registry.GetCommand(commandName)
+	if !exists {
+		return "", fmt.Errorf("command %s not found", commandName)
+	}
+	
+	configType := descriptor.GetConfigType()
+	metadata, err := bind.NewAnalyzer("posix").Analyze(configType)
+	if err != nil {
+		return "", err
+	}
+	
+	loader := app.newConfigLoader()
+	layers, err := app.loadConfigLayers(loader)
+	if err != nil {
+		return "", err
+	}
+	
+	// Values contributed by each layer on its own
+	layerValues := make([]reflect.Value, len(layers))
+	for i, layer := range layers {
+		section, err := configfile.CommandConfig(layer.Data, commandName)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", layer.Path, err)
+		}
+		value := reflect.New(configType)
+		if err := loader.MapToStruct(section, value.Interface()); err != nil {
+			return "", fmt.Errorf("%s: %w", layer.Path, err)
+		}
+		layerValues[i] = value.Elem()
+	}
+	
+	// Defaults from tags and the Defaulter hook, as the executor computes them
+	binder := bind.NewBinder("posix")
+	defaults := reflect.New(configType)
+	if err := binder.ApplyDefaults(defaults.Interface()); err != nil {
+		return "", err
+	}
+	if defaulter, ok := defaults.Interface().(core.Defaulter); ok {
+		defaulter.Defaults()
+	}
+	
+	// Effective values: defaults, then merged layers, then environment variables
+	effective := reflect.New(configType)
+	effective.Elem().Set(defaults.Elem())
+	section, err := configfile.CommandConfig(mergeConfigLayers(layers), commandName)
+	if err != nil {
+		return "", err
+	}
+	if err := loader.MapToStruct(section, effective.Interface()); err != nil {
+		return "", err
+	}
+	if err := core.NewEnhancedParser(binder).Parse(nil, effective.Interface()); err != nil {
+		return "", err
+	}
+	
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Command: %s\n", commandName))
+	
+	if app.profile == "" {
+		sb.WriteString("Profile: (none)\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Profile: %s (%s)\n", app.profile, strings.Join(app.profileChain, " -> ")))
+	}
+	
+	if len(layers) == 0 {
+		sb.WriteString("Sources: (no configuration file found)\n")
+	} else {
+		sb.WriteString("Sources:\n")
+		for _, layer := range layers {
+			sb.WriteString(fmt.Sprintf("  %s\n", layer.Source))
+		}
+	}
+	sb.WriteString("\n")
+	
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, field := range metadata.Fields {
+		if field.Positional {
+			continue
+		}
+		
+		source := "unset"
+		if !defaults.Elem().FieldByIndex(field.Index).IsZero() {
+			source = "default"
+		}
+		for i := range layers {
+			if !layerValues[i].FieldByIndex(field.Index).IsZero() {
+				source = layers[i].Source
+			}
+		}
+		if field.Environment != "" && os.Getenv(field.Environment) != "" {
+			source = "env " + field.Environment
+		}
+		
+		value := effective.Elem().FieldByIndex(field.Index).Interface()
+		if field.Secret && value != "" {
+			value = "(secret)"
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%s\n", field.Path, value, source)
+	}
+	tw.Flush()
+	
+	return sb.String(), nil
+}
@@ -0,0 +1,436 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/configfile"
+	"github.com/eugener/clix/internal/help"
+	"github.com/eugener/clix/internal/interactive"
+	"gopkg.in/yaml.v3"
+)
+
+// parseConfigKeyArgs extracts the --command/-c option of the get, set and unset subcommands
+func parseConfigKeyArgs(args []string) (string, []string, error) {
+	var commandName string
+	var rest []string
+	
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-c" || arg == "--command":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("flag %s requires a command name", arg)
+			}
+			commandName = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--command="):
+			commandName = strings.TrimPrefix(arg, "--command=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	
+	return commandName, rest, nil
+}
+
+// configDocumentTarget returns the file modified by the config subcommands and the
+// key prefix inside it: the explicit file, or the searched file with the highest
+// precedence. With an active profile this is the profile's own file when one exists,
+// or the profile's section of the main file otherwise.
+func (app *Application) configDocumentTarget() (string, string, error) {
+	loader := app.newConfigLoader()
+	
+	path := app.explicitConfigFile()
+	if path == "" {
+		var err error
+		if path, err = loader.FindFile(); err != nil {
+			return "", "", err
+		}
+	}
+	if path == "" {
+		path = loader.DefaultPath()
+	}
+	
+	if app.profile == "" {
+		return path, "", nil
+	}
+	
+	profilePath, err := loader.FindProfileFile(app.profile)
+	if err != nil {
+		return "", "", err
+	}
+	if profilePath != "" {
+		return profilePath, "", nil
+	}
+	
+	return path, configfile.ProfilesKey + "." + app.profile + ".", nil
+}
+
+// configDocumentKey returns the full dotted key of a setting in the config file
+func configDocumentKey(prefix, commandName, key string) string {
+	if commandName != "" {
+		return prefix + configfile.CommandsKey + "." + commandName + "." + key
+	}
+	return prefix + key
+}
+
+// resolveConfigKey finds the type and allowed values of a config key using the schema
+// of the given command, or of the first command that defines the key when commandName is empty
+func (app *Application) resolveConfigKey(commandName, key string) (reflect.Type, []string, error) {
+	var names []string
+	if commandName != "" {
+		if _, exists := app.registry.GetCommand(commandName); !exists {
+			return nil, nil, fmt.Errorf("command %s not found", commandName)
+		}
+		names = []string{commandName}
+	} else {
+		// Plugins have no schema, only registered commands define keys
+		names = app.getRegisteredCommandNames()
+	}
+	
+	loader := app.newConfigLoader()
+	var known []string
+	
+	for _, name := range names {
+		descriptor, _ := app.registry.GetCommand(name)
+		configType := descriptor.GetConfigType()
+		
+		index, fieldType, err := loader.ResolveKey(configType, key)
+		if err != nil {
+			if metadata, err := bind.NewAnalyzer("posix").Analyze(configType); err == nil {
+				for _, field := range metadata.Fields {
+					if !field.Positional && !slices.Contains(known, field.Path) {
+						known = append(known, field.Path)
+					}
+				}
+			}
+			continue
+		}
+		
+		// Allowed values come from the matching field's posix tag
+		var choices []string
+		if metadata, err := bind.NewAnalyzer("posix").Analyze(configType); err == nil {
+			for _, field := range metadata.Fields {
+				if slices.Equal(field.Index, index) {
+					choices = field.Choices
+				}
+			}
+		}
+		
+		return fieldType, choices, nil
+	}
+	
+	msg := fmt.Sprintf("unknown configuration key %s", key)
+	if commandName != "" {
+		msg += " for command " + commandName
+	}
+	if suggestions := help.NewSuggestionEngine().SuggestFlags(key, known); len(suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+	}
+	return nil, nil, fmt.Errorf("%s", msg)
+}
+
+// configFileValue converts a command-line string into the value written to the config
+// file. Values are checked with the binder's converters; types without a native
+// YAML form, such as durations, are stored as their string representation.
+func configFileValue(value string, fieldType reflect.Type, choices []string) (any, error) {
+	converted, err := bind.NewBinder("posix").ConvertString(value, fieldType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for %s: %w", value, fieldType, err)
+	}
+	
+	if len(choices) > 0 {
+		for _, item := range strings.Split(value, ",") {
+			if !slices.Contains(choices, strings.TrimSpace(item)) {
+				return nil, fmt.Errorf("invalid value %q, must be one of: %s", item, strings.Join(choices, ", "))
+			}
+		}
+	}
+	
+	elemType := fieldType
+	if fieldType.Kind() == reflect.Slice {
+		elemType = fieldType.Elem()
+	}
+	if elemType.PkgPath() == "" {
+		return converted, nil
+	}
+	
+	if fieldType.Kind() == reflect.Slice {
+		items := strings.Split(value, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
+// GetConfigValue returns the value of a key stored in the active config file,
+// optionally from the section of a command
+func (app *Application) GetConfigValue(commandName, key string) (any, bool, error) {
+	path, prefix, err := app.configDocumentTarget()
+	if err != nil {
+		return nil, false, err
+	}
+	
+	doc, err := configfile.OpenDocument(path)
+	if err != nil {
+		return nil, false, err
+	}
+	
+	return doc.Get(configDocumentKey(prefix, commandName, key))
+}
+
+// SetConfigValue type-checks value against the command schema and stores it in the
+// active config file, returning the path of the file written
+func (app *Application) SetConfigValue(commandName, key, value string) (string, error) {
+	fieldType, choices, err := app.resolveConfigKey(commandName, key)
+	if err != nil {
+		return "", err
+	}
+	
+	fileValue, err := configFileValue(value, fieldType, choices)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	
+	path, prefix, err := app.configDocumentTarget()
+	if err != nil {
+		return "", err
+	}
+	
+	doc, err := configfile.OpenDocument(path)
+	if err != nil {
+		return "", err
+	}
+	
+	if err := doc.Set(configDocumentKey(prefix, commandName, key), fileValue); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	
+	return path, doc.Save()
+}
+
+// UnsetConfigValue removes a key from the active config file, returning the path of the file written
+func (app *Application) UnsetConfigValue(commandName, key string) (string, error) {
+	path, prefix, err := app.configDocumentTarget()
+	if err != nil {
+		return "", err
+	}
+	
+	doc, err := configfile.OpenDocument(path)
+	if err != nil {
+		return "", err
+	}
+	
+	fullKey := configDocumentKey(prefix, commandName, key)
+	if !doc.Unset(fullKey) {
+		return "", fmt.Errorf("%s: key %s is not set", path, fullKey)
+	}
+	
+	return path, doc.Save()
+}
+
+// EditConfig opens the active config file in $VISUAL or $EDITOR and saves the
+// result only when it is a valid configuration for the registered commands
+func (app *Application) EditConfig() (string, error) {
+	path, _, err := app.configDocumentTarget()
+	if err != nil {
+		return "", err
+	}
+	
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	
+	// Edit a copy next to the original so relative includes still resolve
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))+".*"+filepath.Ext(path))
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	tmp.Close()
+	
+	confirm := interactive.NewConfirmPrompter()
+	
+	for {
+		if err := runEditor(tmp.Name()); err != nil {
+			return "", err
+		}
+		
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return "", err
+		}
+		
+		validationErr := app.validateConfigFile(tmp.Name())
+		if validationErr == nil {
+			if string(edited) == string(original) {
+				return path, nil
+			}
+			return path, configfile.WriteFileAtomic(path, edited)
+		}
+		
+		// Report problems against the real file rather than the temporary copy
+		problems := strings.ReplaceAll(validationErr.Error(), tmp.Name(), path)
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%s\n", problems)
+		again, err := confirm.Confirm("Edit again?", true)
+		if err != nil || !again {
+			return "", fmt.Errorf("configuration not saved:\n%s", problems)
+		}
+	}
+}
+
+// runEditor opens a file in the user's editor
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	
+	// The editor may carry its own arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+	return nil
+}
+
+// ValidateConfig checks the active configuration files and profile against every
+// command: unknown sections and keys, value types, allowed values and required values.
+// Problems are returned as configfile.ValidationErrors with file positions.
+func (app *Application) ValidateConfig() error {
+	loader := app.newConfigLoader()
+	
+	layers, err := app.loadConfigLayers(loader)
+	if err != nil {
+		return err
+	}
+	
+	errs, err := app.validateConfigLayers(loader, layers, true)
+	if err != nil || len(errs) == 0 {
+		return err
+	}
+	return errs
+}
+
+// validateConfigFile checks that a config file parses, has no unknown sections
+// or keys, and that every command's settings have valid values. The base settings
+// and each inline profile are checked on their own.
+func (app *Application) validateConfigFile(path string) error {
+	loader := app.newConfigLoader()
+	
+	data, positions, err := loader.ReadMapPositions(path)
+	if err != nil {
+		return err
+	}
+	
+	profiles, err := configfile.Profiles(data)
+	if err != nil {
+		return err
+	}
+	
+	base := configfile.WithoutProfiles(data)
+	delete(base, configfile.ExtendsKey)
+	layers := []configLayer{{Path: path, Data: base, Positions: positions}}
+	
+	for name := range profiles {
+		if _, err := configfile.ProfileChain(profiles, name); err != nil {
+			return err
+		}
+		layers = append(layers, configLayer{
+			Path:      path,
+			Data:      configfile.ProfileSettings(profiles, name),
+			KeyPrefix: configfile.ProfilesKey + "." + name,
+			Positions: positions,
+		})
+	}
+	
+	errs, err := app.validateConfigLayers(loader, layers, false)
+	if err != nil || len(errs) == 0 {
+		return err
+	}
+	return errs
+}
+
+// validateConfigLayers checks the configuration layers for every registered command:
+// unknown sections and keys, value types and allowed values, and, when requireValues
+// is set, required values that are neither configured nor provided by the environment
+func (app *Application) validateConfigLayers(loader *configfile.Loader, layers []configLayer, requireValues bool) (configfile.ValidationErrors, error) {
+	errs, err := app.configProblems(loader, layers, "")
+	if err != nil {
+		return nil, err
+	}
+	
+	names := app.getRegisteredCommandNames()
+	merged := mergeConfigLayers(layers)
+	
+	for _, name := range names {
+		descriptor, _ := app.registry.GetCommand(name)
+		configType := descriptor.GetConfigType()
+		
+		errs = append(errs, checkConfigValues(loader, layers, name, configType)...)
+		
+		if !requireValues || len(layers) == 0 {
+			continue
+		}
+		
+		section, err := configfile.CommandConfig(merged, name)
+		if err != nil {
+			return nil, err
+		}
+		
+		// Missing values are reported at the last command section defining the command
+		prefix := configfile.CommandsKey + "." + name
+		positions := configfile.Positions{prefix: {File: layers[0].Path}}
+		for _, layer := range layers {
+			key := configfile.JoinKey(layer.KeyPrefix, prefix)
+			if pos, ok := layer.Positions[key]; ok {
+				positions[prefix] = pos
+			}
+		}
+		errs = append(errs, loader.CheckRequired(section, configType, prefix, positions)...)
+	}
+	
+	errs.Sort()
+	return slices.CompactFunc(errs, func(a, b *configfile.ValidationError) bool {
+		return a.Error() == b.Error()
+	}), nil
+}
+
+// formatConfigValue renders a config value for display, using YAML for sections and lists
+func formatConfigValue(value any) string {
+	switch value.(type) {
+	case map[string]any, []any:
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return strings.TrimRight(string(out), "\n")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetConfigValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		command string
+		key     string
+		value   string
+		profile string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "top-level key",
+			file:  "# settings\nname: api\n",
+			key:   "replicas",
+			value: "3",
+			want:  "# settings\nname: api\nreplicas: 3\n",
+		},
+		{
+			name:    "command section",
+			key:     "database.host",
+			command: "deploy",
+			value:   "db",
+			want:    "commands:\n  deploy:\n    database:\n      host: db\n",
+		},
+		{
+			name:    "profile section",
+			key:     "region",
+			value:   "eu",
+			profile: "prod",
+			want:    "profiles:\n  prod:\n    region: eu\n",
+		},
+		{
+			name:    "wrong type",
+			key:     "replicas",
+			value:   "three",
+			wantErr: `replicas: invalid value "three"`,
+		},
+		{
+			name:    "unknown key with suggestion",
+			key:     "replica",
+			value:   "3",
+			wantErr: "unknown configuration key replica (did you mean replicas?)",
+		},
+		{
+			name:    "unknown command",
+			key:     "replicas",
+			command: "status",
+			value:   "3",
+			wantErr: "command status not found",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "tool.yaml")
+			if tt.file != "" {
+				writeFile(t, dir, "tool.yaml", tt.file)
+			}
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs)
+			application.profile = tt.profile
+			
+			written, err := application.SetConfigValue(tt.command, tt.key, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SetConfigValue() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetConfigValue() error = %v", err)
+			}
+			if written != path {
+				t.Errorf("SetConfigValue() wrote %s, want %s", written, path)
+			}
+			
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("config file = %q, want %q", content, tt.want)
+			}
+			
+			value, found, err := application.GetConfigValue(tt.command, tt.key)
+			if err != nil || !found {
+				t.Fatalf("GetConfigValue() = %v, %v, %v", value, found, err)
+			}
+			if formatConfigValue(value) != tt.value {
+				t.Errorf("GetConfigValue() = %v, want %s", value, tt.value)
+			}
+		})
+	}
+}
+
+func TestUnsetConfigValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		key     string
+		want    string
+		wantErr string
+	}{
+		{name: "top-level key", key: "name", want: "replicas: 2\ncommands:\n  deploy:\n    region: eu\n"},
+		{name: "command section", command: "deploy", key: "region", want: "name: api\nreplicas: 2\ncommands:\n  deploy: {}\n"},
+		{name: "missing key", key: "region", wantErr: "key region is not set"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := writeFile(t, dir, "tool.yaml", "name: api\nreplicas: 2\ncommands:\n  deploy:\n    region: eu\n")
+			
+			var runs []deployConfig
+			_, err := newTestApp(t, dir, &runs).UnsetConfigValue(tt.command, tt.key)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("UnsetConfigValue() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnsetConfigValue() error = %v", err)
+			}
+			
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("config file = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/eugener/clix/internal/configfile"
+	"github.com/eugener/clix/internal/help"
+)
+
+// configLayer is one source of configuration values. Layers are merged in
+// order, later layers overriding earlier ones.
+type configLayer struct {
+	Source string
+	Path   string
+	Data   map[string]any
+	
+	// KeyPrefix is the dotted key of Data within the file, e.g. profiles.prod
+	KeyPrefix string
+	Positions configfile.Positions
+}
+
+// newConfigLoader creates a config file loader from the application settings.
+// Without configured search paths the XDG Base Directory locations are searched.
+func (app *Application) newConfigLoader() *configfile.Loader {
+	configFileName := app.config.ConfigFile
+	if configFileName == "" {
+		configFileName = app.config.Name // Use app name as default
+	}
+	
+	if len(app.config.ConfigPaths) > 0 {
+		return configfile.NewLoader(configFileName, app.config.ConfigPaths...)
+	}
+	
+	return configfile.NewLocationLoader(configFileName, configfile.DefaultLocations(app.config.Name, configFileName)...)
+}
+
+// explicitConfigFile returns the configuration file given with --config or the
+// <APP>_CONFIG environment variable, or an empty string
+func (app *Application) explicitConfigFile() string {
+	if app.configPath != "" {
+		return app.configPath
+	}
+	return os.Getenv(configfile.EnvVarName(app.config.Name))
+}
+
+// configFiles returns the configuration files to merge, from lowest to highest
+// precedence: system, user and project files, then the explicit file
+func (app *Application) configFiles(loader *configfile.Loader) ([]configfile.File, error) {
+	files := loader.FindFiles()
+	
+	if explicit := app.explicitConfigFile(); explicit != "" {
+		if _, err := os.Stat(explicit); errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", configfile.ErrFileNotFound, explicit)
+		} else if err != nil {
+			return nil, err
+		}
+		files = append(files, configfile.File{Path: explicit, Scope: configfile.ScopeExplicit})
+	}
+	
+	if logger := app.config.Logger; logger != nil {
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = file.Path
+		}
+		logger.Debug("configuration search order", "patterns", loader.SearchOrder(), "explicit", app.explicitConfigFile())
+		logger.Debug("configuration files found", "files", paths)
+	}
+	
+	return files, nil
+}
+
+// loadConfigLayers loads the configuration files followed by the layers of the active profile
+func (app *Application) loadConfigLayers(loader *configfile.Loader) ([]configLayer, error) {
+	var layers []configLayer
+	
+	files, err := app.configFiles(loader)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Inline profiles of all files are merged, each file contributing its own layers
+	profiles := map[string]any{}
+	fileProfiles := make([]map[string]any, len(files))
+	path := ""
+	for i, file := range files {
+		data, positions, err := readConfigFile(loader, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		
+		if fileProfiles[i], err = configfile.Profiles(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		configfile.MergeMaps(profiles, fileProfiles[i])
+		
+		source := "file " + file.Path
+		if file.Scope != "" {
+			source = file.Scope + " " + source
+		}
+		layers = append(layers, configLayer{
+			Source:    source,
+			Path:      file.Path,
+			Data:      configfile.WithoutProfiles(data),
+			Positions: positions,
+		})
+		path = file.Path
+	}
+	baseLayers := slices.Clone(layers)
+	
+	if app.profile == "" {
+		return layers, nil
+	}
+	
+	profilePath, err := loader.FindProfileFile(app.profile)
+	if err != nil {
+		return nil, err
+	}
+	
+	profileData, profilePositions, err := readConfigFile(loader, profilePath)
+	if err != nil {
+		return nil, err
+	}
+	
+	// The profile file may extend inline profiles just like an inline profile
+	var chain []string
+	if _, inline := profiles[app.profile]; inline || profileData == nil {
+		chain, err = configfile.ProfileChain(profiles, app.profile)
+	} else if parent, ok := profileData[configfile.ExtendsKey].(string); ok && parent != "" {
+		chain, err = configfile.ProfileChain(profiles, parent)
+		chain = append(chain, app.profile)
+	} else {
+		chain = []string{app.profile}
+	}
+	if err != nil {
+		if path == "" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	app.profileChain = chain
+	
+	for _, name := range chain {
+		for i, base := range baseLayers {
+			if _, inline := fileProfiles[i][name]; !inline {
+				continue
+			}
+			source := "profile " + name
+			if len(files) > 1 {
+				source += fmt.Sprintf(" (%s)", base.Path)
+			}
+			layers = append(layers, configLayer{
+				Source:    source,
+				Path:      base.Path,
+				Data:      configfile.ProfileSettings(fileProfiles[i], name),
+				KeyPrefix: configfile.ProfilesKey + "." + name,
+				Positions: base.Positions,
+			})
+		}
+	}
+	
+	if profileData != nil {
+		settings := configfile.WithoutProfiles(profileData)
+		delete(settings, configfile.ExtendsKey)
+		layers = append(layers, configLayer{
+			Source:    fmt.Sprintf("profile %s (%s)", app.profile, profilePath),
+			Path:      profilePath,
+			Data:      settings,
+			Positions: profilePositions,
+		})
+	}
+	
+	return layers, nil
+}
+
+// readConfigFile reads a configuration file and the positions of its keys.
+// An empty path yields no data.
+func readConfigFile(loader *configfile.Loader, path string) (map[string]any, configfile.Positions, error) {
+	if path == "" {
+		return nil, nil, nil
+	}
+	return loader.ReadMapPositions(path)
+}
+
+// mergeConfigLayers merges configuration layers in order into a single map
+func mergeConfigLayers(layers []configLayer) map[string]any {
+	merged := make(map[string]any)
+	for _, layer := range layers {
+		configfile.MergeMaps(merged, layer.Data)
+	}
+	return merged
+}
+
+// loadConfigIntoStruct loads the shared and command-specific sections of the
+// configuration file and active profile into struct. It returns the index paths of the
+// fields the files set and whether a file was found.
+func (app *Application) loadConfigIntoStruct(commandName string, config any) ([][]int, bool, error) {
+	loader := app.newConfigLoader()
+	
+	layers, err := app.sessionConfigLayers(loader)
+	if err != nil || len(layers) == 0 {
+		return nil, false, err
+	}
+	
+	if err := app.checkConfigSections(loader, layers, commandName); err != nil {
+		return nil, false, err
+	}
+	
+	// Values are checked per layer so errors point at the file that set them
+	if descriptor, exists := app.registry.GetCommand(commandName); exists {
+		if errs := checkConfigValues(loader, layers, commandName, descriptor.GetConfigType()); len(errs) > 0 {
+			return nil, false, errs
+		}
+	}
+	
+	section, err := configfile.CommandConfig(mergeConfigLayers(layers), commandName)
+	if err == nil {
+		err = loader.MapToStruct(section, config)
+	}
+	if err != nil {
+		if path := failingConfigLayer(loader, layers, commandName, reflect.TypeOf(config)); path != "" {
+			return nil, false, fmt.Errorf("%s: %w", path, err)
+		}
+		return nil, false, err
+	}
+	
+	return loader.SetFields(section, reflect.TypeOf(config)), true, nil
+}
+
+// failingConfigLayer returns the path of the first layer whose settings for a command
+// fail to load on their own, or "" when only the merged settings fail
+func failingConfigLayer(loader *configfile.Loader, layers []configLayer, commandName string, configType reflect.Type) string {
+	for _, layer := range layers {
+		section, err := configfile.CommandConfig(layer.Data, commandName)
+		if err == nil {
+			err = loader.MapToStruct(section, reflect.New(configType.Elem()).Interface())
+		}
+		if err != nil {
+			return layer.Path
+		}
+	}
+	return ""
+}
+
+// checkConfigValues checks the type and allowed values of every setting that applies to a command
+func checkConfigValues(loader *configfile.Loader, layers []configLayer, commandName string, configType reflect.Type) configfile.ValidationErrors {
+	var errs configfile.ValidationErrors
+	
+	for _, layer := range layers {
+		for _, scope := range configfile.CommandScopes(layer.Data, commandName) {
+			prefix := configfile.JoinKey(layer.KeyPrefix, scope.Prefix)
+			errs = append(errs, loader.CheckValues(scope.Data, configType, prefix, layer.Positions)...)
+		}
+	}
+	
+	errs.Sort()
+	return errs
+}
+
+// checkConfigSections reports unknown command sections and keys in the configuration file.
+// Problems are returned as an error in strict mode and printed as warnings otherwise.
+func (app *Application) checkConfigSections(loader *configfile.Loader, layers []configLayer, commandName string) error {
+	problems, err := app.configProblems(loader, layers, commandName)
+	if err != nil {
+		return err
+	}
+	
+	if len(problems) == 0 {
+		return nil
+	}
+	
+	if app.config.StrictConfig {
+		return problems
+	}
+	
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", problem)
+	}
+	
+	return nil
+}
+
+// configProblems returns the unknown command sections and keys in the configuration layers.
+// Only the section of commandName is checked for unknown keys, all command sections are
+// checked when commandName is empty.
+func (app *Application) configProblems(loader *configfile.Loader, layers []configLayer, commandName string) (configfile.ValidationErrors, error) {
+	var problems configfile.ValidationErrors
+	
+	commandNames := app.getRegisteredCommandNames()
+	
+	// Keys accepted by any command, only collected for suggestions on unknown shared keys
+	var allKnown []string
+	knownKeys := func() []string {
+		if allKnown == nil {
+			allKnown = []string{}
+			for _, name := range commandNames {
+				descriptor, _ := app.registry.GetCommand(name)
+				allKnown = append(allKnown, loader.KnownKeys(descriptor.GetConfigType())...)
+			}
+			slices.Sort(allKnown)
+			allKnown = slices.Compact(allKnown)
+		}
+		return allKnown
+	}
+	
+	for _, layer := range layers {
+		sections, err := configfile.CommandSections(layer.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Path, err)
+		}
+		
+		// Sections must name registered commands
+		for name, raw := range sections {
+			key := configfile.JoinKey(layer.KeyPrefix, configfile.CommandsKey+"."+name)
+			descriptor, exists := app.registry.GetCommand(name)
+			if !exists {
+				problem := &configfile.ValidationError{
+					Position: layer.Positions.Find(key),
+					Key:      key,
+					Message:  "unknown command section",
+				}
+				if suggestions := help.NewSuggestionEngine().SuggestCommands(name, commandNames); len(suggestions) > 0 {
+					problem.Message += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+				}
+				problems = append(problems, problem)
+				continue
+			}
+			
+			// Keys in the command sections must map onto the command's config
+			section, ok := raw.(map[string]any)
+			if !ok || (commandName != "" && name != commandName) {
+				continue
+			}
+			problems = append(problems, loader.CheckUnknownKeys(section, descriptor.GetConfigType(), key, layer.Positions)...)
+		}
+		
+		// Shared keys must be understood by at least one command
+		for _, scope := range configfile.CommandScopes(layer.Data, "") {
+			prefix := configfile.JoinKey(layer.KeyPrefix, scope.Prefix)
+			for _, key := range app.unknownSharedKeys(loader, scope.Data, commandNames, commandName) {
+				problems = append(problems, configfile.UnknownKeyError(key, prefix, layer.Positions, knownKeys()))
+			}
+		}
+	}
+	
+	problems.Sort()
+	return problems, nil
+}
+
+// unknownSharedKeys returns the keys of a shared section that no command accepts. A nested
+// key such as database.hots is unknown when every command rejects it or one of its sections.
+// The other commands are only checked when the dispatched command rejects a key.
+func (app *Application) unknownSharedKeys(loader *configfile.Loader, data map[string]any, commandNames []string, commandName string) []string {
+	if len(commandNames) == 0 {
+		return loader.UnknownKeys(data, reflect.TypeOf(struct{}{}))
+	}
+	
+	if descriptor, exists := app.registry.GetCommand(commandName); exists && len(loader.UnknownKeys(data, descriptor.GetConfigType())) == 0 {
+		return nil
+	}
+	
+	unknownSets := make([][]string, len(commandNames))
+	var candidates []string
+	for i, name := range commandNames {
+		descriptor, _ := app.registry.GetCommand(name)
+		unknownSets[i] = loader.UnknownKeys(data, descriptor.GetConfigType())
+		candidates = append(candidates, unknownSets[i]...)
+	}
+	slices.Sort(candidates)
+	candidates = slices.Compact(candidates)
+	
+	var unknown []string
+	for _, key := range candidates {
+		rejected := true
+		for _, set := range unknownSets {
+			if !slices.ContainsFunc(set, func(u string) bool { return u == key || strings.HasPrefix(key, u+".") }) {
+				rejected = false
+				break
+			}
+		}
+		if rejected {
+			unknown = append(unknown, key)
+		}
+	}
+	
+	return unknown
+}
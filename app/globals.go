@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/help"
+)
+
+// globalFlag describes an application-level flag accepted before or after the command name
+type globalFlag struct {
+	Name        string
+	Type        string
+	Description string
+	Set         func(app *Application, value string)
+	Get         func(app *Application) string
+}
+
+// builtinGlobalFlags lists the application-level flags of every application
+var builtinGlobalFlags = []globalFlag{
+	{
+		Name:        "profile",
+		Type:        "name",
+		Description: "Configuration profile to use",
+		Set: func(app *Application, value string) {
+			app.profile = value
+		},
+		Get: func(app *Application) string {
+			return app.profile
+		},
+	},
+	{
+		Name:        "config",
+		Type:        "file",
+		Description: "Configuration file merged over the searched files",
+		Set: func(app *Application, value string) {
+			app.configPath = value
+		},
+		Get: func(app *Application) string {
+			return app.configPath
+		},
+	},
+}
+
+// globalFlagsHelp returns help entries for application-level flags
+func globalFlagsHelp(globalFlags []globalFlag) []help.FlagHelp {
+	flags := make([]help.FlagHelp, 0, len(globalFlags))
+	for _, flag := range globalFlags {
+		flags = append(flags, help.FlagHelp{
+			Long:        flag.Name,
+			Type:        flag.Type,
+			Description: flag.Description,
+		})
+	}
+	return flags
+}
+
+// findGlobalFlag returns the application-level flag with the given name
+func (app *Application) findGlobalFlag(name string) (*globalFlag, bool) {
+	for i := range app.globalFlags {
+		if app.globalFlags[i].Name == name {
+			return &app.globalFlags[i], true
+		}
+	}
+	return nil, false
+}
+
+// extractGlobalFlags resets the per-run state, applies application-level flags
+// and returns the remaining arguments. Flags after the command name are left to
+// the command when its config defines a flag with the same name.
+func (app *Application) extractGlobalFlags(args []string) ([]string, error) {
+	app.profile = app.config.Profile
+	app.profileChain = nil
+	app.configPath = ""
+	
+	rest := make([]string, 0, len(args))
+	var metadata *bind.StructMetadata
+	commandSeen := false
+	commandName := ""
+	commandIndex := 0
+	
+	// Flags before the command are in the application's dialect
+	args = slices.Clone(args)
+	dialect := app.executor.Dialect("")
+	
+	for i := 0; i < len(args); i++ {
+		if !commandSeen {
+			args[i] = dialect.Normalize(args[i], app.isApplicationFlag)
+		}
+		arg := args[i]
+		
+		// Everything after -- belongs to the command
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		
+		if !strings.HasPrefix(arg, "--") {
+			if !commandSeen && !strings.HasPrefix(arg, "-") {
+				commandSeen = true
+				commandIndex = len(rest)
+				commandName = arg
+				if app.config.Abbreviations {
+					if resolved, err := app.resolveCommandName(arg); err == nil {
+						commandName = resolved
+					}
+				}
+				metadata = app.commandMetadata(commandName)
+				
+				// Plugins parse their own arguments
+				if _, plugin := app.findPlugin(commandName); plugin {
+					rest = append(rest, args[i:]...)
+					break
+				}
+				
+				// The command's arguments are in its own dialect
+				args = append(args[:i+1], app.executor.NormalizeArgs(commandName, args[i+1:])...)
+			}
+			rest = append(rest, arg)
+			continue
+		}
+		
+		name, value, hasValue := strings.Cut(arg[2:], "=")
+		flag, isGlobal := app.findGlobalFlag(name)
+		if !isGlobal {
+			rest = append(rest, arg)
+			continue
+		}
+		
+		// Arguments the command passes through to a wrapped tool are left alone
+		if metadata != nil && app.passedThrough(commandName, rest[commandIndex+1:], arg) {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		
+		// The command's own flag wins over a global of the same name
+		if metadata != nil {
+			if _, defined := metadata.FieldMap[name]; defined {
+				rest = append(rest, arg)
+				continue
+			}
+		}
+		
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag --%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		
+		flag.Set(app, value)
+	}
+	
+	app.executor.SetProfile(app.profile)
+	
+	return rest, nil
+}
+
+// commandIndex returns the index of the command name in args, skipping the application
+// flags and their values before it, or -1 when args name no command
+func (app *Application) commandIndex(args []string) int {
+	dialect := app.executor.Dialect("")
+	
+	for i := 0; i < len(args); i++ {
+		arg := dialect.Normalize(args[i], app.isApplicationFlag)
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		if name, ok := strings.CutPrefix(arg, "--"); ok && !strings.Contains(name, "=") {
+			if _, isGlobal := app.findGlobalFlag(name); isGlobal {
+				i++
+			}
+		}
+	}
+	
+	return -1
+}
+
+// isApplicationFlag reports whether name is a flag accepted before the command name
+func (app *Application) isApplicationFlag(name string) bool {
+	if _, isGlobal := app.findGlobalFlag(name); isGlobal {
+		return true
+	}
+	return name == "help" || name == "h" || name == "version" || name == "v"
+}
+
+// passedThrough reports whether the command no longer parses arg for flags after the
+// command arguments so far, e.g. after the first positional in POSIX order
+func (app *Application) passedThrough(commandName string, commandArgs []string, arg string) bool {
+	probe := append(slices.Clone(commandArgs), arg)
+	return app.executor.FlagsEnd(commandName, probe) < len(probe)
+}
+
+// commandMetadata returns the analyzed config metadata of a registered command, or nil
+func (app *Application) commandMetadata(commandName string) *bind.StructMetadata {
+	descriptor, exists := app.registry.GetCommand(commandName)
+	if !exists {
+		return nil
+	}
+	
+	metadata, err := bind.NewAnalyzer("posix").Analyze(descriptor.GetConfigType())
+	if err != nil {
+		return nil
+	}
+	
+	return metadata
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// pluginPrefix returns the prefix of plugin executables, e.g. "tool-" for tool-foo
+func (app *Application) pluginPrefix() string {
+	return app.config.Name + "-"
+}
+
+// pluginDirs returns the directories searched for plugins, the configured ones first.
+// Empty PATH entries are skipped, rather than searching the current directory.
+func (app *Application) pluginDirs() []string {
+	dirs := append([]string{}, app.config.PluginDirs...)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// findPlugin returns the path of the plugin executable for a command name, when
+// plugins are enabled and the name is not a registered command
+func (app *Application) findPlugin(name string) (string, bool) {
+	if !app.config.Plugins || name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	if _, registered := app.registry.GetCommand(name); registered {
+		return "", false
+	}
+	
+	for _, dir := range app.pluginDirs() {
+		if path := filepath.Join(dir, app.pluginPrefix()+name); isExecutable(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// discoverPlugins returns the plugins found in the plugin directories by command name.
+// The first executable of a name wins, as when running it.
+func (app *Application) discoverPlugins() map[string]string {
+	plugins := make(map[string]string)
+	if !app.config.Plugins {
+		return plugins
+	}
+	
+	for _, dir := range app.pluginDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, found := strings.CutPrefix(entry.Name(), app.pluginPrefix())
+			if !found || name == "" {
+				continue
+			}
+			if _, seen := plugins[name]; seen {
+				continue
+			}
+			if _, registered := app.registry.GetCommand(name); registered {
+				continue
+			}
+			if path := filepath.Join(dir, entry.Name()); isExecutable(path) {
+				plugins[name] = path
+			}
+		}
+	}
+	return plugins
+}
+
+// runPlugin runs a plugin with the command arguments and returns its exit code. Global
+// flags are passed as environment variables, e.g. TOOL_PROFILE for --profile.
+func (app *Application) runPlugin(ctx context.Context, path string, args []string) int {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), app.pluginEnv()...)
+	
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to run plugin %s: %w", path, err)
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	return 0
+}
+
+// pluginEnv returns the global flags set for this run as environment variables
+func (app *Application) pluginEnv() []string {
+	replacer := strings.NewReplacer("-", "_", ".", "_")
+	prefix := strings.ToUpper(replacer.Replace(app.config.Name)) + "_"
+	
+	var env []string
+	for _, flag := range app.globalFlags {
+		if flag.Get == nil {
+			continue
+		}
+		if value := flag.Get(app); value != "" {
+			env = append(env, prefix+strings.ToUpper(replacer.Replace(flag.Name))+"="+value)
+		}
+	}
+	return env
+}
+
+// isExecutable reports whether path is a regular file that can be executed
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/eugener/clix/config"
+	"github.com/eugener/clix/core"
+)
+
+// pluginScript records its arguments and the profile passed by the application
+const pluginScript = "#!/bin/sh\nprintf '%s\\n' \"$*\" \"$TOOL_PROFILE\" > \"$PLUGIN_OUT\"\nexit 3\n"
+
+// installPlugin writes an executable tool-<name> script to a directory on the PATH
+// and returns the file the script records its run in
+func installPlugin(t *testing.T, name string) string {
+	t.Helper()
+	
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "tool-"+name), []byte(pluginScript), 0755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	
+	out := filepath.Join(t.TempDir(), "plugin.out")
+	t.Setenv("PLUGIN_OUT", out)
+	return out
+}
+
+func TestPlugins(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   string
+		file     string
+		args     []string
+		wantCode int
+		wantOut  string // recorded by the plugin, empty when it must not run
+		wantRuns int
+	}{
+		{name: "runs the plugin", plugin: "greet", args: []string{"greet", "a", "--loud"}, wantCode: 3, wantOut: "a --loud\n\n"},
+		{name: "global flags in the environment", plugin: "greet", file: "profiles:\n  prod: {}\n", args: []string{"--profile", "prod", "greet"}, wantCode: 3, wantOut: "\nprod\n"},
+		{name: "registered command wins", plugin: "deploy", args: []string{"deploy"}, wantRuns: 1},
+		{name: "config checks skip plugins", plugin: "greet", file: "region: eu\ncommands:\n  deploy:\n    replicas: 2\n", args: []string{"deploy"}, wantRuns: 1},
+		{name: "plugins have no config section", plugin: "greet", file: "commands:\n  greet:\n    loud: true\n", args: []string{"deploy"}, wantCode: 1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := installPlugin(t, tt.plugin)
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeFile(t, dir, "tool.yaml", tt.file)
+			}
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs, config.WithPlugins(), config.WithStrictConfig(true))
+			if code := application.Run(context.Background(), tt.args); code != tt.wantCode {
+				t.Fatalf("Run(%q) = %d, want %d", tt.args, code, tt.wantCode)
+			}
+			if len(runs) != tt.wantRuns {
+				t.Errorf("deploy ran %d times, want %d", len(runs), tt.wantRuns)
+			}
+			
+			got, err := os.ReadFile(out)
+			if tt.wantOut == "" {
+				if err == nil {
+					t.Errorf("plugin ran with %q", got)
+				}
+				return
+			}
+			if string(got) != tt.wantOut {
+				t.Errorf("plugin recorded %q, want %q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestPluginsWithConfigCommands(t *testing.T) {
+	installPlugin(t, "greet")
+	dir := t.TempDir()
+	writeFile(t, dir, "tool.yaml", "region: eu\n")
+	
+	var runs []deployConfig
+	application := newTestApp(t, dir, &runs, config.WithPlugins())
+	
+	if err := application.ValidateConfig(); err != nil {
+		t.Errorf("ValidateConfig() error = %v", err)
+	}
+	if _, err := application.SetConfigValue("", "replicas", "3"); err != nil {
+		t.Errorf("SetConfigValue() error = %v", err)
+	}
+	if value, _, err := application.GetConfigValue("", "region"); err != nil || value != "eu" {
+		t.Errorf("GetConfigValue() = %v, %v, want eu", value, err)
+	}
+	if _, err := application.SetConfigValue("greet", "loud", "true"); err == nil || !strings.Contains(err.Error(), "command greet not found") {
+		t.Errorf("SetConfigValue() for a plugin error = %v", err)
+	}
+}
+
+func TestPluginDirs(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	t.Setenv("PATH", sep+"/usr/bin"+sep+sep+"/bin"+sep)
+	
+	var runs []deployConfig
+	application := newTestApp(t, t.TempDir(), &runs, config.WithPlugins("/opt/tool"))
+	if got, want := application.pluginDirs(), []string{"/opt/tool", "/usr/bin", "/bin"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pluginDirs() = %q, want %q", got, want)
+	}
+}
+
+func TestPluginEnv(t *testing.T) {
+	provider := &testProvider{flags: []core.GlobalFlag{{Name: "log-level", Set: func(value string) {}}}}
+	
+	var runs []deployConfig
+	application := newTestApp(t, t.TempDir(), &runs, config.WithName("my-tool"))
+	if err := application.AddProvider(provider); err != nil {
+		t.Fatalf("AddProvider() error = %v", err)
+	}
+	if code := application.Run(context.Background(), []string{"--log-level=debug", "deploy"}); code != 0 {
+		t.Fatalf("Run() = %d, want 0", code)
+	}
+	if got, want := application.pluginEnv(), []string{"MY_TOOL_LOG_LEVEL=debug"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pluginEnv() = %q, want %q", got, want)
+	}
+}
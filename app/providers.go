@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/eugener/clix/core"
+	"github.com/eugener/clix/internal/help"
+)
+
+// AddProvider adds the commands, global flags, middleware and help topics of a
+// provider. Names colliding with existing commands, flags or topics are errors, and
+// the application is left unchanged.
+func (app *Application) AddProvider(provider core.CommandProvider) error {
+	return app.addProvider("", provider)
+}
+
+// AddNamespacedProvider adds a provider with its commands named namespace:command, so
+// they cannot collide with the commands of the application or other providers
+func (app *Application) AddNamespacedProvider(namespace string, provider core.CommandProvider) error {
+	if namespace == "" {
+		return fmt.Errorf("provider %s: namespace must not be empty", provider.Name())
+	}
+	return app.addProvider(namespace+":", provider)
+}
+
+// addProvider checks all names of a provider, then adds its contributions
+func (app *Application) addProvider(prefix string, provider core.CommandProvider) error {
+	commands := provider.Commands()
+	names := make([]string, 0, len(commands))
+	seen := make(map[string]bool)
+	
+	for _, cmd := range commands {
+		named, ok := cmd.(interface{ Name() string })
+		if !ok {
+			return fmt.Errorf("provider %s: command %T must implement Name()", provider.Name(), cmd)
+		}
+		
+		name := prefix + named.Name()
+		if seen[name] || app.isCommandName(name) {
+			return fmt.Errorf("provider %s: command %s already registered", provider.Name(), name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	
+	var flags []core.GlobalFlag
+	if flagProvider, ok := provider.(core.GlobalFlagProvider); ok {
+		flags = flagProvider.GlobalFlags()
+	}
+	for i, flag := range flags {
+		_, defined := app.findGlobalFlag(flag.Name)
+		if defined || flag.Name == "help" || flag.Name == "version" || containsFlag(flags[:i], flag.Name) {
+			return fmt.Errorf("provider %s: global flag --%s already defined", provider.Name(), flag.Name)
+		}
+	}
+	
+	var topics []core.HelpTopic
+	if topicProvider, ok := provider.(core.HelpTopicProvider); ok {
+		topics = topicProvider.HelpTopics()
+	}
+	for i, topic := range topics {
+		_, defined := app.findHelpTopic(topic.Name)
+		if defined || seen[topic.Name] || app.isCommandName(topic.Name) || containsTopic(topics[:i], topic.Name) {
+			return fmt.Errorf("provider %s: help topic %s collides with a command or topic", provider.Name(), topic.Name)
+		}
+	}
+	
+	// Commands are checked on a scratch registry first, so none is added when one fails
+	scratch := core.NewRegistry()
+	for i, cmd := range commands {
+		if err := scratch.RegisterAs(names[i], cmd); err != nil {
+			return fmt.Errorf("provider %s: %w", provider.Name(), err)
+		}
+	}
+	for i, cmd := range commands {
+		if err := app.registry.RegisterAs(names[i], cmd); err != nil {
+			return fmt.Errorf("provider %s: %w", provider.Name(), err)
+		}
+	}
+	
+	for _, flag := range flags {
+		var last string
+		app.globalFlags = append(app.globalFlags, globalFlag{
+			Name:        flag.Name,
+			Type:        flag.Type,
+			Description: flag.Description,
+			Set: func(app *Application, value string) {
+				last = value
+				flag.Set(value)
+			},
+			Get: func(app *Application) string {
+				if flag.Get != nil {
+					return flag.Get()
+				}
+				return last
+			},
+		})
+		app.helpGen.AddGlobalFlags(help.FlagHelp{
+			Long:        flag.Name,
+			Type:        flag.Type,
+			Description: flag.Description,
+		})
+	}
+	
+	if middlewareProvider, ok := provider.(core.MiddlewareProvider); ok {
+		app.executor.Use(middlewareProvider.Middleware()...)
+	}
+	
+	for _, topic := range topics {
+		app.helpTopics = append(app.helpTopics, topic)
+		app.helpGen.AddTopics(help.TopicHelp{Name: topic.Name, Summary: topic.Summary})
+	}
+	
+	return nil
+}
+
+// isCommandName reports whether a name is taken by a registered or built-in command
+func (app *Application) isCommandName(name string) bool {
+	if _, registered := app.registry.GetCommand(name); registered {
+		return true
+	}
+	return name == "help" ||
+		(app.config.ConfigCommand && name == configCommandName) ||
+		(app.config.SchemaCommand && name == schemaCommandName) ||
+		(app.config.ShellCommand && name == shellCommandName)
+}
+
+// findHelpTopic returns the help topic with the given name
+func (app *Application) findHelpTopic(name string) (core.HelpTopic, bool) {
+	for _, topic := range app.helpTopics {
+		if topic.Name == name {
+			return topic, true
+		}
+	}
+	return core.HelpTopic{}, false
+}
+
+// containsFlag reports whether flags include one with the given name
+func containsFlag(flags []core.GlobalFlag, name string) bool {
+	for _, flag := range flags {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTopic reports whether topics include one with the given name
+func containsTopic(topics []core.HelpTopic, name string) bool {
+	for _, topic := range topics {
+		if topic.Name == name {
+			return true
+		}
+	}
+	return false
+}
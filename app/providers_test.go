@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eugener/clix/core"
+	"github.com/eugener/clix/internal/help"
+)
+
+// testProvider contributes the commands, flags, middleware and topics it is given
+type testProvider struct {
+	commands   []any
+	flags      []core.GlobalFlag
+	middleware []core.Middleware
+	topics     []core.HelpTopic
+}
+
+func (p *testProvider) Name() string                   { return "company" }
+func (p *testProvider) Commands() []any                { return p.commands }
+func (p *testProvider) GlobalFlags() []core.GlobalFlag { return p.flags }
+func (p *testProvider) Middleware() []core.Middleware  { return p.middleware }
+func (p *testProvider) HelpTopics() []core.HelpTopic   { return p.topics }
+
+// statusCommand creates a provider command recording its runs in runs
+func statusCommand(name string, runs *[]string) *core.CommandBase[deployConfig] {
+	return core.NewCommand(name, "Show status", func(ctx context.Context, c deployConfig) error {
+		*runs = append(*runs, name)
+		return nil
+	})
+}
+
+// namedCommand has a name but no Run method, so registering it fails
+type namedCommand struct{}
+
+func (namedCommand) Name() string { return "named" }
+
+func TestAddProvider(t *testing.T) {
+	var runs []string
+	
+	tests := []struct {
+		name       string
+		namespaced bool
+		namespace  string
+		provider   *testProvider
+		wantErr    string
+	}{
+		{name: "commands", provider: &testProvider{commands: []any{statusCommand("status", &runs)}}},
+		{name: "namespaced", namespaced: true, namespace: "company", provider: &testProvider{commands: []any{statusCommand("deploy", &runs)}}},
+		{name: "empty namespace", namespaced: true, provider: &testProvider{}, wantErr: "provider company: namespace must not be empty"},
+		{name: "collides with a command", provider: &testProvider{commands: []any{statusCommand("deploy", &runs)}}, wantErr: "provider company: command deploy already registered"},
+		{name: "collides with a built-in command", provider: &testProvider{commands: []any{statusCommand("help", &runs)}}, wantErr: "command help already registered"},
+		{name: "duplicate command", provider: &testProvider{commands: []any{statusCommand("status", &runs), statusCommand("status", &runs)}}, wantErr: "command status already registered"},
+		{name: "invalid command after a valid one", provider: &testProvider{commands: []any{statusCommand("status", &runs), namedCommand{}}}, wantErr: "must implement Name(), Description(), and Run()"},
+		{name: "command without a name", provider: &testProvider{commands: []any{struct{}{}}}, wantErr: "must implement Name()"},
+		{name: "collides with a global flag", provider: &testProvider{flags: []core.GlobalFlag{{Name: "profile"}}}, wantErr: "global flag --profile already defined"},
+		{name: "collides with --help", provider: &testProvider{flags: []core.GlobalFlag{{Name: "help"}}}, wantErr: "global flag --help already defined"},
+		{name: "duplicate global flag", provider: &testProvider{flags: []core.GlobalFlag{{Name: "zone"}, {Name: "zone"}}}, wantErr: "global flag --zone already defined"},
+		{name: "topic collides with a command", provider: &testProvider{topics: []core.HelpTopic{{Name: "deploy"}}}, wantErr: "help topic deploy collides"},
+		{name: "topic collides with a provided command", provider: &testProvider{commands: []any{statusCommand("status", &runs)}, topics: []core.HelpTopic{{Name: "status"}}}, wantErr: "help topic status collides"},
+		{name: "duplicate topic", provider: &testProvider{topics: []core.HelpTopic{{Name: "auth"}, {Name: "auth"}}}, wantErr: "help topic auth collides"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deployRuns []deployConfig
+			application := newTestApp(t, t.TempDir(), &deployRuns)
+			commands := len(application.registry.ListCommands())
+			flags := len(application.globalFlags)
+			
+			var err error
+			if tt.namespaced {
+				err = application.AddNamespacedProvider(tt.namespace, tt.provider)
+			} else {
+				err = application.AddProvider(tt.provider)
+			}
+			
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AddProvider() error = %v, want %q", err, tt.wantErr)
+				}
+				// A rejected provider leaves the application unchanged
+				if got := len(application.registry.ListCommands()); got != commands {
+					t.Errorf("commands = %d after a rejected provider, want %d", got, commands)
+				}
+				if got := len(application.globalFlags); got != flags {
+					t.Errorf("global flags = %d after a rejected provider, want %d", got, flags)
+				}
+				if len(application.helpTopics) != 0 {
+					t.Errorf("help topics = %v after a rejected provider", application.helpTopics)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddProvider() error = %v", err)
+			}
+			
+			name := "status"
+			if tt.namespace != "" {
+				name = tt.namespace + ":deploy"
+			}
+			if _, registered := application.registry.GetCommand(name); !registered {
+				t.Errorf("command %s not registered", name)
+			}
+		})
+	}
+}
+
+func TestProviderContributions(t *testing.T) {
+	var zone string
+	var events []string
+	provider := &testProvider{
+		flags: []core.GlobalFlag{{Name: "zone", Type: "name", Description: "Availability zone", Set: func(value string) { zone = value }}},
+		middleware: []core.Middleware{func(next core.ExecuteFunc) core.ExecuteFunc {
+			return func(ctx *core.ExecutionContext) error {
+				events = append(events, "middleware "+ctx.CommandName)
+				return next(ctx)
+			}
+		}},
+		topics: []core.HelpTopic{{Name: "auth", Summary: "How to log in", Text: "Run tool login.\n"}},
+	}
+	provider.commands = []any{statusCommand("status", &events)}
+	
+	var runs []deployConfig
+	application := newTestApp(t, t.TempDir(), &runs)
+	if err := application.AddNamespacedProvider("company", provider); err != nil {
+		t.Fatalf("AddNamespacedProvider() error = %v", err)
+	}
+	
+	tests := []struct {
+		name       string
+		args       []string
+		wantZone   string
+		wantEvents []string
+	}{
+		{name: "provided command", args: []string{"company:status"}, wantEvents: []string{"middleware company:status", "status"}},
+		{name: "global flag before the command", args: []string{"--zone", "eu-1", "deploy"}, wantZone: "eu-1", wantEvents: []string{"middleware deploy"}},
+		{name: "global flag after the command", args: []string{"company:status", "--zone=us-2"}, wantZone: "us-2", wantEvents: []string{"middleware company:status", "status"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, events = "", nil
+			
+			if code := application.Run(context.Background(), tt.args); code != 0 {
+				t.Fatalf("Run(%q) = %d, want 0", tt.args, code)
+			}
+			if zone != tt.wantZone {
+				t.Errorf("zone = %q, want %q", zone, tt.wantZone)
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("events = %q, want %q", events, tt.wantEvents)
+			}
+		})
+	}
+	
+	if topic, found := application.findHelpTopic("auth"); !found || topic.Text != "Run tool login.\n" {
+		t.Errorf("findHelpTopic(auth) = %+v, %v", topic, found)
+	}
+	mainHelp := application.helpGen.GenerateMainHelp(map[string]help.CommandInfo{})
+	for _, want := range []string{"Help Topics:\n  auth  How to log in\n", "--zone"} {
+		if !strings.Contains(mainHelp, want) {
+			t.Errorf("main help misses %q:\n%s", want, mainHelp)
+		}
+	}
+}
+
+func TestProviderGlobalFlagValues(t *testing.T) {
+	zone := "eu-1"
+	provider := &testProvider{flags: []core.GlobalFlag{
+		{Name: "zone", Set: func(value string) { zone = value }, Get: func() string { return zone }},
+		{Name: "tier", Set: func(value string) {}},
+	}}
+	
+	var runs []deployConfig
+	application := newTestApp(t, t.TempDir(), &runs)
+	if err := application.AddProvider(provider); err != nil {
+		t.Fatalf("AddProvider() error = %v", err)
+	}
+	
+	if want := []string{"--zone=eu-1"}; !reflect.DeepEqual(application.shellGlobalFlags(), want) {
+		t.Errorf("shellGlobalFlags() = %q before setting flags, want %q", application.shellGlobalFlags(), want)
+	}
+	if code := application.Run(context.Background(), []string{"--zone", "us-2", "--tier", "gold", "deploy"}); code != 0 {
+		t.Fatalf("Run() = %d, want 0", code)
+	}
+	if want := []string{"--zone=us-2", "--tier=gold"}; !reflect.DeepEqual(application.shellGlobalFlags(), want) {
+		t.Errorf("shellGlobalFlags() = %q, want %q", application.shellGlobalFlags(), want)
+	}
+	if want := []string{"TOOL_ZONE=us-2", "TOOL_TIER=gold"}; !reflect.DeepEqual(application.pluginEnv(), want) {
+		t.Errorf("pluginEnv() = %q, want %q", application.pluginEnv(), want)
+	}
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/eugener/clix/core"
+	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/configfile"
+)
+
+// watchConfig watches the configuration files of a running command and delivers
+// each new valid configuration to it. Invalid configurations are logged and the
+// command keeps its current configuration.
+func (app *Application) watchConfig(execCtx *core.ExecutionContext) func() {
+	ctx, cancel := context.WithCancel(execCtx.Context)
+	done := make(chan struct{})
+	
+	commandName := execCtx.CommandName
+	logger := execCtx.Logger
+	current := execCtx.Config
+	
+	watcher := configfile.NewWatcher(app.config.ConfigReload, app.configWatchFiles)
+	
+	go func() {
+		defer close(done)
+		watcher.Watch(ctx, func() {
+			next, err := app.reloadConfig(execCtx)
+			if err != nil {
+				logger.Error("configuration reload rejected", "command", commandName, "error", err)
+				return
+			}
+			
+			changes := diffConfigs(current, next)
+			if len(changes) == 0 {
+				logger.Debug("configuration files changed without affecting settings", "command", commandName)
+				return
+			}
+			
+			if err := app.executor.Reload(ctx, commandName, next); err != nil {
+				logger.Error("command rejected configuration reload", "command", commandName, "error", err)
+				return
+			}
+			
+			current = next
+			logger.Info("configuration reloaded", "command", commandName, "changes", changes)
+		})
+	}()
+	
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// reloadConfig runs the configuration pipeline of a running command again:
+// files, environment, arguments and validation
+func (app *Application) reloadConfig(execCtx *core.ExecutionContext) (any, error) {
+	baseConfig, err := app.loadConfigurationFile(execCtx.CommandName, execCtx.Args)
+	if err != nil {
+		return nil, err
+	}
+	
+	return app.executor.RebuildConfig(execCtx, baseConfig)
+}
+
+// configWatchFiles returns the configuration files to watch: every file the active
+// configuration was read from, or the default location when there is none yet
+func (app *Application) configWatchFiles() []string {
+	loader := app.newConfigLoader()
+	
+	layers, err := app.loadConfigLayers(loader)
+	if err != nil || len(layers) == 0 {
+		files, _ := app.configFiles(loader)
+		if len(files) == 0 {
+			return []string{loader.DefaultPath()}
+		}
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = file.Path
+		}
+		return paths
+	}
+	
+	var files []string
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		for _, file := range append([]string{layer.Path}, layer.Positions.Files()...) {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	
+	return files
+}
+
+// diffConfigs lists the settings that differ between two configurations as "key: old -> new",
+// without the values of secret fields
+func diffConfigs(previous, next any) []string {
+	previousValue := reflect.Indirect(reflect.ValueOf(previous))
+	nextValue := reflect.Indirect(reflect.ValueOf(next))
+	
+	metadata, err := bind.NewAnalyzer("posix").Analyze(nextValue.Type())
+	if err != nil {
+		return []string{"(configuration changed)"}
+	}
+	
+	var changes []string
+	for _, field := range metadata.Fields {
+		before := previousValue.FieldByIndex(field.Index).Interface()
+		after := nextValue.FieldByIndex(field.Index).Interface()
+		switch {
+		case reflect.DeepEqual(before, after):
+		case field.Secret:
+			changes = append(changes, field.Path+": (secret changed)")
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", field.Path, before, after))
+		}
+	}
+	
+	return changes
+}
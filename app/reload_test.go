@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eugener/clix/config"
+	"github.com/eugener/clix/core"
+	"github.com/eugener/clix/internal/configfile"
+)
+
+type serveConfig struct {
+	Port  int    `posix:"p,port,Port"`
+	Token string `posix:",token,API token,secret"`
+}
+
+func TestConfigReload(t *testing.T) {
+	tests := []struct {
+		name   string
+		update string
+		want   []serveConfig
+	}{
+		{name: "valid change", update: "port: 9090\n", want: []serveConfig{{Port: 9090}}},
+		{name: "invalid change is rejected", update: "port: nope\n"},
+		{name: "change without effect", update: "# comment\nport: 8080\n"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := writeFile(t, dir, "tool.yaml", "port: 8080\n")
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs, config.WithConfigReload(5*time.Millisecond))
+			
+			reloads := make(chan serveConfig, 10)
+			command := core.NewCommand("serve", "Serve", func(ctx context.Context, c serveConfig) error {
+				// Let the watcher take its first snapshot, then replace the file
+				// atomically so it is never seen half written
+				time.Sleep(20 * time.Millisecond)
+				if err := configfile.WriteFileAtomic(path, []byte(tt.update)); err != nil {
+					return err
+				}
+				time.Sleep(100 * time.Millisecond)
+				return nil
+			}).OnReload(func(ctx context.Context, c serveConfig) error {
+				reloads <- c
+				return nil
+			})
+			if err := application.Register(command); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			
+			if code := application.Run(context.Background(), []string{"serve"}); code != 0 {
+				t.Fatalf("Run() = %d, want 0", code)
+			}
+			close(reloads)
+			
+			var got []serveConfig
+			for c := range reloads {
+				got = append(got, c)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reloads = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigReloadInjectsServices(t *testing.T) {
+	type watchConfig struct {
+		Port     int            `posix:"p,port,Port"`
+		Recorder *closeRecorder `inject:""`
+	}
+	
+	dir := t.TempDir()
+	path := writeFile(t, dir, "tool.yaml", "port: 8080\n")
+	
+	var events []string
+	var runs []deployConfig
+	application := newTestApp(t, dir, &runs, config.WithConfigReload(5*time.Millisecond))
+	recorder := &closeRecorder{events: &events}
+	Provide(application, func(ctx context.Context) (*closeRecorder, error) {
+		return recorder, nil
+	})
+	
+	reloads := make(chan watchConfig, 10)
+	command := core.NewCommand("watch", "Watch", func(ctx context.Context, c watchConfig) error {
+		time.Sleep(20 * time.Millisecond)
+		if err := configfile.WriteFileAtomic(path, []byte("port: 9090\n")); err != nil {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}).OnReload(func(ctx context.Context, c watchConfig) error {
+		reloads <- c
+		return nil
+	})
+	if err := application.Register(command); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	if code := application.Run(context.Background(), []string{"watch"}); code != 0 {
+		t.Fatalf("Run() = %d, want 0", code)
+	}
+	close(reloads)
+	
+	var got []watchConfig
+	for c := range reloads {
+		got = append(got, c)
+	}
+	if want := []watchConfig{{Port: 9090, Recorder: recorder}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reloads = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous serveConfig
+		next     serveConfig
+		want     []string
+	}{
+		{name: "unchanged", previous: serveConfig{Port: 1}, next: serveConfig{Port: 1}},
+		{name: "changed value", previous: serveConfig{Port: 1}, next: serveConfig{Port: 2}, want: []string{"port: 1 -> 2"}},
+		{name: "secret is not shown", previous: serveConfig{Token: "a"}, next: serveConfig{Token: "b"}, want: []string{"token: (secret changed)"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffConfigs(&tt.previous, &tt.next); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffConfigs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/eugener/clix/internal/configfile"
+)
+
+// schemaCommandName is the name of the built-in schema command
+const schemaCommandName = "schema"
+
+// isSchemaCommand checks if the argument invokes the built-in schema command.
+// A registered command with the same name always takes precedence.
+func (app *Application) isSchemaCommand(arg string) bool {
+	if !app.config.SchemaCommand || arg != schemaCommandName {
+		return false
+	}
+	_, registered := app.registry.GetCommand(schemaCommandName)
+	return !registered
+}
+
+// handleSchemaCommand prints the schema of the config file, or of one command
+func (app *Application) handleSchemaCommand(args []string) int {
+	var schema []byte
+	var err error
+	
+	switch {
+	case len(args) == 1 && app.isHelpRequest(args[0]):
+		fmt.Printf("Print the JSON Schema of the configuration file\n\nUsage:\n  %s schema [command]\n", app.config.Name)
+		return 0
+	case len(args) == 0:
+		schema, err = app.ConfigSchema()
+	case len(args) == 1:
+		schema, err = app.CommandSchema(args[0])
+	default:
+		err = fmt.Errorf("usage: %s schema [command]", app.config.Name)
+	}
+	
+	if err != nil {
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	
+	fmt.Println(string(schema))
+	return 0
+}
+
+// ConfigSchema returns a JSON Schema describing the configuration file of the
+// application, for editor completion and for validating config files in CI
+func (app *Application) ConfigSchema() ([]byte, error) {
+	commands := make(map[string]reflect.Type)
+	for name, descriptor := range app.registry.ListCommands() {
+		commands[name] = descriptor.GetConfigType()
+	}
+	
+	schema, err := configfile.NewSchemaGenerator().FileSchema(app.config.Name+" configuration", commands)
+	if err != nil {
+		return nil, err
+	}
+	
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// CommandSchema returns a JSON Schema describing the configuration of a single command
+func (app *Application) CommandSchema(commandName string) ([]byte, error) {
+	descriptor, exists := app.registry.GetCommand(commandName)
+	if !exists {
+		return nil, fmt.Errorf("command %s not found", commandName)
+	}
+	
+	schema, err := configfile.NewSchemaGenerator().CommandSchema(descriptor.GetConfigType())
+	if err != nil {
+		return nil, err
+	}
+	schema["title"] = commandName
+	
+	return json.MarshalIndent(schema, "", "  ")
+}
@@ -0,0 +1,293 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/eugener/clix/internal/complete"
+	"github.com/eugener/clix/internal/configfile"
+	"github.com/eugener/clix/internal/posix"
+	"github.com/eugener/clix/internal/shell"
+)
+
+// shellCommandName is the name of the built-in shell command
+const shellCommandName = "shell"
+
+// isShellCommand checks if the argument invokes the built-in shell command.
+// A registered command with the same name always takes precedence.
+func (app *Application) isShellCommand(arg string) bool {
+	if !app.config.ShellCommand || arg != shellCommandName {
+		return false
+	}
+	_, registered := app.registry.GetCommand(shellCommandName)
+	return !registered
+}
+
+// handleShellCommand runs the shell within the session of the shell command, so
+// global flags given before it apply to every command of the shell
+func (app *Application) handleShellCommand(ctx context.Context, args []string) int {
+	switch {
+	case len(args) == 1 && app.isHelpRequest(args[0]):
+		fmt.Printf("Run commands interactively\n\nUsage:\n  %s shell\n", app.config.Name)
+		return 0
+	case len(args) > 0:
+		err := fmt.Errorf("usage: %s shell", app.config.Name)
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	
+	return app.runShell(ctx)
+}
+
+// RunREPL reads commands from standard input and runs them one by one until exit
+// or the end of the input, and returns the exit code of the last command. Services
+// and hooks span the whole shell; Ctrl-C cancels only the running command.
+func (app *Application) RunREPL(ctx context.Context) int {
+	return app.session(ctx, nil, app.runShell)
+}
+
+// runShell runs the read-run loop of the shell
+func (app *Application) runShell(ctx context.Context) int {
+	if app.inShell {
+		err := fmt.Errorf("already running the shell")
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	app.inShell = true
+	defer func() {
+		app.inShell = false
+		app.shellConfig = nil
+	}()
+	
+	// Standard input holds the commands of the shell, not secrets
+	app.executor.SetSecretStdin(nil)
+	defer app.executor.SetSecretStdin(os.Stdin)
+	
+	editor := shell.NewEditor(os.Stdin, os.Stdout)
+	generator := app.GetCompletionGenerator()
+	editor.Complete = func(line string) []string {
+		return app.completeShellLine(generator, line)
+	}
+	if path := app.shellHistoryPath(); path != "" {
+		if err := editor.LoadHistory(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	if editor.IsTerminal() {
+		fmt.Printf("%s shell, type help for commands and exit to quit\n", app.config.Name)
+	}
+	
+	globals := app.shellGlobalFlags()
+	
+	// Ctrl-C cancels the running command instead of ending the shell
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	
+	prompt := app.config.Name + "> "
+	code := 0
+	for ctx.Err() == nil {
+		line, err := editor.ReadLine(prompt)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to read command: %w", err)
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+			return app.config.ErrorHandler(err)
+		}
+		
+		if err := editor.AddHistory(strings.TrimSpace(line)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		
+		args, err := posix.SplitArgs(line)
+		if err != nil {
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+			code = app.config.ErrorHandler(err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		
+		if app.isShellExit(args[0]) {
+			return app.shellExitCode(args[1:], code)
+		}
+		code = app.runShellLine(ctx, interrupts, append(slices.Clone(globals), args...))
+	}
+	
+	return code
+}
+
+// runShellLine runs the command of one shell line with a context cancelled by Ctrl-C
+func (app *Application) runShellLine(ctx context.Context, interrupts chan os.Signal, args []string) int {
+	// Ctrl-C pressed while no command was running is dropped
+	select {
+	case <-interrupts:
+	default:
+	}
+	
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	
+	if app.config.ArgsFiles {
+		expanded, err := app.expandArgsFiles(args)
+		if err != nil {
+			fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+			return app.config.ErrorHandler(err)
+		}
+		args = expanded
+	}
+	
+	args, err := app.extractGlobalFlags(args)
+	if err != nil {
+		fmt.Fprint(os.Stderr, app.errorFormat.FormatError(err, nil))
+		return app.config.ErrorHandler(err)
+	}
+	if len(args) == 0 {
+		return 0
+	}
+	
+	code := app.dispatch(ctx, args, nil)
+	
+	// Config commands may change the files, which are loaded again for the next line
+	if app.isConfigCommand(args[0]) {
+		app.shellConfig = nil
+	}
+	return code
+}
+
+// shellConfig holds the config layers loaded once for the lines of the shell, along
+// with the profile and config file they were loaded for
+type shellConfig struct {
+	profile      string
+	configPath   string
+	layers       []configLayer
+	profileChain []string
+}
+
+// sessionConfigLayers returns the config layers for a command run. The shell loads
+// them once, and again only when a line selects another profile or config file.
+func (app *Application) sessionConfigLayers(loader *configfile.Loader) ([]configLayer, error) {
+	if !app.inShell {
+		return app.loadConfigLayers(loader)
+	}
+	
+	if cached := app.shellConfig; cached != nil && cached.profile == app.profile && cached.configPath == app.configPath {
+		app.profileChain = cached.profileChain
+		return cached.layers, nil
+	}
+	
+	layers, err := app.loadConfigLayers(loader)
+	if err != nil {
+		return nil, err
+	}
+	app.shellConfig = &shellConfig{
+		profile:      app.profile,
+		configPath:   app.configPath,
+		layers:       layers,
+		profileChain: app.profileChain,
+	}
+	return layers, nil
+}
+
+// shellGlobalFlags returns the global flags set for the shell as arguments, so they
+// apply to every line unless the line sets them again
+func (app *Application) shellGlobalFlags() []string {
+	var args []string
+	for _, flag := range app.globalFlags {
+		if flag.Get == nil {
+			continue
+		}
+		if value := flag.Get(app); value != "" {
+			args = append(args, "--"+flag.Name+"="+value)
+		}
+	}
+	return args
+}
+
+// isShellExit reports whether a shell line leaves the shell. Registered commands
+// named exit or quit take precedence.
+func (app *Application) isShellExit(name string) bool {
+	if name != "exit" && name != "quit" {
+		return false
+	}
+	_, registered := app.registry.GetCommand(name)
+	return !registered
+}
+
+// shellExitCode returns the code given to exit, or the code of the last command
+func (app *Application) shellExitCode(args []string, last int) int {
+	if len(args) == 0 {
+		return last
+	}
+	code, err := strconv.Atoi(args[0])
+	if err != nil || len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Usage: exit [code]\n")
+		return 1
+	}
+	return code
+}
+
+// shellHistoryPath returns the history file of the shell, or "" without a home directory
+func (app *Application) shellHistoryPath() string {
+	if app.config.ShellHistory != "" {
+		return app.config.ShellHistory
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "."+app.config.Name+"_history")
+}
+
+// completeShellLine returns the completions of the last word of a shell line
+func (app *Application) completeShellLine(generator *complete.Generator, line string) []string {
+	args, err := posix.SplitArgs(line)
+	if err != nil {
+		return nil
+	}
+	if len(args) == 0 || strings.HasSuffix(line, " ") {
+		args = append(args, "")
+	}
+	word := args[len(args)-1]
+	
+	var values []string
+	if len(args) == 1 {
+		for _, name := range []string{"help", "exit", configCommandName, schemaCommandName} {
+			if strings.HasPrefix(name, word) && (name == "exit" || app.isCommandName(name)) {
+				values = append(values, name)
+			}
+		}
+	}
+	
+	items, err := generator.Complete(args, len(line))
+	if err != nil {
+		return values
+	}
+	for _, item := range items {
+		// Placeholders such as <filename> describe a value and cannot be completed
+		if strings.HasPrefix(item.Value, word) && !strings.HasPrefix(item.Value, "<") && !slices.Contains(values, item.Value) {
+			values = append(values, item.Value)
+		}
+	}
+	
+	slices.Sort(values)
+	return values
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eugener/clix/config"
+	"github.com/eugener/clix/core"
+)
+
+// withStdin makes input the standard input of the shell while fn runs
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+	
+	path := writeFile(t, t.TempDir(), "stdin", input)
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer file.Close()
+	
+	stdin := os.Stdin
+	os.Stdin = file
+	defer func() { os.Stdin = stdin }()
+	fn()
+}
+
+func TestShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		args     []string
+		input    string
+		wantCode int
+		wantRuns []string // names deploy ran with
+	}{
+		{name: "runs each line", input: "deploy --name a\n\n  \ndeploy -n 'b c'\n", wantRuns: []string{"a", "b c"}},
+		{name: "last line without newline", input: "deploy -n a", wantRuns: []string{"a"}},
+		{name: "exit with a code", input: "deploy -n a\nexit 4\ndeploy -n b\n", wantCode: 4, wantRuns: []string{"a"}},
+		{name: "exit with the last code", input: "deploy --replicas x\nexit\n", wantCode: 1},
+		{name: "invalid exit code", input: "exit four\n", wantCode: 1},
+		{name: "quit", input: "quit\ndeploy\n", wantRuns: nil},
+		{name: "end of input returns the last code", input: "deploy --replicas x\n", wantCode: 1},
+		{name: "failed line does not end the shell", input: "deploy --name 'a\ndeploy -n b\n", wantRuns: []string{"b"}},
+		{name: "unknown command", input: "deplyo\ndeploy -n a\n", wantRuns: []string{"a"}},
+		{name: "no nested shell", input: "shell\n", wantCode: 1},
+		{name: "global flags span the shell", file: "profiles:\n  prod:\n    name: prod\n  dev:\n    name: dev\n", args: []string{"--profile", "prod"}, input: "deploy\n--profile dev deploy\ndeploy\n", wantRuns: []string{"prod", "dev", "prod"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.file != "" {
+				writeFile(t, dir, "tool.yaml", tt.file)
+			}
+			
+			var runs []deployConfig
+			application := newTestApp(t, dir, &runs,
+				config.WithShellCommand(true),
+				config.WithShellHistory(filepath.Join(dir, "history")),
+			)
+			
+			var code int
+			withStdin(t, tt.input, func() {
+				code = application.Run(context.Background(), append(tt.args, "shell"))
+			})
+			if code != tt.wantCode {
+				t.Errorf("shell exited with %d, want %d", code, tt.wantCode)
+			}
+			
+			var names []string
+			for _, run := range runs {
+				names = append(names, run.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantRuns) {
+				t.Errorf("deploy ran with names %q, want %q", names, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRunREPL(t *testing.T) {
+	dir := t.TempDir()
+	history := filepath.Join(dir, "history")
+	
+	var events []string
+	record := func(name string) func(*core.ExecutionContext) error {
+		return func(*core.ExecutionContext) error {
+			events = append(events, name)
+			return nil
+		}
+	}
+	
+	var runs []deployConfig
+	application := newTestApp(t, dir, &runs,
+		config.WithShellHistory(history),
+		config.WithBeforeAll(record("before all")),
+		config.WithAfterAll(record("after all")),
+		config.WithBeforeEach(record("before each")),
+	)
+	
+	var code int
+	withStdin(t, "deploy -n a\ndeploy -n a\nexit 2\n", func() {
+		code = application.RunREPL(context.Background())
+	})
+	if code != 2 {
+		t.Errorf("RunREPL() = %d, want 2", code)
+	}
+	
+	// The session hooks run once around all lines of the shell
+	if want := []string{"before all", "before each", "before each", "after all"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+	
+	data, err := os.ReadFile(history)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "deploy -n a\nexit 2\n"; string(data) != want {
+		t.Errorf("history = %q, want %q", data, want)
+	}
+}
+
+func TestShellLoadsConfigOnce(t *testing.T) {
+	dir := t.TempDir()
+	profiles := "profiles:\n  dev:\n    region: dev\n"
+	path := writeFile(t, dir, "tool.yaml", "name: a\n"+profiles)
+	
+	var runs []deployConfig
+	application := newTestApp(t, dir, &runs, config.WithConfigCommand(true))
+	err := application.Register(core.NewCommand("rewrite", "Rewrite the config file", func(ctx context.Context, c struct{}) error {
+		return os.WriteFile(path, []byte("name: b\n"+profiles), 0644)
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	input := "deploy\nrewrite\ndeploy\n--profile dev deploy\nconfig set name c\ndeploy\n"
+	withStdin(t, input, func() {
+		if code := application.RunREPL(context.Background()); code != 0 {
+			t.Errorf("RunREPL() = %d, want 0", code)
+		}
+	})
+	
+	// Files are read again only for another profile or after a config command
+	var names []string
+	for _, run := range runs {
+		names = append(names, run.Name)
+	}
+	if want := []string{"a", "a", "b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("deploy ran with names %q, want %q", names, want)
+	}
+}
+
+func TestCompleteShellLine(t *testing.T) {
+	var runs []deployConfig
+	application := newTestApp(t, t.TempDir(), &runs, config.WithShellCommand(true), config.WithConfigCommand(true))
+	generator := application.GetCompletionGenerator()
+	
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "", want: []string{"config", "deploy", "exit", "help"}},
+		{line: "de", want: []string{"deploy"}},
+		{line: "ex", want: []string{"exit"}},
+		{line: "deploy --rep", want: []string{"--replicas"}},
+		{line: "deploy --database.", want: []string{"--database.host", "--database.port"}},
+		{line: "deploy 'unterminated", want: nil},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := application.completeShellLine(generator, tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("completeShellLine(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
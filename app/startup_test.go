@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eugener/clix/config"
+	"github.com/eugener/clix/core"
+)
+
+type startupDatabase struct {
+	Host string `posix:",host,Database host"`
+	Port int    `posix:",port,Database port"`
+}
+
+type startupConfig struct {
+	Name     string          `posix:"n,name,Name"`
+	Region   string          `posix:",region,Region"`
+	Replicas int             `posix:",replicas,Replicas,default=1"`
+	Token    string          `posix:"t,token,API token,secret"`
+	Database startupDatabase `posix:",database,Database settings"`
+}
+
+// BenchmarkStartup runs one of 100 commands, registered as built commands or lazily
+func BenchmarkStartup(b *testing.B) {
+	noop := func(ctx context.Context, c startupConfig) error { return nil }
+	
+	benchmarks := []struct {
+		name     string
+		register func(application *Application, name string) error
+	}{
+		{name: "eager", register: func(application *Application, name string) error {
+			return application.Register(core.NewCommand(name, "Deploy", noop))
+		}},
+		{name: "lazy", register: func(application *Application, name string) error {
+			return RegisterLazy(application, name, "Deploy", func() core.Command[startupConfig] {
+				return core.NewCommand(name, "Deploy", noop)
+			})
+		}},
+	}
+	
+	dir := b.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tool.yaml"), []byte("region: eu\ncommands:\n  cmd42:\n    replicas: 2\n"), 0644); err != nil {
+		b.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				application := NewApplicationWithOptions(
+					config.WithName("tool"),
+					config.WithConfigFile("tool"),
+					config.WithConfigPaths([]string{dir}),
+					config.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+					config.WithArgsFiles(true),
+					config.WithAbbreviations(true),
+				)
+				for i := range 100 {
+					if err := bm.register(application, fmt.Sprintf("cmd%d", i)); err != nil {
+						b.Fatalf("register() error = %v", err)
+					}
+				}
+				
+				if code := application.Run(context.Background(), []string{"cmd42", "--name", "web"}); code != 0 {
+					b.Fatalf("Run() = %d, want 0", code)
+				}
+			}
+		})
+	}
+}
@@ -111,6 +111,7 @@ type App struct {
 	author      string
 	options     []config.Option
 	commands    []any
+	services    *core.Services
 }
 
 // New creates a new CLI application with fluent API
@@ -210,6 +211,85 @@ func (a *App) ConfigFile(filename string) *App {
 	return a
 }
 
+// StrictConfig rejects unknown config file sections and keys instead of warning about them
+func (a *App) StrictConfig() *App {
+	a.options = append(a.options, config.WithStrictConfig(true))
+	return a
+}
+
+// Profile selects the default configuration profile
+func (a *App) Profile(name string) *App {
+	a.options = append(a.options, config.WithProfile(name))
+	return a
+}
+
+// ConfigCommand enables the built-in "config" command
+func (a *App) ConfigCommand() *App {
+	a.options = append(a.options, config.WithConfigCommand(true))
+	return a
+}
+
+// SchemaCommand enables the built-in "schema" command
+func (a *App) SchemaCommand() *App {
+	a.options = append(a.options, config.WithSchemaCommand(true))
+	return a
+}
+
+// Shell enables the built-in "shell" command running commands read line by line
+func (a *App) Shell() *App {
+	a.options = append(a.options, config.WithShellCommand(true))
+	return a
+}
+
+// ShellHistory sets the file the shell keeps its history in
+func (a *App) ShellHistory(path string) *App {
+	a.options = append(a.options, config.WithShellHistory(path))
+	return a
+}
+
+// ReloadConfig reloads changed config files for reloadable commands, checking every interval
+func (a *App) ReloadConfig(interval time.Duration) *App {
+	a.options = append(a.options, config.WithConfigReload(interval))
+	return a
+}
+
+// SecretProvider registers a provider for secret flag values written as scheme:ref
+func (a *App) SecretProvider(scheme string, provider core.SecretProvider) *App {
+	a.options = append(a.options, config.WithSecretProvider(scheme, provider))
+	return a
+}
+
+// ArgsFiles enables or disables expanding @file arguments, disabled by default
+func (a *App) ArgsFiles(enabled bool) *App {
+	a.options = append(a.options, config.WithArgsFiles(enabled))
+	return a
+}
+
+// Abbreviations accepts unique prefixes of long flags and command names
+func (a *App) Abbreviations() *App {
+	a.options = append(a.options, config.WithAbbreviations(true))
+	return a
+}
+
+// LenientFlags ignores unknown command flags instead of rejecting them
+func (a *App) LenientFlags() *App {
+	a.options = append(a.options, config.WithLenientFlags(true))
+	return a
+}
+
+// Plugins runs executables named <name>-<command> for unknown commands, searching the
+// given directories before PATH
+func (a *App) Plugins(dirs ...string) *App {
+	a.options = append(a.options, config.WithPlugins(dirs...))
+	return a
+}
+
+// Dialect sets the flag syntax of commands, e.g. core.DialectGo or core.DialectSlash
+func (a *App) Dialect(dialect core.Dialect) *App {
+	a.options = append(a.options, config.WithDialect(dialect))
+	return a
+}
+
 // Timeout sets the default command timeout
 func (a *App) Timeout(timeout time.Duration) *App {
 	a.options = append(a.options, config.WithDefaultTimeout(timeout))
@@ -228,6 +308,53 @@ func (a *App) AddCommand(command any) *App {
 	return a
 }
 
+// registration adds a typed command to the application when it is built
+type registration func(*app.Application) error
+
+// Register adds a command with its config type known at compile time, e.g.
+// cli.Register[DeployConfig](app, &DeployCommand{})
+func Register[T any](a *App, cmd core.Command[T]) *App {
+	a.commands = append(a.commands, registration(func(application *app.Application) error {
+		return app.Register(application, cmd)
+	}))
+	return a
+}
+
+// RegisterLazy adds a command by name and description, built by the factory only
+// when it runs
+func RegisterLazy[T any](a *App, name, description string, factory func() core.Command[T]) *App {
+	a.commands = append(a.commands, registration(func(application *app.Application) error {
+		return app.RegisterLazy(application, name, description, factory)
+	}))
+	return a
+}
+
+// Provider adds the commands, global flags, middleware and help topics of a provider
+func (a *App) Provider(provider core.CommandProvider) *App {
+	a.commands = append(a.commands, registration(func(application *app.Application) error {
+		return application.AddProvider(provider)
+	}))
+	return a
+}
+
+// NamespacedProvider adds a provider with its commands named namespace:command
+func (a *App) NamespacedProvider(namespace string, provider core.CommandProvider) *App {
+	a.commands = append(a.commands, registration(func(application *app.Application) error {
+		return application.AddNamespacedProvider(namespace, provider)
+	}))
+	return a
+}
+
+// Provide registers a lazy provider of a service shared by commands
+func Provide[T any](a *App, provider func(ctx context.Context) (T, error)) *App {
+	if a.services == nil {
+		a.services = core.NewServices()
+		a.options = append(a.options, config.WithServices(a.services))
+	}
+	core.Provide(a.services, provider)
+	return a
+}
+
 // BeforeAll sets a hook to run before all commands
 func (a *App) BeforeAll(hook func(*core.ExecutionContext) error) *App {
 	a.options = append(a.options, config.WithBeforeAll(hook))
@@ -276,7 +403,13 @@ func (a *App) Build() *app.Application {
 	
 	// Register all commands
 	for _, cmd := range a.commands {
-		if err := application.Register(cmd); err != nil {
+		var err error
+		if typed, ok := cmd.(registration); ok {
+			err = typed(application)
+		} else {
+			err = application.Register(cmd)
+		}
+		if err != nil {
 			panic("Failed to register command: " + err.Error())
 		}
 	}
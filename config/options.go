@@ -25,6 +25,9 @@ type CLIConfig struct {
 	// Middleware
 	Middleware []core.Middleware
 	
+	// Services shared by commands, built lazily and closed after AfterAll
+	Services *core.Services
+	
 	// Global flags
 	GlobalFlags map[string]interface{}
 	
@@ -32,6 +35,26 @@ type CLIConfig struct {
 	ConfigFile     string
 	ConfigPaths    []string
 	AutoLoadConfig bool
+	StrictConfig   bool   // Treat unknown config sections and keys as errors instead of warnings
+	Profile        string // Default configuration profile, overridden by --profile
+	ConfigCommand  bool   // Enable the built-in "config" command
+	SchemaCommand  bool   // Enable the built-in "schema" command
+	ShellCommand   bool   // Enable the built-in "shell" command
+	ShellHistory   string // History file of the shell, ~/.<name>_history when empty
+	ConfigReload   time.Duration // Poll interval for reloading config files of reloadable commands, 0 disables
+	
+	// External plugin commands: executables named <name>-<command> in PluginDirs or on PATH
+	Plugins    bool
+	PluginDirs []string
+	
+	// Secret providers for secret flag values written as scheme:ref, by scheme
+	SecretProviders map[string]core.SecretProvider
+	
+	// Argument settings
+	ArgsFiles     bool         // Expand @file arguments with the arguments read from the file
+	Abbreviations bool         // Accept unique prefixes of long flags and command names
+	LenientFlags  bool         // Ignore unknown command flags instead of rejecting them
+	Dialect       core.Dialect // Flag syntax of commands that do not set their own, POSIX when nil
 	
 	// Interactive mode settings
 	InteractiveMode bool
@@ -100,6 +123,22 @@ func WithDefaultTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithPlugins runs executables named <name>-<command> for commands that are not
+// registered, searching the given directories before PATH, like git and kubectl plugins
+func WithPlugins(dirs ...string) Option {
+	return func(c *CLIConfig) {
+		c.Plugins = true
+		c.PluginDirs = append(c.PluginDirs, dirs...)
+	}
+}
+
+// WithServices sets the container of services shared by commands
+func WithServices(services *core.Services) Option {
+	return func(c *CLIConfig) {
+		c.Services = services
+	}
+}
+
 // WithMiddleware adds middleware to the CLI
 func WithMiddleware(middleware ...core.Middleware) Option {
 	return func(c *CLIConfig) {
@@ -222,6 +261,100 @@ func WithAutoLoadConfig(enabled bool) Option {
 	}
 }
 
+// WithStrictConfig makes unknown config file sections and keys errors instead of warnings
+func WithStrictConfig(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.StrictConfig = enabled
+	}
+}
+
+// WithProfile selects the default configuration profile
+func WithProfile(profile string) Option {
+	return func(c *CLIConfig) {
+		c.Profile = profile
+	}
+}
+
+// WithConfigCommand enables the built-in "config" command for inspecting configuration
+func WithConfigCommand(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.ConfigCommand = enabled
+	}
+}
+
+// WithSchemaCommand enables the built-in "schema" command printing the JSON Schema of the config file
+func WithSchemaCommand(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.SchemaCommand = enabled
+	}
+}
+
+// WithShellCommand enables the built-in "shell" command running commands read line by line
+func WithShellCommand(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.ShellCommand = enabled
+	}
+}
+
+// WithShellHistory sets the file the shell keeps its history in
+func WithShellHistory(path string) Option {
+	return func(c *CLIConfig) {
+		c.ShellHistory = path
+	}
+}
+
+// WithConfigReload enables reloading changed config files for commands implementing
+// core.Reloadable, checking the files every interval
+func WithConfigReload(interval time.Duration) Option {
+	return func(c *CLIConfig) {
+		c.ConfigReload = interval
+	}
+}
+
+// WithSecretProvider registers a provider resolving secret flag values written as scheme:ref.
+// The file scheme is built in; core.ExecSecretProvider runs commands once registered as exec.
+func WithSecretProvider(scheme string, provider core.SecretProvider) Option {
+	return func(c *CLIConfig) {
+		if c.SecretProviders == nil {
+			c.SecretProviders = make(map[string]core.SecretProvider)
+		}
+		c.SecretProviders[scheme] = provider
+	}
+}
+
+// WithArgsFiles enables or disables expanding @file arguments with the arguments read
+// from the file. It is disabled by default, since positional arguments starting with @
+// would be read as files.
+func WithArgsFiles(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.ArgsFiles = enabled
+	}
+}
+
+// WithAbbreviations enables GNU-style unique-prefix matching of long flags and command
+// names, e.g. --verb for --verbose and dep for deploy. Ambiguous prefixes are errors.
+func WithAbbreviations(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.Abbreviations = enabled
+	}
+}
+
+// WithLenientFlags ignores unknown command flags instead of rejecting them, for forward
+// compatibility with arguments meant for newer versions
+func WithLenientFlags(enabled bool) Option {
+	return func(c *CLIConfig) {
+		c.LenientFlags = enabled
+	}
+}
+
+// WithDialect sets the flag syntax of commands, e.g. core.DialectGo for -name value or
+// core.DialectSlash for /name:value; commands can override it with WithDialect
+func WithDialect(dialect core.Dialect) Option {
+	return func(c *CLIConfig) {
+		c.Dialect = dialect
+	}
+}
+
 // WithInteractiveMode enables or disables interactive prompting for missing required fields
 func WithInteractiveMode(enabled bool) Option {
 	return func(c *CLIConfig) {
@@ -242,6 +375,7 @@ func DefaultConfig() *CLIConfig {
 		ConfigFile:      "",
 		ConfigPaths:     []string{},
 		AutoLoadConfig:  false,
+		ArgsFiles:       false,
 		InteractiveMode: false,
 		ErrorHandler: func(err error) int {
 			if err != nil {
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"reflect"
@@ -20,6 +22,11 @@ type ExecutionContext struct {
 	CommandName string
 	Args       []string
 	Metadata   map[string]any
+	Profile    string // Active configuration profile, empty when none is selected
+	Config     any    // Validated command configuration, set before the command runs
+	
+	// resolvedArgs are the arguments with secret values resolved, never logged
+	resolvedArgs []string
 }
 
 // NewExecutionContext creates a new execution context
@@ -57,12 +64,23 @@ type Middleware func(next ExecuteFunc) ExecuteFunc
 // ExecuteFunc represents a command execution function
 type ExecuteFunc func(ctx *ExecutionContext) error
 
+// ReloadWatcher starts watching for configuration changes while a reloadable
+// command runs, and returns a function that stops watching
+type ReloadWatcher func(execCtx *ExecutionContext) (stop func())
+
 // Executor manages command execution with middleware support
 type Executor struct {
-	registry   *Registry
-	binder     *bind.Binder
-	middleware []Middleware
-	logger     *slog.Logger
+	registry      *Registry
+	binder        *bind.Binder
+	middleware    []Middleware
+	logger        *slog.Logger
+	profile       string
+	reloadWatcher ReloadWatcher
+	secrets       *secretResolver
+	abbreviations bool
+	lenientFlags  bool
+	dialect       Dialect
+	services      *Services
 }
 
 // NewExecutor creates a new command executor
@@ -72,6 +90,8 @@ func NewExecutor(registry *Registry) *Executor {
 		binder:     bind.NewBinder("posix"),
 		middleware: make([]Middleware, 0),
 		logger:     slog.Default(),
+		secrets:    newSecretResolver(),
+		dialect:    DialectPOSIX,
 	}
 }
 
@@ -85,6 +105,66 @@ func (e *Executor) SetLogger(logger *slog.Logger) {
 	e.logger = logger
 }
 
+// SetProfile sets the active configuration profile reported to commands and logs
+func (e *Executor) SetProfile(profile string) {
+	e.profile = profile
+}
+
+// SetReloadWatcher sets the watcher started for commands implementing Reloadable
+func (e *Executor) SetReloadWatcher(watcher ReloadWatcher) {
+	e.reloadWatcher = watcher
+}
+
+// SetAbbreviations enables or disables unique-prefix matching of long flags
+func (e *Executor) SetAbbreviations(enabled bool) {
+	e.abbreviations = enabled
+	e.secrets.abbreviations = enabled
+}
+
+// SetLenientFlags makes commands ignore unknown flags instead of rejecting them
+func (e *Executor) SetLenientFlags(enabled bool) {
+	e.lenientFlags = enabled
+}
+
+// SetDialect sets the flag syntax of commands that do not choose their own
+func (e *Executor) SetDialect(dialect Dialect) {
+	e.dialect = dialect
+}
+
+// Dialect returns the flag syntax of a command
+func (e *Executor) Dialect(commandName string) Dialect {
+	descriptor, exists := e.registry.GetCommand(commandName)
+	if !exists {
+		return e.dialect
+	}
+	return e.commandDialect(descriptor)
+}
+
+// commandDialect returns the flag syntax of a command, defaulting to the executor's
+func (e *Executor) commandDialect(descriptor *commandDescriptor) Dialect {
+	if dialect := descriptor.Dialect(); dialect != nil {
+		return dialect
+	}
+	return e.dialect
+}
+
+// SetServices sets the service container commands get their services from
+func (e *Executor) SetServices(services *Services) {
+	e.services = services
+}
+
+// SetSecretProvider registers a provider for secret flag values written as scheme:ref
+func (e *Executor) SetSecretProvider(scheme string, provider SecretProvider) {
+	e.secrets.providers[scheme] = provider
+}
+
+// SetSecretStdin sets the reader secret flag values given as - are read from, standard
+// input by default. A nil reader rejects them, e.g. while a shell reads its commands
+// from standard input.
+func (e *Executor) SetSecretStdin(reader io.Reader) {
+	e.secrets.stdin = reader
+}
+
 // Execute runs a command with the given context and arguments
 func (e *Executor) Execute(ctx context.Context, commandName string, args []string) error {
 	return e.ExecuteWithConfig(ctx, commandName, args, nil)
@@ -92,18 +172,69 @@ func (e *Executor) Execute(ctx context.Context, commandName string, args []strin
 
 // ExecuteWithConfig runs a command with the given context, arguments, and base configuration
 func (e *Executor) ExecuteWithConfig(ctx context.Context, commandName string, args []string, baseConfig any) error {
-	// Create execution context
-	execCtx := NewExecutionContext(ctx, commandName, args).WithLogger(e.logger)
+	prepared, err := e.PrepareArgs(ctx, commandName, args)
+	if err != nil {
+		return err
+	}
+	return e.ExecutePrepared(ctx, prepared, baseConfig)
+}
+
+// PreparedArgs are the arguments of a command with their secret values resolved.
+// Redacted is the form safe to log and to show to hooks.
+type PreparedArgs struct {
+	CommandName string
+	Redacted    []string
+	resolved    []string
+}
+
+// PrepareArgs normalizes the arguments of a command and resolves its secret values
+// once, so secrets read from standard input or a provider are not read again
+func (e *Executor) PrepareArgs(ctx context.Context, commandName string, args []string) (*PreparedArgs, error) {
+	descriptor, exists := e.registry.GetCommand(commandName)
+	if !exists {
+		return nil, fmt.Errorf("command not found: %s", commandName)
+	}
+	
+	// Flags are resolved in POSIX form
+	args = e.normalizeArgs(descriptor, args)
+	
+	// Secret providers get services through the context like commands
+	if e.services != nil {
+		ctx = WithServices(ctx, e.services)
+	}
+	
+	// Secret values are resolved once and zeroed in the arguments seen by middleware
+	resolved, redacted, err := e.secrets.resolve(ctx, descriptor.GetConfigType(), args, e.flagsEnd(descriptor, args))
+	if err != nil {
+		return nil, err
+	}
 	
-	// Get command descriptor
+	return &PreparedArgs{CommandName: commandName, Redacted: redacted, resolved: resolved}, nil
+}
+
+// ExecutePrepared runs a command with arguments from PrepareArgs and a base configuration
+func (e *Executor) ExecutePrepared(ctx context.Context, prepared *PreparedArgs, baseConfig any) error {
+	commandName := prepared.CommandName
 	descriptor, exists := e.registry.GetCommand(commandName)
 	if !exists {
 		return fmt.Errorf("command not found: %s", commandName)
 	}
 	
+	// Commands get their services through the context
+	if e.services != nil {
+		ctx = WithServices(ctx, e.services)
+	}
+	
+	// Create execution context
+	execCtx := NewExecutionContext(ctx, commandName, prepared.Redacted).WithLogger(e.logger)
+	if e.profile != "" {
+		execCtx.Profile = e.profile
+		execCtx.Logger = execCtx.Logger.With("profile", e.profile)
+	}
+	
 	// Create the base execution function
 	baseFunc := func(execCtx *ExecutionContext) error {
-		return e.executeCommandWithConfig(execCtx, descriptor, args, baseConfig)
+		return e.executeCommandWithConfig(execCtx, descriptor, prepared.resolved, baseConfig)
 	}
 	
 	// Build middleware chain
@@ -120,38 +251,188 @@ func (e *Executor) executeCommand(execCtx *ExecutionContext, descriptor *command
 
 // executeCommandWithConfig executes the actual command with base configuration
 func (e *Executor) executeCommandWithConfig(execCtx *ExecutionContext, descriptor *commandDescriptor, args []string, baseConfig any) error {
+	config, err := e.buildConfig(descriptor, args, baseConfig)
+	if err != nil {
+		return err
+	}
+	
+	execCtx.Config = config
+	execCtx.resolvedArgs = args
+	
+	// Log execution start
+	execCtx.Logger.Info("executing command",
+		"command", execCtx.CommandName,
+		"args", execCtx.Args,
+		"duration_so_far", execCtx.Duration(),
+	)
+	
+	// Watch for configuration changes while a reloadable command runs
+	if e.reloadWatcher != nil && descriptor.SupportsReload() {
+		stop := e.reloadWatcher(execCtx)
+		defer stop()
+	}
+	
+	// Execute the command
+	return e.runCommand(execCtx, descriptor, config)
+}
+
+// runCommand runs a command between its lifecycle hooks. Cleanup is deferred in the
+// goroutine running the command, so it runs after PostRun, and before a panic reaches
+// the recovery middleware. After a timeout it runs once Run returns.
+func (e *Executor) runCommand(execCtx *ExecutionContext, descriptor *commandDescriptor, config any) error {
+	descriptor.resolve()
+	if descriptor.cleanup != nil {
+		defer descriptor.cleanup()
+	}
+	
+	if err := ServicesFrom(execCtx.Context).Inject(execCtx.Context, config); err != nil {
+		return fmt.Errorf("failed to inject services: %w", err)
+	}
+	
+	// Parents prepare for their children, outermost first
+	for _, parent := range e.registry.parents(execCtx.CommandName) {
+		if err := parent.childPreRun(execCtx.Context, execCtx.CommandName); err != nil {
+			return fmt.Errorf("pre-run of %s failed: %w", parent.name, err)
+		}
+	}
+	
+	if descriptor.preRun != nil {
+		if err := descriptor.preRun(execCtx.Context, config); err != nil {
+			return fmt.Errorf("pre-run failed: %w", err)
+		}
+	}
+	
+	err := e.registry.Execute(execCtx.Context, execCtx.CommandName, config)
+	
+	if descriptor.postRun != nil {
+		err = descriptor.postRun(execCtx.Context, config, err)
+	}
+	return err
+}
+
+// BuildConfig builds and validates the configuration of a command from a base
+// configuration and arguments, without running the command
+func (e *Executor) BuildConfig(commandName string, args []string, baseConfig any) (any, error) {
+	descriptor, exists := e.registry.GetCommand(commandName)
+	if !exists {
+		return nil, fmt.Errorf("command not found: %s", commandName)
+	}
+	
+	args = e.normalizeArgs(descriptor, args)
+	resolved, _, err := e.secrets.resolve(context.Background(), descriptor.GetConfigType(), args, e.flagsEnd(descriptor, args))
+	if err != nil {
+		return nil, err
+	}
+	
+	return e.buildConfig(descriptor, resolved, baseConfig)
+}
+
+// RebuildConfig builds the configuration of a running command again from a new base
+// configuration, reusing its arguments with their already resolved secrets, and injects
+// the services of the running command
+func (e *Executor) RebuildConfig(execCtx *ExecutionContext, baseConfig any) (any, error) {
+	descriptor, exists := e.registry.GetCommand(execCtx.CommandName)
+	if !exists {
+		return nil, fmt.Errorf("command not found: %s", execCtx.CommandName)
+	}
+	
+	config, err := e.buildConfig(descriptor, execCtx.resolvedArgs, baseConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := ServicesFrom(execCtx.Context).Inject(execCtx.Context, config); err != nil {
+		return nil, fmt.Errorf("failed to inject services: %w", err)
+	}
+	return config, nil
+}
+
+// Reload delivers a new configuration to a running command
+func (e *Executor) Reload(ctx context.Context, commandName string, config any) error {
+	return e.registry.Reload(ctx, commandName, config)
+}
+
+// buildConfig creates the command configuration: computed defaults, base configuration,
+// then environment variables and arguments, then normalization and validation
+func (e *Executor) buildConfig(descriptor *commandDescriptor, args []string, baseConfig any) (any, error) {
 	// Create config instance
 	configType := descriptor.GetConfigType()
 	configPtr := reflect.New(configType)
 	config := configPtr.Interface()
 	
+	// Tag defaults come first, so every later source can override them
+	if err := e.binder.ApplyDefaults(config); err != nil {
+		return nil, err
+	}
+	if defaulter, ok := config.(Defaulter); ok {
+		defaulter.Defaults()
+	}
+	
 	// Apply base configuration if provided (from config file)
 	if baseConfig != nil {
 		if err := e.mergeConfigs(config, baseConfig); err != nil {
-			return fmt.Errorf("failed to apply base configuration: %w", err)
+			return nil, fmt.Errorf("failed to apply base configuration: %w", err)
 		}
 	}
 	
 	// Parse arguments using enhanced parser (CLI args override config file)
-	parser := NewEnhancedParser(e.binder)
-	if err := parser.Parse(args, config); err != nil {
-		return fmt.Errorf("failed to parse arguments: %w", err)
+	if err := e.newParser(descriptor).Parse(args, config); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	
+	if normalizer, ok := config.(Normalizer); ok {
+		normalizer.Normalize()
 	}
 	
 	// Validate configuration
 	if err := e.validateConfig(config); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if validator, ok := config.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, fmt.Errorf("validation failed: %w", err)
+		}
 	}
 	
-	// Log execution start
-	execCtx.Logger.Info("executing command",
-		"command", execCtx.CommandName,
-		"args", execCtx.Args,
-		"duration_so_far", execCtx.Duration(),
-	)
-	
-	// Execute the command
-	return e.registry.Execute(execCtx.Context, execCtx.CommandName, config)
+	return config, nil
+}
+
+// FlagsEnd returns the index of the first argument of a command that is not parsed for
+// flags, e.g. the arguments a POSIX ordered command passes through to a wrapped tool
+func (e *Executor) FlagsEnd(commandName string, args []string) int {
+	descriptor, exists := e.registry.GetCommand(commandName)
+	if !exists {
+		return len(args)
+	}
+	return e.flagsEnd(descriptor, args)
+}
+
+// flagsEnd returns the index of the first argument of the command that is not parsed for flags
+func (e *Executor) flagsEnd(descriptor *commandDescriptor, args []string) int {
+	return e.newParser(descriptor).FlagsEnd(args, reflect.New(descriptor.GetConfigType()).Interface())
+}
+
+// NormalizeArgs returns the arguments of a command with flags converted from the
+// command's dialect to POSIX form
+func (e *Executor) NormalizeArgs(commandName string, args []string) []string {
+	descriptor, exists := e.registry.GetCommand(commandName)
+	if !exists {
+		return args
+	}
+	return e.normalizeArgs(descriptor, args)
+}
+
+// normalizeArgs converts the flags of a command from its dialect to POSIX form
+func (e *Executor) normalizeArgs(descriptor *commandDescriptor, args []string) []string {
+	return e.newParser(descriptor).Normalize(args, reflect.New(descriptor.GetConfigType()).Interface())
+}
+
+// newParser creates the argument parser for a command with the executor's settings
+func (e *Executor) newParser(descriptor *commandDescriptor) *EnhancedParser {
+	return NewEnhancedParser(e.binder).
+		WithAbbreviations(e.abbreviations).
+		WithLenientFlags(e.lenientFlags).
+		WithParseMode(descriptor.ParseMode()).
+		WithDialect(e.commandDialect(descriptor))
 }
 
 // buildMiddlewareChain builds the middleware execution chain
@@ -187,7 +468,7 @@ func (e *Executor) validateConfig(config any) error {
 			continue
 		}
 		
-		field := configValue.FieldByName(fieldInfo.Name)
+		field := configValue.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() || field.IsZero() {
 			return fmt.Errorf("required field %s is missing", fieldInfo.Name)
 		}
@@ -199,7 +480,7 @@ func (e *Executor) validateConfig(config any) error {
 			continue
 		}
 		
-		field := configValue.FieldByName(fieldInfo.Name)
+		field := configValue.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() || field.IsZero() {
 			continue
 		}
@@ -218,12 +499,28 @@ func (e *Executor) validateConfig(config any) error {
 		}
 	}
 	
+	// Check min, max and pattern rules
+	for _, fieldInfo := range metadata.Fields {
+		field := configValue.FieldByIndex(fieldInfo.Index)
+		if !field.IsValid() || field.IsZero() {
+			continue
+		}
+		
+		if err := fieldInfo.CheckValue(field); err != nil {
+			return fmt.Errorf("field %s %w", fieldInfo.Name, err)
+		}
+	}
+	
 	return nil
 }
 
 // EnhancedParser wraps the POSIX parser with additional functionality
 type EnhancedParser struct {
-	binder *bind.Binder
+	binder        *bind.Binder
+	abbreviations bool
+	lenientFlags  bool
+	parseMode     ParseMode
+	dialect       Dialect
 }
 
 // NewEnhancedParser creates a new enhanced parser
@@ -231,6 +528,31 @@ func NewEnhancedParser(binder *bind.Binder) *EnhancedParser {
 	return &EnhancedParser{binder: binder}
 }
 
+// WithAbbreviations enables unique-prefix matching of long flags, e.g. --verb for --verbose
+func (ep *EnhancedParser) WithAbbreviations(enabled bool) *EnhancedParser {
+	ep.abbreviations = enabled
+	return ep
+}
+
+// WithLenientFlags ignores unknown flags instead of rejecting them, so arguments meant
+// for newer versions of a command do not fail
+func (ep *EnhancedParser) WithLenientFlags(enabled bool) *EnhancedParser {
+	ep.lenientFlags = enabled
+	return ep
+}
+
+// WithDialect sets the flag syntax
+func (ep *EnhancedParser) WithDialect(dialect Dialect) *EnhancedParser {
+	ep.dialect = dialect
+	return ep
+}
+
+// WithParseMode sets where flags are accepted among the arguments
+func (ep *EnhancedParser) WithParseMode(mode ParseMode) *EnhancedParser {
+	ep.parseMode = mode
+	return ep
+}
+
 // Parse parses arguments and applies environment variables and defaults
 func (ep *EnhancedParser) Parse(args []string, target any) error {
 	// Apply environment variables first
@@ -239,9 +561,22 @@ func (ep *EnhancedParser) Parse(args []string, target any) error {
 	}
 	
 	// Parse command line arguments using POSIX parser
-	parser := posix.NewConfigurableParser(nil)
+	parser, metadata, err := ep.newParser(target)
+	if err != nil {
+		return err
+	}
 	result, err := parser.Parse(args)
 	if err != nil {
+		// Report unknown flags the way they are written in the dialect
+		var unknownFlag *posix.UnknownFlagError
+		if errors.As(err, &unknownFlag) {
+			unknownFlag.Flag = posix.POSIXFlag(ep.dialect, unknownFlag.Flag)
+		}
+		return err
+	}
+	
+	// Extra or missing positional arguments are errors
+	if err := metadata.CheckPositional(result.Positional); err != nil {
 		return err
 	}
 	
@@ -249,6 +584,67 @@ func (ep *EnhancedParser) Parse(args []string, target any) error {
 	return ep.binder.BindValues(target, result.Flags, result.Positional)
 }
 
+// FlagsEnd returns the index of the first argument that is not parsed for flags, so
+// arguments passed through to a wrapped command can be left alone
+func (ep *EnhancedParser) FlagsEnd(args []string, target any) int {
+	parser, _, err := ep.newParser(target)
+	if err != nil {
+		return len(args)
+	}
+	return parser.FlagsEnd(args)
+}
+
+// Normalize returns args with the flags written in the dialect converted to POSIX form
+func (ep *EnhancedParser) Normalize(args []string, target any) []string {
+	parser, _, err := ep.newParser(target)
+	if err != nil {
+		return args
+	}
+	return parser.Normalize(args)
+}
+
+// newParser creates a POSIX parser for the flags and positional fields of the target struct
+func (ep *EnhancedParser) newParser(target any) (*posix.ConfigurableParser, *bind.StructMetadata, error) {
+	metadata, err := bind.NewAnalyzer("posix").Analyze(reflect.TypeOf(target))
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	parser := posix.NewConfigurableParser(nil)
+	ep.addKnownFlags(parser, metadata)
+	return parser, metadata, nil
+}
+
+// addKnownFlags declares the flags of the target struct to the parser, so it knows
+// which flags take values and rejects unknown ones, and enables abbreviations when configured
+func (ep *EnhancedParser) addKnownFlags(parser *posix.ConfigurableParser, metadata *bind.StructMetadata) {
+	
+	for _, field := range metadata.Fields {
+		if field.Positional {
+			continue
+		}
+		
+		// Values other than booleans are converted by the binder
+		flagType := "string"
+		if field.Type.Kind() == reflect.Bool {
+			flagType = "bool"
+		}
+		parser.AddFlag(&posix.FlagInfo{
+			Name:          field.Name,
+			Short:         field.Short,
+			Long:          field.Long,
+			Type:          flagType,
+			Optional:      field.Optional,
+			OptionalValue: field.OptionalValue,
+		})
+	}
+	
+	parser.SetAllowAbbreviations(ep.abbreviations)
+	parser.SetStrictMode(!ep.lenientFlags)
+	parser.SetDialect(ep.dialect)
+	parser.SetStopAfter(posix.StopAfter(ep.parseMode == ParsePOSIX, metadata.PassthroughIndex()))
+}
+
 // applyEnvironmentVariables applies environment variable values
 func (ep *EnhancedParser) applyEnvironmentVariables(target any) error {
 	targetValue := reflect.ValueOf(target)
@@ -278,9 +674,22 @@ func (ep *EnhancedParser) applyEnvironmentVariables(target any) error {
 	return nil
 }
 
-// mergeConfigs merges base configuration into target configuration
-// Values in target (from CLI args) take precedence over base (from config file)
+// PartialConfig is a base configuration that sets only some fields, such as the
+// settings read from config files. Fields holds the index paths of the fields it
+// sets, so zero values like false or 0 override defaults too.
+type PartialConfig struct {
+	Config any
+	Fields [][]int
+}
+
+// mergeConfigs merges base configuration into target configuration. The fields set
+// by a PartialConfig override the target's defaults; of any other base, the non-zero values do.
 func (e *Executor) mergeConfigs(target, base any) error {
+	partial, isPartial := base.(*PartialConfig)
+	if isPartial {
+		base = partial.Config
+	}
+	
 	targetValue := reflect.ValueOf(target)
 	baseValue := reflect.ValueOf(base)
 	
@@ -304,7 +713,20 @@ func (e *Executor) mergeConfigs(target, base any) error {
 		return fmt.Errorf("target and base configurations must have the same type")
 	}
 	
-	// Copy non-zero values from base to target where target field is zero
+	if !isPartial {
+		mergeStructs(targetStruct, baseStruct)
+		return nil
+	}
+	
+	for _, index := range partial.Fields {
+		targetStruct.FieldByIndex(index).Set(baseStruct.FieldByIndex(index))
+	}
+	return nil
+}
+
+// mergeStructs copies non-zero values from base to target, over computed defaults,
+// descending into nested section structs field by field
+func mergeStructs(targetStruct, baseStruct reflect.Value) {
 	for i := 0; i < targetStruct.NumField(); i++ {
 		targetField := targetStruct.Field(i)
 		baseField := baseStruct.Field(i)
@@ -314,15 +736,18 @@ func (e *Executor) mergeConfigs(target, base any) error {
 			continue
 		}
 		
-		// If target field is zero and base field is not zero, copy from base
-		if targetField.IsZero() && !baseField.IsZero() {
+		if bind.IsSectionType(targetField.Type()) {
+			mergeStructs(targetField, baseField)
+			continue
+		}
+		
+		// Values set in the base override the target's computed defaults
+		if !baseField.IsZero() {
 			if targetField.Type() == baseField.Type() {
 				targetField.Set(baseField)
 			}
 		}
 	}
-	
-	return nil
 }
 
 // Built-in middleware
@@ -368,17 +793,31 @@ func TimeoutMiddleware(timeout time.Duration) Middleware {
 				CommandName: ctx.CommandName,
 				Args:        ctx.Args,
 				Metadata:    ctx.Metadata,
+				Profile:     ctx.Profile,
 			}
 			
+			// A panic in the command is raised again here, so recovery middleware
+			// outside the timeout sees it
 			done := make(chan error, 1)
+			panicked := make(chan any, 1)
 			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panicked <- r
+					}
+				}()
 				done <- next(newCtx)
 			}()
 			
 			select {
 			case err := <-done:
 				return err
+			case r := <-panicked:
+				panic(r)
 			case <-timeoutCtx.Done():
+				if err := ctx.Context.Err(); err != nil {
+					return fmt.Errorf("command cancelled: %w", err)
+				}
 				return fmt.Errorf("command timed out after %v", timeout)
 			}
 		}
@@ -0,0 +1,299 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eugener/clix/internal/posix"
+)
+
+type execConfig struct {
+	User    string   `posix:"u,user,Remote user"`
+	Verbose bool     `posix:"v,verbose,Verbose"`
+	Command string   `posix:",command,Command to run,positional"`
+	Args    []string `posix:",args,Command arguments,passthrough"`
+}
+
+type runConfig struct {
+	Verbose bool     `posix:"v,verbose,Verbose"`
+	Files   []string `posix:",,Files,positional"`
+}
+
+// buildTestConfig registers command with a fresh executor and builds its config from args
+func buildTestConfig[T any](t *testing.T, command *CommandBase[T], args []string, setup ...func(*Executor)) (T, error) {
+	t.Helper()
+	
+	registry := NewRegistry()
+	if err := Register(registry, command); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	executor := NewExecutor(registry)
+	for _, fn := range setup {
+		fn(executor)
+	}
+	
+	var zero T
+	config, err := executor.BuildConfig(command.Name(), args, nil)
+	if err != nil {
+		return zero, err
+	}
+	return *config.(*T), nil
+}
+
+func TestParseModes(t *testing.T) {
+	noop := func(ctx context.Context, c runConfig) error { return nil }
+	
+	tests := []struct {
+		name  string
+		mode  ParseMode
+		posix bool // POSIXLY_CORRECT set
+		args  []string
+		want  runConfig
+	}{
+		{name: "interleaved", args: []string{"a", "-v", "b"}, want: runConfig{Verbose: true, Files: []string{"a", "b"}}},
+		{name: "interleaved stops at --", args: []string{"a", "--", "-v"}, want: runConfig{Files: []string{"a", "-v"}}},
+		{name: "posix flags before positional", mode: ParsePOSIX, args: []string{"-v", "script.sh"}, want: runConfig{Verbose: true, Files: []string{"script.sh"}}},
+		{name: "posix stops at first positional", mode: ParsePOSIX, args: []string{"script.sh", "-v"}, want: runConfig{Files: []string{"script.sh", "-v"}}},
+		{name: "POSIXLY_CORRECT", posix: true, args: []string{"script.sh", "-v"}, want: runConfig{Files: []string{"script.sh", "-v"}}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.posix {
+				t.Setenv("POSIXLY_CORRECT", "1")
+			}
+			
+			got, err := buildTestConfig(t, NewCommand("run", "Run", noop).WithParseMode(tt.mode), tt.args)
+			if err != nil {
+				t.Fatalf("BuildConfig(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildConfig(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	noop := func(ctx context.Context, c execConfig) error { return nil }
+	
+	tests := []struct {
+		name string
+		args []string
+		want execConfig
+	}{
+		{name: "flags after the command are passed through", args: []string{"-u", "bob", "ls", "-la", "--user", "x"}, want: execConfig{User: "bob", Command: "ls", Args: []string{"-la", "--user", "x"}}},
+		{name: "flags before the command", args: []string{"-v", "--user=bob", "ls"}, want: execConfig{User: "bob", Verbose: true, Command: "ls", Args: []string{}}},
+		{name: "separator", args: []string{"-v", "--", "-x", "-y"}, want: execConfig{Verbose: true, Command: "-x", Args: []string{"-y"}}},
+		{name: "separator after the command is kept", args: []string{"ls", "--", "-v"}, want: execConfig{Command: "ls", Args: []string{"--", "-v"}}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTestConfig(t, NewCommand("exec", "Exec", noop), tt.args)
+			if err != nil {
+				t.Fatalf("BuildConfig(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildConfig(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPassthroughFieldRules(t *testing.T) {
+	type notLast struct {
+		Args []string `posix:",args,Arguments,passthrough"`
+		Name string   `posix:",name,Name,positional"`
+	}
+	type notStrings struct {
+		Args []int `posix:",args,Arguments,passthrough"`
+	}
+	
+	_, err := buildTestConfig(t, NewCommand("a", "A", func(ctx context.Context, c notLast) error { return nil }), nil)
+	if err == nil || !strings.Contains(err.Error(), "must be the last positional field") {
+		t.Errorf("passthrough before a positional: error = %v", err)
+	}
+	_, err = buildTestConfig(t, NewCommand("b", "B", func(ctx context.Context, c notStrings) error { return nil }), nil)
+	if err == nil || !strings.Contains(err.Error(), "must be a []string") {
+		t.Errorf("passthrough of ints: error = %v", err)
+	}
+}
+
+func TestPositionalArguments(t *testing.T) {
+	type copyConfig struct {
+		Src   string   `posix:",src,Source file,positional|required"`
+		Dst   string   `posix:",dst,Destination,positional|required"`
+		Count int      `posix:",count,Copies,positional"`
+		Files []string `posix:",files,More sources,positional|max=2"`
+	}
+	noop := func(ctx context.Context, c copyConfig) error { return nil }
+	
+	tests := []struct {
+		name    string
+		args    []string
+		want    copyConfig
+		wantErr string
+	}{
+		{name: "required", args: []string{"a", "b"}, want: copyConfig{Src: "a", Dst: "b", Files: []string{}}},
+		{name: "all", args: []string{"a", "b", "2", "c", "d"}, want: copyConfig{Src: "a", Dst: "b", Count: 2, Files: []string{"c", "d"}}},
+		{name: "missing", args: []string{"a"}, wantErr: "missing argument <dst>"},
+		{name: "extra", args: []string{"a", "b", "2", "c", "d", "e"}, wantErr: `unexpected argument "e", expected at most 5`},
+		{name: "typed", args: []string{"a", "b", "two"}, wantErr: `invalid value "two" for argument [count]`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTestConfig(t, NewCommand("cp", "Copy", noop), tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BuildConfig(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildConfig(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildConfig(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownFlags(t *testing.T) {
+	noop := func(ctx context.Context, c runConfig) error { return nil }
+	
+	tests := []struct {
+		name        string
+		args        []string
+		lenient     bool
+		want        runConfig
+		wantUnknown string
+	}{
+		{name: "known flags", args: []string{"-v", "a"}, want: runConfig{Verbose: true, Files: []string{"a"}}},
+		{name: "unknown long flag", args: []string{"--verbos"}, wantUnknown: "--verbos"},
+		{name: "unknown short flag", args: []string{"-x"}, wantUnknown: "-x"},
+		{name: "unknown flag after --", args: []string{"--", "--verbos"}, want: runConfig{Files: []string{"--verbos"}}},
+		{name: "lenient", args: []string{"--future", "-v", "a"}, lenient: true, want: runConfig{Verbose: true, Files: []string{"a"}}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTestConfig(t, NewCommand("run", "Run", noop), tt.args, func(e *Executor) {
+				e.SetLenientFlags(tt.lenient)
+			})
+			if tt.wantUnknown != "" {
+				var unknown *posix.UnknownFlagError
+				if !errors.As(err, &unknown) || unknown.Flag != tt.wantUnknown {
+					t.Fatalf("BuildConfig(%q) error = %v, want unknown flag %s", tt.args, err, tt.wantUnknown)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildConfig(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildConfig(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+// lifecycleConfig records the order of its lifecycle methods
+type lifecycleConfig struct {
+	Name    string `posix:"n,name,Name,env=LIFECYCLE_NAME"`
+	Workers int    `posix:"w,workers,Workers,default=1"`
+	Mode    string `posix:"m,mode,Mode"`
+	
+	calls []string
+}
+
+func (c *lifecycleConfig) Defaults() {
+	c.calls = append(c.calls, "Defaults")
+	c.Workers *= 4
+	c.Mode = "auto"
+}
+
+func (c *lifecycleConfig) Normalize() {
+	c.calls = append(c.calls, "Normalize")
+	c.Name = strings.ToLower(c.Name)
+}
+
+func (c *lifecycleConfig) Validate() error {
+	c.calls = append(c.calls, "Validate")
+	if c.Name == "invalid" {
+		return errors.New("name is invalid")
+	}
+	return nil
+}
+
+func TestConfigLifecycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     string
+		base    *lifecycleConfig
+		want    lifecycleConfig
+		wantErr string
+	}{
+		{name: "defaults", want: lifecycleConfig{Workers: 4, Mode: "auto"}},
+		{name: "flags then normalize", args: []string{"--name", "WEB", "-w", "2"}, want: lifecycleConfig{Name: "web", Workers: 2, Mode: "auto"}},
+		{name: "environment", env: "API", want: lifecycleConfig{Name: "api", Workers: 4, Mode: "auto"}},
+		{name: "flags override environment", args: []string{"-n", "cli"}, env: "API", want: lifecycleConfig{Name: "cli", Workers: 4, Mode: "auto"}},
+		{name: "base overrides defaults", base: &lifecycleConfig{Mode: "manual"}, want: lifecycleConfig{Workers: 4, Mode: "manual"}},
+		{name: "validate sees normalized values", args: []string{"-n", "INVALID"}, wantErr: "validation failed: name is invalid"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("LIFECYCLE_NAME", tt.env)
+			}
+			
+			var runs []*lifecycleConfig
+			registry := NewRegistry()
+			err := Register(registry, NewCommand("serve", "Serve", func(ctx context.Context, c *lifecycleConfig) error {
+				runs = append(runs, c)
+				return nil
+			}))
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			executor := NewExecutor(registry)
+			executor.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			
+			var base any
+			if tt.base != nil {
+				base = tt.base
+			}
+			err = executor.ExecuteWithConfig(context.Background(), "serve", tt.args, base)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExecuteWithConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExecuteWithConfig() error = %v", err)
+			}
+			
+			if len(runs) != 1 {
+				t.Fatalf("serve ran %d times, want 1", len(runs))
+			}
+			got := runs[0]
+			if wantCalls := []string{"Defaults", "Normalize", "Validate"}; !reflect.DeepEqual(got.calls, wantCalls) {
+				t.Errorf("calls = %v, want %v", got.calls, wantCalls)
+			}
+			got.calls = nil
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("serve ran with %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
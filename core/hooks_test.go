@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type hookConfig struct {
+	Fail string `posix:"f,fail,Step that fails"`
+}
+
+// hookRecorder records lifecycle events, also from the goroutine of a timed out command
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *hookRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// newHookCommand creates a command recording its hooks; --fail names the step that
+// fails, or panics for run-panic
+func newHookCommand(name string, recorder *hookRecorder) *CommandBase[hookConfig] {
+	return NewCommand(name, "Hooks", func(ctx context.Context, c hookConfig) error {
+		recorder.add(name + ":run")
+		switch c.Fail {
+		case "run":
+			return errors.New("run failed")
+		case "run-panic":
+			panic("run panicked")
+		}
+		return nil
+	}).OnPreRun(func(ctx context.Context, c hookConfig) error {
+		recorder.add(name + ":pre")
+		if c.Fail == "pre" {
+			return errors.New("pre failed")
+		}
+		return nil
+	}).OnPostRun(func(ctx context.Context, c hookConfig, runErr error) error {
+		recorder.add(name + ":post")
+		return runErr
+	}).OnCleanup(func() {
+		recorder.add(name + ":cleanup")
+	})
+}
+
+// newParentCommand creates a parent recording the children it prepares for; it fails
+// for a child named in failFor
+func newParentCommand(name string, recorder *hookRecorder, failFor string) *CommandBase[hookConfig] {
+	return NewCommand(name, "Parent", func(ctx context.Context, c hookConfig) error {
+		return nil
+	}).OnChildPreRun(func(ctx context.Context, commandName string) error {
+		recorder.add(name + ":child-pre " + commandName)
+		if commandName == failFor {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+}
+
+func TestCommandHooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		args       []string
+		middleware []Middleware
+		want       []string
+		wantErr    string
+	}{
+		{
+			name:    "success",
+			command: "migrate",
+			want:    []string{"migrate:pre", "migrate:run", "migrate:post", "migrate:cleanup"},
+		},
+		{
+			name:    "run error reaches post-run",
+			command: "migrate",
+			args:    []string{"--fail=run"},
+			want:    []string{"migrate:pre", "migrate:run", "migrate:post", "migrate:cleanup"},
+			wantErr: "run failed",
+		},
+		{
+			name:    "pre-run error skips run",
+			command: "migrate",
+			args:    []string{"--fail=pre"},
+			want:    []string{"migrate:pre", "migrate:cleanup"},
+			wantErr: "pre-run failed: pre failed",
+		},
+		{
+			name:       "panic runs cleanup before recovery",
+			command:    "migrate",
+			args:       []string{"--fail=run-panic"},
+			middleware: []Middleware{RecoveryMiddleware},
+			want:       []string{"migrate:pre", "migrate:run", "migrate:cleanup"},
+			wantErr:    "command panicked: run panicked",
+		},
+		{
+			name:       "panic under a timeout",
+			command:    "migrate",
+			args:       []string{"--fail=run-panic"},
+			middleware: []Middleware{RecoveryMiddleware, TimeoutMiddleware(time.Minute)},
+			want:       []string{"migrate:pre", "migrate:run", "migrate:cleanup"},
+			wantErr:    "command panicked: run panicked",
+		},
+		{
+			name:    "parents prepare outermost first",
+			command: "db:schema:migrate",
+			want: []string{
+				"db:child-pre db:schema:migrate", "db:schema:child-pre db:schema:migrate",
+				"db:schema:migrate:pre", "db:schema:migrate:run", "db:schema:migrate:post", "db:schema:migrate:cleanup",
+			},
+		},
+		{
+			name:    "parent error skips the child",
+			command: "db:seed",
+			want:    []string{"db:child-pre db:seed", "db:seed:cleanup"},
+			wantErr: "pre-run of db failed: not ready",
+		},
+		{
+			name:    "parents do not prepare for themselves",
+			command: "db",
+			want:    nil,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &hookRecorder{}
+			registry := NewRegistry()
+			for _, cmd := range []*CommandBase[hookConfig]{
+				newHookCommand("migrate", recorder),
+				newHookCommand("db:schema:migrate", recorder),
+				newHookCommand("db:seed", recorder),
+				newParentCommand("db", recorder, "db:seed"),
+				newParentCommand("db:schema", recorder, ""),
+			} {
+				if err := Register(registry, cmd); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+			}
+			
+			executor := NewExecutor(registry)
+			executor.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			executor.Use(tt.middleware...)
+			
+			err := executor.Execute(context.Background(), tt.command, tt.args)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+			}
+			if got := recorder.get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupAfterTimeout(t *testing.T) {
+	recorder := &hookRecorder{}
+	release := make(chan struct{})
+	cleaned := make(chan struct{})
+	
+	registry := NewRegistry()
+	err := Register(registry, NewCommand("wait", "Wait", func(ctx context.Context, c hookConfig) error {
+		<-release
+		recorder.add("run done")
+		return ctx.Err()
+	}).OnPostRun(func(ctx context.Context, c hookConfig, runErr error) error {
+		recorder.add("post")
+		return runErr
+	}).OnCleanup(func() {
+		recorder.add("cleanup")
+		close(cleaned)
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	executor := NewExecutor(registry)
+	executor.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	executor.Use(TimeoutMiddleware(10 * time.Millisecond))
+	
+	err = executor.Execute(context.Background(), "wait", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Execute() error = %v, want a timeout", err)
+	}
+	if got := recorder.get(); len(got) != 0 {
+		t.Fatalf("events before Run returned = %q, want none", got)
+	}
+	
+	close(release)
+	select {
+	case <-cleaned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cleanup did not run after Run returned")
+	}
+	
+	want := []string{"run done", "post", "cleanup"}
+	if got := recorder.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
@@ -1,6 +1,10 @@
 package core
 
-import "context"
+import (
+	"context"
+	
+	"github.com/eugener/clix/internal/posix"
+)
 
 // Command represents a type-safe CLI command with configuration of type T
 type Command[T any] interface {
@@ -14,6 +18,129 @@ type Command[T any] interface {
 	Description() string
 }
 
+// Reloadable is implemented by long-running commands that accept configuration
+// changes without a restart. Reload is called with each new valid configuration
+// while Run is still executing, so implementations must synchronize with Run.
+// Returning an error keeps the command on its current configuration.
+type Reloadable[T any] interface {
+	Reload(ctx context.Context, config T) error
+}
+
+// CommandProvider contributes commands to applications, e.g. a module of commands shared
+// by all CLIs of a company. Providers may also implement GlobalFlagProvider,
+// MiddlewareProvider and HelpTopicProvider.
+type CommandProvider interface {
+	// Name identifies the provider in errors about colliding names
+	Name() string
+	
+	// Commands returns the commands to register, as accepted by Registry.Register
+	Commands() []any
+}
+
+// GlobalFlagProvider is implemented by providers contributing application-level flags
+type GlobalFlagProvider interface {
+	GlobalFlags() []GlobalFlag
+}
+
+// MiddlewareProvider is implemented by providers contributing middleware
+type MiddlewareProvider interface {
+	Middleware() []Middleware
+}
+
+// HelpTopicProvider is implemented by providers contributing help topics
+type HelpTopicProvider interface {
+	HelpTopics() []HelpTopic
+}
+
+// GlobalFlag is an application-level flag taking a value, e.g. --region eu
+type GlobalFlag struct {
+	Name        string
+	Type        string // Value placeholder in help, e.g. "name"
+	Description string
+	Set         func(value string)
+	
+	// Get returns the current value, forwarded to plugins and kept between shell
+	// lines. Without it the value last set is used.
+	Get func() string
+}
+
+// HelpTopic is a page of help text shown by "help <topic>"
+type HelpTopic struct {
+	Name    string
+	Summary string // One line shown in the main help
+	Text    string
+}
+
+// Commands may implement PreRunner, PostRunner and Cleaner. The hooks run inside the
+// middleware chain: PreRun before Run, skipping Run when it fails; PostRun after Run
+// with its error, returning the command's error; and Cleanup once after a command
+// started, also when PreRun fails or Run panics. Cleanup runs in the goroutine of the
+// command after PostRun, so after a timeout it runs once Run returns.
+
+// PreRunner is implemented by commands that prepare for Run, e.g. open connections
+type PreRunner[T any] interface {
+	PreRun(ctx context.Context, config T) error
+}
+
+// PostRunner is implemented by commands that act on the result of Run; the returned
+// error replaces the error of Run
+type PostRunner[T any] interface {
+	PostRun(ctx context.Context, config T, runErr error) error
+}
+
+// Cleaner is implemented by commands that release resources after running
+type Cleaner interface {
+	Cleanup()
+}
+
+// ParentPreRunner is implemented by parent commands that prepare for the commands below
+// them in the command tree, named parent:child. PreRunChild gets the child's name and
+// runs before the child's PreRun, outer parents first; an error skips the child.
+type ParentPreRunner interface {
+	PreRunChild(ctx context.Context, commandName string) error
+}
+
+// Config structs may implement Defaulter, Normalizer and Validator, on the struct or
+// its pointer. The executor builds a config in this order: tag defaults, Defaults,
+// config file, environment variables, flags, Normalize, then tag rules and Validate.
+// Each source overrides the ones before it, including with zero values.
+
+// Defaulter is implemented by config structs that compute their own defaults
+type Defaulter interface {
+	Defaults()
+}
+
+// Normalizer is implemented by config structs that tidy their values after binding,
+// e.g. trimming or lowercasing
+type Normalizer interface {
+	Normalize()
+}
+
+// Validator is implemented by config structs that check their values; an error
+// rejects the configuration
+type Validator interface {
+	Validate() error
+}
+
+// SecretProvider resolves secret references given to secret flags as scheme:ref,
+// e.g. --token=exec:pass show api/token
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Dialect is a command line flag syntax: DialectPOSIX, DialectGo or DialectSlash
+type Dialect = posix.Dialect
+
+// Built-in flag syntaxes
+var (
+	// DialectPOSIX accepts -v, -n5, --name value and --name=value
+	DialectPOSIX Dialect = posix.POSIXDialect{}
+	// DialectGo accepts -name value and -name=value, like Go's flag package
+	DialectGo Dialect = posix.GoDialect{}
+	// DialectSlash accepts /name, /name:value and /? for help, Windows style
+	DialectSlash Dialect = posix.SlashDialect{}
+)
+
 // CLI represents the main CLI application
 type CLI interface {
 	// Register adds a command to the CLI
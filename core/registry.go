@@ -3,7 +3,21 @@ package core
 import (
 	"context"
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
+	"sync"
+)
+
+// ParseMode controls where a command accepts flags among its arguments
+type ParseMode int
+
+const (
+	// ParseInterleaved accepts flags anywhere before --, GNU style
+	ParseInterleaved ParseMode = iota
+	// ParsePOSIX stops parsing flags at the first positional argument, so commands
+	// wrapping other tools receive the rest untouched
+	ParsePOSIX
 )
 
 // CommandBase provides a base implementation for commands
@@ -11,6 +25,13 @@ type CommandBase[T any] struct {
 	name        string
 	description string
 	runner      func(ctx context.Context, config T) error
+	reloader    func(ctx context.Context, config T) error
+	preRun      func(ctx context.Context, config T) error
+	postRun     func(ctx context.Context, config T, runErr error) error
+	cleanup     func()
+	childPreRun func(ctx context.Context, commandName string) error
+	parseMode   ParseMode
+	dialect     Dialect
 }
 
 // NewCommand creates a new generic command
@@ -37,10 +58,111 @@ func (c *CommandBase[T]) Run(ctx context.Context, config T) error {
 	return c.runner(ctx, config)
 }
 
+// OnReload sets the handler receiving configuration changes while the command runs
+func (c *CommandBase[T]) OnReload(reloader func(ctx context.Context, config T) error) *CommandBase[T] {
+	c.reloader = reloader
+	return c
+}
+
+// Reload delivers a new configuration to the reload handler
+func (c *CommandBase[T]) Reload(ctx context.Context, config T) error {
+	if c.reloader == nil {
+		return fmt.Errorf("command %s does not support reloading", c.name)
+	}
+	return c.reloader(ctx, config)
+}
+
+// SupportsReload reports whether a reload handler is set
+func (c *CommandBase[T]) SupportsReload() bool {
+	return c.reloader != nil
+}
+
+// OnPreRun sets a hook preparing for Run; an error skips Run
+func (c *CommandBase[T]) OnPreRun(hook func(ctx context.Context, config T) error) *CommandBase[T] {
+	c.preRun = hook
+	return c
+}
+
+// PreRun calls the pre-run hook
+func (c *CommandBase[T]) PreRun(ctx context.Context, config T) error {
+	if c.preRun == nil {
+		return nil
+	}
+	return c.preRun(ctx, config)
+}
+
+// OnPostRun sets a hook receiving the error of Run and returning the command's error
+func (c *CommandBase[T]) OnPostRun(hook func(ctx context.Context, config T, runErr error) error) *CommandBase[T] {
+	c.postRun = hook
+	return c
+}
+
+// PostRun calls the post-run hook
+func (c *CommandBase[T]) PostRun(ctx context.Context, config T, runErr error) error {
+	if c.postRun == nil {
+		return runErr
+	}
+	return c.postRun(ctx, config, runErr)
+}
+
+// OnCleanup sets a hook releasing resources once the command has run
+func (c *CommandBase[T]) OnCleanup(hook func()) *CommandBase[T] {
+	c.cleanup = hook
+	return c
+}
+
+// Cleanup calls the cleanup hook
+func (c *CommandBase[T]) Cleanup() {
+	if c.cleanup != nil {
+		c.cleanup()
+	}
+}
+
+// OnChildPreRun sets a hook preparing for every command below this one in the command
+// tree, e.g. db:migrate below db, before the child's own PreRun; an error skips the child
+func (c *CommandBase[T]) OnChildPreRun(hook func(ctx context.Context, commandName string) error) *CommandBase[T] {
+	c.childPreRun = hook
+	return c
+}
+
+// PreRunChild calls the child pre-run hook
+func (c *CommandBase[T]) PreRunChild(ctx context.Context, commandName string) error {
+	if c.childPreRun == nil {
+		return nil
+	}
+	return c.childPreRun(ctx, commandName)
+}
+
+// WithParseMode sets where the command accepts flags among its arguments
+func (c *CommandBase[T]) WithParseMode(mode ParseMode) *CommandBase[T] {
+	c.parseMode = mode
+	return c
+}
+
+// ParseMode returns where the command accepts flags among its arguments
+func (c *CommandBase[T]) ParseMode() ParseMode {
+	return c.parseMode
+}
+
+// WithDialect sets the flag syntax of the command, overriding the application's
+func (c *CommandBase[T]) WithDialect(dialect Dialect) *CommandBase[T] {
+	c.dialect = dialect
+	return c
+}
+
+// Dialect returns the flag syntax of the command, or nil for the application's
+func (c *CommandBase[T]) Dialect() Dialect {
+	return c.dialect
+}
+
 // GetConfigType returns the reflect.Type for the config struct
 func (c *CommandBase[T]) GetConfigType() reflect.Type {
-	var zero T
-	return reflect.TypeOf(zero)
+	return configStructType(reflect.TypeFor[T]())
+}
+
+// registerWith adds the command to a registry with typed dispatch
+func (c *CommandBase[T]) registerWith(r *Registry) error {
+	return Register[T](r, c)
 }
 
 // Registry manages command registration with type safety
@@ -53,6 +175,21 @@ type commandDescriptor struct {
 	configType reflect.Type
 	name       string
 	desc       string
+	
+	// Typed closures of commands added with Register; nil for commands dispatched
+	// through reflection
+	run    func(ctx context.Context, config any) error
+	reload func(ctx context.Context, config any) error
+	
+	// Lifecycle hooks, nil when the command does not implement them
+	preRun      func(ctx context.Context, config any) error
+	postRun     func(ctx context.Context, config any, runErr error) error
+	cleanup     func()
+	childPreRun func(ctx context.Context, commandName string) error
+	
+	// Builds the command of a lazily registered descriptor on first use
+	factory func() *commandDescriptor
+	once    sync.Once
 }
 
 // NewRegistry creates a new command registry
@@ -62,9 +199,143 @@ func NewRegistry() *Registry {
 	}
 }
 
-// Register adds a command to the registry
+// Register adds a command to the registry with its config type known at compile time.
+// Run is called through a typed closure, without reflection. T may be a struct or a
+// pointer to one; a pointer receives the same config the executor built.
+func Register[T any](r *Registry, cmd Command[T]) error {
+	return r.add(typedDescriptor(cmd))
+}
+
+// RegisterLazy adds a command by name and description, building it with the factory
+// the first time it runs or is inspected, so large CLIs start fast. Listing commands
+// and reading their config types does not build them.
+func RegisterLazy[T any](r *Registry, name, description string, factory func() Command[T]) error {
+	return r.add(&commandDescriptor{
+		configType: configStructType(reflect.TypeFor[T]()),
+		name:       name,
+		desc:       description,
+		factory: func() *commandDescriptor {
+			return typedDescriptor(factory())
+		},
+	})
+}
+
+// typedDescriptor describes a command with typed closures for Run, Reload and its hooks
+func typedDescriptor[T any](cmd Command[T]) *commandDescriptor {
+	descriptor := &commandDescriptor{
+		instance:   cmd,
+		configType: configStructType(reflect.TypeFor[T]()),
+		name:       cmd.Name(),
+		desc:       cmd.Description(),
+		run: func(ctx context.Context, config any) error {
+			typed, err := typedConfig[T](config)
+			if err != nil {
+				return err
+			}
+			return cmd.Run(ctx, typed)
+		},
+	}
+	
+	if reloadable, ok := cmd.(Reloadable[T]); ok {
+		descriptor.reload = func(ctx context.Context, config any) error {
+			typed, err := typedConfig[T](config)
+			if err != nil {
+				return err
+			}
+			return reloadable.Reload(ctx, typed)
+		}
+	}
+	
+	if hook, ok := cmd.(PreRunner[T]); ok {
+		descriptor.preRun = func(ctx context.Context, config any) error {
+			typed, err := typedConfig[T](config)
+			if err != nil {
+				return err
+			}
+			return hook.PreRun(ctx, typed)
+		}
+	}
+	if hook, ok := cmd.(PostRunner[T]); ok {
+		descriptor.postRun = func(ctx context.Context, config any, runErr error) error {
+			typed, err := typedConfig[T](config)
+			if err != nil {
+				return err
+			}
+			return hook.PostRun(ctx, typed, runErr)
+		}
+	}
+	if hook, ok := cmd.(Cleaner); ok {
+		descriptor.cleanup = hook.Cleanup
+	}
+	if hook, ok := cmd.(ParentPreRunner); ok {
+		descriptor.childPreRun = hook.PreRunChild
+	}
+	
+	return descriptor
+}
+
+// resolve builds the command of a lazily registered descriptor, keeping its name and
+// description
+func (d *commandDescriptor) resolve() {
+	d.once.Do(func() {
+		if d.factory == nil {
+			return
+		}
+		built := d.factory()
+		d.instance = built.instance
+		d.run, d.reload = built.run, built.reload
+		d.preRun, d.postRun, d.cleanup = built.preRun, built.postRun, built.cleanup
+		d.childPreRun = built.childPreRun
+	})
+}
+
+// typedConfig returns the config given as T or *T
+func typedConfig[T any](config any) (T, error) {
+	switch typed := config.(type) {
+	case T:
+		return typed, nil
+	case *T:
+		return *typed, nil
+	}
+	var zero T
+	return zero, fmt.Errorf("config of type %T given for %v", config, reflect.TypeFor[T]())
+}
+
+// configStructType returns the struct type of a config given by value or pointer
+func configStructType(configType reflect.Type) reflect.Type {
+	if configType.Kind() == reflect.Ptr {
+		return configType.Elem()
+	}
+	return configType
+}
+
+// configArg returns the config built by the executor as the value or pointer a Run or
+// Reload method takes
+func configArg(config any, paramType reflect.Type) reflect.Value {
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() == reflect.Ptr && paramType.Kind() != reflect.Ptr {
+		configValue = configValue.Elem()
+	}
+	return configValue
+}
+
+// add stores a command descriptor, rejecting duplicate names
+func (r *Registry) add(descriptor *commandDescriptor) error {
+	if _, exists := r.commands[descriptor.name]; exists {
+		return fmt.Errorf("command %s already registered", descriptor.name)
+	}
+	r.commands[descriptor.name] = descriptor
+	return nil
+}
+
+// Register adds a command to the registry. Commands created with NewCommand are
+// dispatched through typed closures, others through reflection.
 func (r *Registry) Register(cmd any) error {
-	// Check if it's a CommandBase
+	if typed, ok := cmd.(interface{ registerWith(*Registry) error }); ok {
+		return typed.registerWith(r)
+	}
+	
+	// Commands reporting their config type
 	if baseCmd, ok := cmd.(interface{ GetConfigType() reflect.Type }); ok {
 		return r.registerBaseCommand(baseCmd)
 	}
@@ -84,21 +355,12 @@ func (r *Registry) registerBaseCommand(cmd interface{ GetConfigType() reflect.Ty
 		return fmt.Errorf("command must implement Name() and Description() methods")
 	}
 	
-	name := nameGetter.Name()
-	desc := descGetter.Description()
-	
-	if _, exists := r.commands[name]; exists {
-		return fmt.Errorf("command %s already registered", name)
-	}
-	
-	r.commands[name] = &commandDescriptor{
+	return r.add(reflectHooks(&commandDescriptor{
 		instance:   cmd,
 		configType: configType,
-		name:       name,
-		desc:       desc,
-	}
-	
-	return nil
+		name:       nameGetter.Name(),
+		desc:       descGetter.Description(),
+	}))
 }
 
 func (r *Registry) registerGenericCommand(cmd any) error {
@@ -113,32 +375,76 @@ func (r *Registry) registerGenericCommand(cmd any) error {
 		return fmt.Errorf("command must implement Name(), Description(), and Run() methods")
 	}
 	
-	// Extract config type from Run method signature
+	// Extract config type from Run method signature, bound to the command
 	runType := runMethod.Type()
-	if runType.NumIn() != 3 { // receiver, context, config
+	if runType.NumIn() != 2 { // context, config
 		return fmt.Errorf("Run method must have signature: Run(context.Context, T) error")
 	}
 	
-	configType := runType.In(2) // Third parameter is the config
+	configType := configStructType(runType.In(1))
 	
 	// Get name and description
 	nameResult := nameMethod.Call(nil)
 	descResult := descMethod.Call(nil)
 	
-	name := nameResult[0].String()
-	desc := descResult[0].String()
+	return r.add(reflectHooks(&commandDescriptor{
+		instance:   cmd,
+		configType: configType,
+		name:       nameResult[0].String(),
+		desc:       descResult[0].String(),
+	}))
+}
+
+// reflectHooks sets the lifecycle hooks of a command dispatched through reflection
+func reflectHooks(d *commandDescriptor) *commandDescriptor {
+	cmdValue := reflect.ValueOf(d.instance)
 	
-	if _, exists := r.commands[name]; exists {
-		return fmt.Errorf("command %s already registered", name)
+	if method := cmdValue.MethodByName("PreRun"); method.IsValid() && method.Type().NumIn() == 2 {
+		d.preRun = func(ctx context.Context, config any) error {
+			return callError(method, reflect.ValueOf(ctx), configArg(config, method.Type().In(1)))
+		}
+	}
+	if method := cmdValue.MethodByName("PostRun"); method.IsValid() && method.Type().NumIn() == 3 {
+		d.postRun = func(ctx context.Context, config any, runErr error) error {
+			errValue := reflect.Zero(method.Type().In(2))
+			if runErr != nil {
+				errValue = reflect.ValueOf(runErr)
+			}
+			return callError(method, reflect.ValueOf(ctx), configArg(config, method.Type().In(1)), errValue)
+		}
+	}
+	if cleaner, ok := d.instance.(Cleaner); ok {
+		d.cleanup = cleaner.Cleanup
+	}
+	if parent, ok := d.instance.(ParentPreRunner); ok {
+		d.childPreRun = parent.PreRunChild
 	}
 	
-	r.commands[name] = &commandDescriptor{
-		instance:   cmd,
-		configType: configType,
-		name:       name,
-		desc:       desc,
+	return d
+}
+
+// callError calls a method returning an error
+func callError(method reflect.Value, args ...reflect.Value) error {
+	results := method.Call(args)
+	if len(results) > 0 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
+
+// RegisterAs adds a command under another name than its own, e.g. namespaced
+func (r *Registry) RegisterAs(name string, cmd any) error {
+	scratch := NewRegistry()
+	if err := scratch.Register(cmd); err != nil {
+		return err
 	}
 	
+	for _, descriptor := range scratch.commands {
+		descriptor.name = name
+		if err := r.add(descriptor); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -163,19 +469,47 @@ func (r *Registry) Execute(ctx context.Context, name string, config any) error {
 	if !exists {
 		return fmt.Errorf("command not found: %s", name)
 	}
+	descriptor.resolve()
+	
+	if descriptor.run != nil {
+		return descriptor.run(ctx, config)
+	}
 	
 	// Call the Run method using reflection
 	cmdValue := reflect.ValueOf(descriptor.instance)
 	runMethod := cmdValue.MethodByName("Run")
 	
-	configValue := reflect.ValueOf(config)
-	if configValue.Kind() == reflect.Ptr {
-		configValue = configValue.Elem()
+	results := runMethod.Call([]reflect.Value{
+		reflect.ValueOf(ctx),
+		configArg(config, runMethod.Type().In(1)),
+	})
+	
+	if len(results) > 0 && !results[0].IsNil() {
+		return results[0].Interface().(error)
 	}
 	
-	results := runMethod.Call([]reflect.Value{
+	return nil
+}
+
+// Reload delivers a new configuration to a running command implementing Reloadable
+func (r *Registry) Reload(ctx context.Context, name string, config any) error {
+	descriptor, exists := r.commands[name]
+	if !exists {
+		return fmt.Errorf("command not found: %s", name)
+	}
+	
+	if !descriptor.SupportsReload() {
+		return fmt.Errorf("command %s does not support reloading", name)
+	}
+	
+	if descriptor.reload != nil {
+		return descriptor.reload(ctx, config)
+	}
+	
+	reloadMethod := reflect.ValueOf(descriptor.instance).MethodByName("Reload")
+	results := reloadMethod.Call([]reflect.Value{
 		reflect.ValueOf(ctx),
-		configValue,
+		configArg(config, reloadMethod.Type().In(1)),
 	})
 	
 	if len(results) > 0 && !results[0].IsNil() {
@@ -185,6 +519,70 @@ func (r *Registry) Execute(ctx context.Context, name string, config any) error {
 	return nil
 }
 
+// SupportsReload reports whether the command implements Reloadable for its config type
+func (d *commandDescriptor) SupportsReload() bool {
+	d.resolve()
+	if reloadable, ok := d.instance.(interface{ SupportsReload() bool }); ok && !reloadable.SupportsReload() {
+		return false
+	}
+	if d.run != nil {
+		return d.reload != nil
+	}
+	
+	method := reflect.ValueOf(d.instance).MethodByName("Reload")
+	if !method.IsValid() {
+		return false
+	}
+	
+	methodType := method.Type()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	return methodType.NumIn() == 2 &&
+		methodType.In(0) == reflect.TypeOf((*context.Context)(nil)).Elem() &&
+		configStructType(methodType.In(1)) == d.configType &&
+		methodType.NumOut() == 1 &&
+		methodType.Out(0) == errorType
+}
+
+// ParseMode returns where the command accepts flags. POSIXLY_CORRECT in the environment
+// selects POSIX ordering for all commands, as with GNU getopt.
+func (d *commandDescriptor) ParseMode() ParseMode {
+	d.resolve()
+	if _, set := os.LookupEnv("POSIXLY_CORRECT"); set {
+		return ParsePOSIX
+	}
+	if command, ok := d.instance.(interface{ ParseMode() ParseMode }); ok {
+		return command.ParseMode()
+	}
+	return ParseInterleaved
+}
+
+// Dialect returns the flag syntax chosen by the command, or nil
+func (d *commandDescriptor) Dialect() Dialect {
+	d.resolve()
+	if command, ok := d.instance.(interface{ Dialect() Dialect }); ok {
+		return command.Dialect()
+	}
+	return nil
+}
+
+// parents returns the registered parents of a command in the command tree that prepare
+// for their children, outermost first: db and db:schema for db:schema:migrate
+func (r *Registry) parents(name string) []*commandDescriptor {
+	var parents []*commandDescriptor
+	parts := strings.Split(name, ":")
+	for i := 1; i < len(parts); i++ {
+		parent, exists := r.commands[strings.Join(parts[:i], ":")]
+		if !exists {
+			continue
+		}
+		parent.resolve()
+		if parent.childPreRun != nil {
+			parents = append(parents, parent)
+		}
+	}
+	return parents
+}
+
 // GetConfigType returns the config type for a command
 func (d *commandDescriptor) GetConfigType() reflect.Type {
 	return d.configType
@@ -202,5 +600,6 @@ func (d *commandDescriptor) GetDescription() string {
 
 // GetInstance returns the command instance
 func (d *commandDescriptor) GetInstance() any {
+	d.resolve()
 	return d.instance
 }
\ No newline at end of file
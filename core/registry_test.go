@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type greetConfig struct {
+	Name string `posix:"n,name,Name"`
+}
+
+// greetCommand implements Command[greetConfig], recording the configs it runs with
+type greetCommand struct {
+	runs *[]greetConfig
+}
+
+func (c greetCommand) Name() string        { return "greet" }
+func (c greetCommand) Description() string { return "Greet" }
+func (c greetCommand) Run(ctx context.Context, config greetConfig) error {
+	*c.runs = append(*c.runs, config)
+	return nil
+}
+
+// greetPointerCommand implements Command[*greetConfig]
+type greetPointerCommand struct {
+	runs *[]*greetConfig
+}
+
+func (c greetPointerCommand) Name() string        { return "greet" }
+func (c greetPointerCommand) Description() string { return "Greet" }
+func (c greetPointerCommand) Run(ctx context.Context, config *greetConfig) error {
+	*c.runs = append(*c.runs, config)
+	return nil
+}
+
+// reflectedCommand has a Run method but is registered without its config type
+type reflectedCommand struct {
+	runs *[]greetConfig
+}
+
+func (c *reflectedCommand) Name() string        { return "greet" }
+func (c *reflectedCommand) Description() string { return "Greet" }
+func (c *reflectedCommand) Run(ctx context.Context, config greetConfig) error {
+	*c.runs = append(*c.runs, config)
+	return nil
+}
+
+func TestRegisterDispatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Registry, runs *[]greetConfig) error
+		typed    bool
+	}{
+		{
+			name: "generic Register",
+			register: func(r *Registry, runs *[]greetConfig) error {
+				return Register[greetConfig](r, greetCommand{runs: runs})
+			},
+			typed: true,
+		},
+		{
+			name: "NewCommand",
+			register: func(r *Registry, runs *[]greetConfig) error {
+				return r.Register(NewCommand("greet", "Greet", func(ctx context.Context, c greetConfig) error {
+					*runs = append(*runs, c)
+					return nil
+				}))
+			},
+			typed: true,
+		},
+		{
+			name: "lazy",
+			register: func(r *Registry, runs *[]greetConfig) error {
+				return RegisterLazy(r, "greet", "Greet", func() Command[greetConfig] {
+					return greetCommand{runs: runs}
+				})
+			},
+			typed: true,
+		},
+		{
+			name: "reflection",
+			register: func(r *Registry, runs *[]greetConfig) error {
+				return r.Register(&reflectedCommand{runs: runs})
+			},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs []greetConfig
+			registry := NewRegistry()
+			if err := tt.register(registry, &runs); err != nil {
+				t.Fatalf("register error = %v", err)
+			}
+			
+			descriptor, exists := registry.GetCommand("greet")
+			if !exists {
+				t.Fatal("greet not registered")
+			}
+			if descriptor.GetConfigType() != reflect.TypeOf(greetConfig{}) {
+				t.Errorf("GetConfigType() = %v, want greetConfig", descriptor.GetConfigType())
+			}
+			
+			// The executor passes a pointer; a value is accepted too
+			ctx := context.Background()
+			if err := registry.Execute(ctx, "greet", &greetConfig{Name: "pointer"}); err != nil {
+				t.Fatalf("Execute(pointer) error = %v", err)
+			}
+			if err := registry.Execute(ctx, "greet", greetConfig{Name: "value"}); err != nil {
+				t.Fatalf("Execute(value) error = %v", err)
+			}
+			
+			want := []greetConfig{{Name: "pointer"}, {Name: "value"}}
+			if !reflect.DeepEqual(runs, want) {
+				t.Errorf("runs = %+v, want %+v", runs, want)
+			}
+			if (descriptor.run != nil) != tt.typed {
+				t.Errorf("typed dispatch = %v, want %v", descriptor.run != nil, tt.typed)
+			}
+		})
+	}
+}
+
+func TestRegisterPointerConfig(t *testing.T) {
+	var runs []*greetConfig
+	registry := NewRegistry()
+	if err := Register[*greetConfig](registry, greetPointerCommand{runs: &runs}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	descriptor, _ := registry.GetCommand("greet")
+	if descriptor.GetConfigType() != reflect.TypeOf(greetConfig{}) {
+		t.Errorf("GetConfigType() = %v, want greetConfig", descriptor.GetConfigType())
+	}
+	
+	config := &greetConfig{Name: "x"}
+	if err := registry.Execute(context.Background(), "greet", config); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(runs) != 1 || runs[0] != config {
+		t.Errorf("Run got %v, want the executor's config %p", runs, config)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	var runs []greetConfig
+	
+	registry := NewRegistry()
+	if err := Register[greetConfig](registry, greetCommand{runs: &runs}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	if err := Register[greetConfig](registry, greetCommand{runs: &runs}); err == nil || err.Error() != "command greet already registered" {
+		t.Errorf("duplicate Register() error = %v", err)
+	}
+	if err := registry.Execute(context.Background(), "greet", &secretConfig{}); err == nil || !strings.Contains(err.Error(), "config of type *core.secretConfig given for core.greetConfig") {
+		t.Errorf("Execute() with the wrong config error = %v", err)
+	}
+	if err := registry.Execute(context.Background(), "missing", nil); err == nil || err.Error() != "command not found: missing" {
+		t.Errorf("Execute() of a missing command error = %v", err)
+	}
+	if err := registry.Register(struct{}{}); err == nil || !strings.Contains(err.Error(), "must implement Name(), Description(), and Run()") {
+		t.Errorf("Register() of a non-command error = %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("runs = %+v, want none", runs)
+	}
+}
+
+func TestRegisterAs(t *testing.T) {
+	var runs []greetConfig
+	registry := NewRegistry()
+	if err := registry.RegisterAs("team:greet", greetCommand{runs: &runs}); err != nil {
+		t.Fatalf("RegisterAs() error = %v", err)
+	}
+	if _, exists := registry.GetCommand("greet"); exists {
+		t.Error("greet registered under its own name too")
+	}
+	
+	if err := registry.Execute(context.Background(), "team:greet", &greetConfig{Name: "x"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].Name != "x" {
+		t.Errorf("runs = %+v, want one run", runs)
+	}
+}
@@ -0,0 +1,275 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/posix"
+)
+
+// SecretFileSuffix is appended to the long name of a secret flag to read its value
+// from a file, e.g. --password-file for --password
+const SecretFileSuffix = "-file"
+
+// FileSecretProvider reads secrets from files, without the trailing newline
+type FileSecretProvider struct{}
+
+// Resolve returns the contents of the file at ref
+func (FileSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return trimNewline(string(content)), nil
+}
+
+// ExecSecretProvider runs a command and uses its output as the secret, e.g.
+// exec:pass show api/token. The reference is split on spaces without a shell;
+// the command's standard error is passed through so it can prompt. It is not
+// built in, as it runs any program named by a flag value; register it with
+// config.WithSecretProvider("exec", core.ExecSecretProvider{}) to opt in.
+type ExecSecretProvider struct{}
+
+// Resolve runs the command in ref and returns its standard output
+func (ExecSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	fields := strings.Fields(ref)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty secret command")
+	}
+	
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("secret command %s failed: %w", fields[0], err)
+	}
+	
+	return trimNewline(stdout.String()), nil
+}
+
+// secretResolver resolves the values of secret flags in command arguments
+type secretResolver struct {
+	providers map[string]SecretProvider
+	
+	// stdin is read for secrets given as -, which are rejected when it is nil
+	stdin io.Reader
+	
+	// abbreviations resolves unique prefixes of long flags like the parser does
+	abbreviations bool
+}
+
+// newSecretResolver creates a resolver with the file provider
+func newSecretResolver() *secretResolver {
+	return &secretResolver{
+		providers: map[string]SecretProvider{
+			"file": FileSecretProvider{},
+		},
+		stdin: os.Stdin,
+	}
+}
+
+// stdinSecret reads standard input at most once while resolving the arguments of a
+// command, so every secret flag given as - gets the same value
+type stdinSecret struct {
+	reader io.Reader
+	once   sync.Once
+	value  string
+	err    error
+}
+
+// resolve rewrites secret flags in args as --name=value with the resolved secret, and
+// returns the rewritten arguments along with a copy whose secret values are zeroed.
+// Arguments from flagsEnd on are not flags and kept as they are.
+func (r *secretResolver) resolve(ctx context.Context, configType reflect.Type, args []string, flagsEnd int) ([]string, []string, error) {
+	metadata, err := bind.NewAnalyzer("posix").Analyze(configType)
+	if err != nil {
+		return args, args, nil // Reported when the configuration is parsed
+	}
+	
+	secrets := make(map[string]*bind.FieldInfo)
+	for i := range metadata.Fields {
+		field := &metadata.Fields[i]
+		if !field.Secret || field.Positional {
+			continue
+		}
+		secrets["--"+field.Long] = field
+		if field.Short != "" {
+			secrets["-"+field.Short] = field
+		}
+		// A flag of the command with the same name wins over the file variant
+		if _, defined := metadata.FieldMap[field.Long+SecretFileSuffix]; !defined {
+			secrets["--"+field.Long+SecretFileSuffix] = field
+		}
+	}
+	
+	if len(secrets) == 0 {
+		return args, args, nil
+	}
+	
+	resolved := make([]string, 0, len(args))
+	redacted := make([]string, 0, len(args))
+	stdin := &stdinSecret{reader: r.stdin}
+	
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		
+		// Everything after -- or a positional stopping flag parsing is positional
+		if arg == "--" || i >= flagsEnd {
+			resolved = append(resolved, args[i:]...)
+			redacted = append(redacted, args[i:]...)
+			break
+		}
+		
+		name, value, hasValue := strings.Cut(arg, "=")
+		if _, exact := secrets[name]; !exact && r.abbreviations && strings.HasPrefix(name, "--") {
+			name = "--" + r.matchLongFlag(name[2:], metadata, secrets)
+		}
+		field, isSecret := secrets[name]
+		if !isSecret && !strings.HasPrefix(arg, "--") {
+			name, value, hasValue, field, isSecret = r.matchShortFlag(arg, metadata, secrets)
+		}
+		if !isSecret {
+			resolved = append(resolved, arg)
+			redacted = append(redacted, arg)
+			continue
+		}
+		
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		
+		var secret string
+		if strings.HasSuffix(name, SecretFileSuffix) && name != "--"+field.Long {
+			secret, err = FileSecretProvider{}.Resolve(ctx, value)
+		} else {
+			secret, err = r.resolveValue(ctx, value, stdin)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("flag %s: %w", name, err)
+		}
+		
+		// Boolean flags bundled before a short secret flag are kept
+		if bundle := strings.TrimSuffix(name, field.Short); !strings.HasPrefix(name, "--") && bundle != "-" {
+			resolved = append(resolved, bundle)
+		}
+		resolved = append(resolved, "--"+field.Long+"="+secret)
+		if hasValue {
+			redacted = append(redacted, name+"=")
+		} else {
+			redacted = append(redacted, name, "")
+		}
+	}
+	
+	return resolved, redacted, nil
+}
+
+// matchLongFlag resolves an abbreviated long flag among the command's flags and the
+// file variants of its secret flags, returning the name unchanged when it is not unique
+func (r *secretResolver) matchLongFlag(name string, metadata *bind.StructMetadata, secrets map[string]*bind.FieldInfo) string {
+	var names []string
+	for long := range metadata.FieldMap {
+		names = append(names, long)
+	}
+	for flag := range secrets {
+		if strings.HasPrefix(flag, "--") {
+			names = append(names, flag[2:])
+		}
+	}
+	
+	match, candidates := posix.MatchPrefix(name, names)
+	if match != "" {
+		return match
+	}
+	
+	// A secret flag and its file variant count as one flag, the parser only knows the former
+	for _, candidate := range candidates {
+		field, isSecret := secrets["--"+candidate]
+		if !isSecret || candidates[0] != field.Long {
+			return name
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return name
+}
+
+// matchShortFlag finds a secret short flag in a bundle such as -vtTOKEN or -vt TOKEN,
+// returning the bundle up to the secret flag as its name and the attached value if any
+func (r *secretResolver) matchShortFlag(arg string, metadata *bind.StructMetadata, secrets map[string]*bind.FieldInfo) (string, string, bool, *bind.FieldInfo, bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", "", false, nil, false
+	}
+	
+	for j, c := range arg[1:] {
+		end := j + 1 + len(string(c))
+		if field, isSecret := secrets["-"+string(c)]; isSecret {
+			value := strings.TrimPrefix(arg[end:], "=")
+			return arg[:end], value, end < len(arg), field, true
+		}
+		// Only boolean flags can be followed by another flag in a bundle
+		field, known := metadata.ShortMap[string(c)]
+		if !known || field.Type.Kind() != reflect.Bool {
+			break
+		}
+	}
+	return "", "", false, nil, false
+}
+
+// resolveValue resolves a secret flag value: - reads standard input, @path reads a file,
+// scheme:ref uses a registered provider and anything else is the secret itself.
+// A leading @@ escapes a literal @.
+func (r *secretResolver) resolveValue(ctx context.Context, value string, stdin *stdinSecret) (string, error) {
+	switch {
+	case value == "-":
+		return stdin.read()
+	case strings.HasPrefix(value, "@@"):
+		return value[1:], nil
+	case strings.HasPrefix(value, "@"):
+		return FileSecretProvider{}.Resolve(ctx, value[1:])
+	}
+	
+	if scheme, ref, found := strings.Cut(value, ":"); found {
+		if provider, exists := r.providers[scheme]; exists {
+			return provider.Resolve(ctx, ref)
+		}
+	}
+	
+	return value, nil
+}
+
+// read reads the secret from standard input once
+func (s *stdinSecret) read() (string, error) {
+	if s.reader == nil {
+		return "", fmt.Errorf("standard input is not available for secrets, use @file instead")
+	}
+	s.once.Do(func() {
+		content, err := io.ReadAll(s.reader)
+		if err != nil {
+			s.err = fmt.Errorf("failed to read secret from stdin: %w", err)
+			return
+		}
+		s.value = trimNewline(string(content))
+	})
+	return s.value, s.err
+}
+
+// trimNewline removes a single trailing line ending
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type secretConfig struct {
+	Verbose bool     `posix:"v,verbose,Verbose"`
+	Token   string   `posix:"t,token,API token,secret"`
+	Name    string   `posix:"n,name,Name"`
+	Files   []string `posix:",,Files,positional"`
+}
+
+// mapSecretProvider resolves references from a map
+type mapSecretProvider map[string]string
+
+func (p mapSecretProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	if value, ok := p[ref]; ok {
+		return value, nil
+	}
+	return "", errors.New("no such secret")
+}
+
+// newSecretExecutor creates an executor with a secret command recording its configs
+func newSecretExecutor(t *testing.T, runs *[]secretConfig) *Executor {
+	t.Helper()
+	
+	registry := NewRegistry()
+	err := Register(registry, NewCommand("login", "Log in", func(ctx context.Context, c secretConfig) error {
+		*runs = append(*runs, c)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	
+	executor := NewExecutor(registry)
+	executor.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	executor.SetSecretProvider("vault", mapSecretProvider{"api": "from-vault"})
+	return executor
+}
+
+func TestSecretArgs(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	tests := []struct {
+		name         string
+		args         []string
+		abbreviate   bool
+		want         secretConfig
+		wantRedacted []string
+		wantErr      string
+	}{
+		{
+			name:         "long flag with value",
+			args:         []string{"--token=abc", "--name", "x"},
+			want:         secretConfig{Token: "abc", Name: "x"},
+			wantRedacted: []string{"--token=", "--name", "x"},
+		},
+		{
+			name:         "long flag with separate value",
+			args:         []string{"--token", "abc"},
+			want:         secretConfig{Token: "abc"},
+			wantRedacted: []string{"--token", ""},
+		},
+		{
+			name:         "short flag",
+			args:         []string{"-t", "abc"},
+			want:         secretConfig{Token: "abc"},
+			wantRedacted: []string{"-t", ""},
+		},
+		{
+			name:         "short flag bundled after a boolean",
+			args:         []string{"-vtabc"},
+			want:         secretConfig{Verbose: true, Token: "abc"},
+			wantRedacted: []string{"-vt="},
+		},
+		{
+			name:         "file reference",
+			args:         []string{"--token", "@" + tokenFile},
+			want:         secretConfig{Token: "from-file"},
+			wantRedacted: []string{"--token", ""},
+		},
+		{
+			name:         "file variant of the flag",
+			args:         []string{"--token-file=" + tokenFile},
+			want:         secretConfig{Token: "from-file"},
+			wantRedacted: []string{"--token-file="},
+		},
+		{
+			name:         "escaped at sign",
+			args:         []string{"--token=@@literal"},
+			want:         secretConfig{Token: "@literal"},
+			wantRedacted: []string{"--token="},
+		},
+		{
+			name:         "provider reference",
+			args:         []string{"--token", "vault:api"},
+			want:         secretConfig{Token: "from-vault"},
+			wantRedacted: []string{"--token", ""},
+		},
+		{
+			name:         "unregistered scheme is literal",
+			args:         []string{"--token", "exec:echo hi"},
+			want:         secretConfig{Token: "exec:echo hi"},
+			wantRedacted: []string{"--token", ""},
+		},
+		{
+			name:         "abbreviated flag",
+			args:         []string{"--tok=abc"},
+			abbreviate:   true,
+			want:         secretConfig{Token: "abc"},
+			wantRedacted: []string{"--token="},
+		},
+		{
+			name:         "positionals after -- are not secrets",
+			args:         []string{"--", "--token=abc"},
+			want:         secretConfig{Files: []string{"--token=abc"}},
+			wantRedacted: []string{"--", "--token=abc"},
+		},
+		{
+			name:    "missing value",
+			args:    []string{"--token"},
+			wantErr: "flag --token requires a value",
+		},
+		{
+			name:    "provider error",
+			args:    []string{"--token=vault:missing"},
+			wantErr: "flag --token: no such secret",
+		},
+		{
+			name:    "missing file",
+			args:    []string{"--token-file", filepath.Join(dir, "missing")},
+			wantErr: "failed to read secret file",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs []secretConfig
+			executor := newSecretExecutor(t, &runs)
+			executor.SetAbbreviations(tt.abbreviate)
+			
+			var seen []string
+			executor.Use(func(next ExecuteFunc) ExecuteFunc {
+				return func(execCtx *ExecutionContext) error {
+					seen = execCtx.Args
+					return next(execCtx)
+				}
+			})
+			
+			prepared, err := executor.PrepareArgs(context.Background(), "login", tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PrepareArgs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PrepareArgs() error = %v", err)
+			}
+			if !reflect.DeepEqual(prepared.Redacted, tt.wantRedacted) {
+				t.Errorf("Redacted = %q, want %q", prepared.Redacted, tt.wantRedacted)
+			}
+			
+			if err := executor.ExecutePrepared(context.Background(), prepared, nil); err != nil {
+				t.Fatalf("ExecutePrepared() error = %v", err)
+			}
+			// The parser sets an empty list when there are no positionals
+			if tt.want.Files == nil {
+				tt.want.Files = []string{}
+			}
+			if len(runs) != 1 || !reflect.DeepEqual(runs[0], tt.want) {
+				t.Errorf("command ran with %+v, want %+v", runs, tt.want)
+			}
+			if !reflect.DeepEqual(seen, tt.wantRedacted) {
+				t.Errorf("middleware saw %q, want %q", seen, tt.wantRedacted)
+			}
+		})
+	}
+}
+
+func TestSecretStdin(t *testing.T) {
+	var runs []secretConfig
+	executor := newSecretExecutor(t, &runs)
+	
+	for _, input := range []string{"first\n", "second\n"} {
+		executor.SetSecretStdin(strings.NewReader(input))
+		if err := executor.Execute(context.Background(), "login", []string{"--token", "-"}); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	var got []string
+	for _, run := range runs {
+		got = append(got, run.Token)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tokens = %q, want %q", got, want)
+	}
+	
+	executor.SetSecretStdin(nil)
+	err := executor.Execute(context.Background(), "login", []string{"--token", "-"})
+	if err == nil || !strings.Contains(err.Error(), "standard input is not available") {
+		t.Errorf("Execute() without stdin error = %v, want standard input is not available", err)
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/eugener/clix/internal/bind"
+)
+
+// Services is a container of services shared by commands, such as database pools and
+// HTTP clients. Services are built by typed providers the first time a command asks
+// for them, and closed in reverse order when the application finishes.
+type Services struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*service
+	built     []*service // Services in the order they were built
+}
+
+// service is a provider and the service it built
+type service struct {
+	mu      sync.Mutex
+	provide func(ctx context.Context) (any, error)
+	built   bool
+	value   any
+}
+
+type servicesKey struct{}
+
+// buildingKey holds the service types being built, to detect dependency cycles
+type buildingKey struct{}
+
+// NewServices creates an empty service container
+func NewServices() *Services {
+	return &Services{
+		providers: make(map[reflect.Type]*service),
+	}
+}
+
+// Provide registers the provider of a service type, replacing an earlier one. The
+// provider runs once, when a command first asks for the service; it may ask for
+// other services through its context.
+func Provide[T any](s *Services, provider func(ctx context.Context) (T, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.providers[reflect.TypeFor[T]()] = &service{
+		provide: func(ctx context.Context) (any, error) {
+			return provider(ctx)
+		},
+	}
+}
+
+// Service returns the service of type T from the context of a command, building it on
+// first use. The context may be the ExecutionContext or the context given to Run.
+func Service[T any](ctx context.Context) (T, error) {
+	var zero T
+	
+	value, err := ServicesFrom(ctx).get(ctx, reflect.TypeFor[T]())
+	if err != nil {
+		return zero, err
+	}
+	typed, _ := value.(T) // A nil service is the zero T
+	return typed, nil
+}
+
+// WithServices returns a context carrying the service container
+func WithServices(ctx context.Context, s *Services) context.Context {
+	return context.WithValue(ctx, servicesKey{}, s)
+}
+
+// ServicesFrom returns the service container of a context, or nil
+func ServicesFrom(ctx context.Context) *Services {
+	s, _ := ctx.Value(servicesKey{}).(*Services)
+	return s
+}
+
+// get returns the service of a type, building it on first use
+func (s *Services) get(ctx context.Context, serviceType reflect.Type) (any, error) {
+	if s == nil {
+		return nil, fmt.Errorf("no services in context for %v", serviceType)
+	}
+	
+	s.mu.Lock()
+	svc, exists := s.providers[serviceType]
+	s.mu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("no provider for service %v", serviceType)
+	}
+	
+	building, _ := ctx.Value(buildingKey{}).([]reflect.Type)
+	if slices.Contains(building, serviceType) {
+		return nil, fmt.Errorf("service %v depends on itself: %v", serviceType, append(building, serviceType))
+	}
+	
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.built {
+		return svc.value, nil
+	}
+	
+	// Failed providers run again the next time the service is asked for
+	value, err := svc.provide(context.WithValue(ctx, buildingKey{}, append(slices.Clone(building), serviceType)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build service %v: %w", serviceType, err)
+	}
+	
+	svc.value = value
+	svc.built = true
+	
+	s.mu.Lock()
+	s.built = append(s.built, svc)
+	s.mu.Unlock()
+	
+	return value, nil
+}
+
+// Inject sets the fields of a config struct tagged inject to the services of their
+// types, e.g. DB *sql.DB `inject:""`
+func (s *Services) Inject(ctx context.Context, config any) error {
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() != reflect.Ptr || configValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a pointer to struct")
+	}
+	
+	configStruct := configValue.Elem()
+	for i := 0; i < configStruct.NumField(); i++ {
+		field := configStruct.Type().Field(i)
+		if !bind.IsInjected(field) || !field.IsExported() {
+			continue
+		}
+		
+		value, err := s.get(ctx, field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if value != nil {
+			configStruct.Field(i).Set(reflect.ValueOf(value))
+		}
+	}
+	
+	return nil
+}
+
+// Close closes the services built so far in reverse order, those implementing
+// io.Closer or Close(), and forgets them so they are built again when asked for
+func (s *Services) Close() error {
+	s.mu.Lock()
+	built := s.built
+	s.built = nil
+	s.mu.Unlock()
+	
+	var errs []error
+	for _, svc := range slices.Backward(built) {
+		svc.mu.Lock()
+		switch closer := svc.value.(type) {
+		case io.Closer:
+			errs = append(errs, closer.Close())
+		case interface{ Close() }:
+			closer.Close()
+		}
+		svc.value = nil
+		svc.built = false
+		svc.mu.Unlock()
+	}
+	
+	return errors.Join(errs...)
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDB struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (db *testDB) Close() error {
+	*db.closed = append(*db.closed, db.name)
+	return db.err
+}
+
+type testClient struct {
+	db     *testDB
+	closed *[]string
+}
+
+func (c *testClient) Close() {
+	*c.closed = append(*c.closed, "client")
+}
+
+type testCache struct{}
+
+func TestService(t *testing.T) {
+	var closed []string
+	builds := map[string]int{}
+	
+	services := NewServices()
+	Provide(services, func(ctx context.Context) (*testDB, error) {
+		builds["db"]++
+		return &testDB{name: "db", closed: &closed}, nil
+	})
+	Provide(services, func(ctx context.Context) (*testClient, error) {
+		builds["client"]++
+		db, err := Service[*testDB](ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &testClient{db: db, closed: &closed}, nil
+	})
+	ctx := WithServices(context.Background(), services)
+	
+	if len(builds) != 0 {
+		t.Fatalf("builds before use = %v, want none", builds)
+	}
+	
+	client, err := Service[*testClient](ctx)
+	if err != nil {
+		t.Fatalf("Service() error = %v", err)
+	}
+	again, err := Service[*testClient](ctx)
+	if err != nil {
+		t.Fatalf("Service() error = %v", err)
+	}
+	db, _ := Service[*testDB](ctx)
+	
+	if client != again || client.db != db {
+		t.Error("Service() built a service twice")
+	}
+	if want := map[string]int{"db": 1, "client": 1}; !reflect.DeepEqual(builds, want) {
+		t.Errorf("builds = %v, want %v", builds, want)
+	}
+}
+
+func TestServiceErrors(t *testing.T) {
+	type a struct{}
+	type b struct{}
+	
+	attempts := 0
+	services := NewServices()
+	Provide(services, func(ctx context.Context) (a, error) {
+		_, err := Service[b](ctx)
+		return a{}, err
+	})
+	Provide(services, func(ctx context.Context) (b, error) {
+		_, err := Service[a](ctx)
+		return b{}, err
+	})
+	Provide(services, func(ctx context.Context) (*testCache, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return &testCache{}, nil
+	})
+	ctx := WithServices(context.Background(), services)
+	
+	tests := []struct {
+		name    string
+		get     func(ctx context.Context) error
+		ctx     context.Context
+		wantErr string
+	}{
+		{
+			name:    "no services in context",
+			get:     func(ctx context.Context) error { _, err := Service[*testDB](ctx); return err },
+			ctx:     context.Background(),
+			wantErr: "no services in context for *core.testDB",
+		},
+		{
+			name:    "no provider",
+			get:     func(ctx context.Context) error { _, err := Service[*testDB](ctx); return err },
+			ctx:     ctx,
+			wantErr: "no provider for service *core.testDB",
+		},
+		{
+			name:    "cycle",
+			get:     func(ctx context.Context) error { _, err := Service[a](ctx); return err },
+			ctx:     ctx,
+			wantErr: "service core.a depends on itself: [core.a core.b core.a]",
+		},
+		{
+			name:    "failed provider",
+			get:     func(ctx context.Context) error { _, err := Service[*testCache](ctx); return err },
+			ctx:     ctx,
+			wantErr: "failed to build service *core.testCache: unavailable",
+		},
+		{
+			name: "failed provider runs again",
+			get:  func(ctx context.Context) error { _, err := Service[*testCache](ctx); return err },
+			ctx:  ctx,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get(tt.ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Service() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Service() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServicesClose(t *testing.T) {
+	var closed []string
+	builds := 0
+	
+	services := NewServices()
+	Provide(services, func(ctx context.Context) (*testDB, error) {
+		builds++
+		return &testDB{name: "db", closed: &closed, err: errors.New("db close failed")}, nil
+	})
+	Provide(services, func(ctx context.Context) (*testClient, error) {
+		db, err := Service[*testDB](ctx)
+		return &testClient{db: db, closed: &closed}, err
+	})
+	Provide(services, func(ctx context.Context) (*testCache, error) {
+		return &testCache{}, nil
+	})
+	ctx := WithServices(context.Background(), services)
+	
+	if _, err := Service[*testClient](ctx); err != nil {
+		t.Fatalf("Service() error = %v", err)
+	}
+	if _, err := Service[*testCache](ctx); err != nil {
+		t.Fatalf("Service() error = %v", err)
+	}
+	
+	// The client was built after the db it depends on, so it is closed first
+	err := services.Close()
+	if err == nil || err.Error() != "db close failed" {
+		t.Errorf("Close() error = %v, want the db's error", err)
+	}
+	if want := []string{"client", "db"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("closed = %v, want %v", closed, want)
+	}
+	
+	// Closed services are built again when asked for
+	if _, err := Service[*testDB](ctx); err != nil {
+		t.Fatalf("Service() after Close error = %v", err)
+	}
+	if builds != 2 {
+		t.Errorf("db built %d times, want 2", builds)
+	}
+	
+	closed = nil
+	services.Close()
+	services.Close()
+	if want := []string{"db"}; !reflect.DeepEqual(closed, want) {
+		t.Errorf("closed after a second Close = %v, want %v", closed, want)
+	}
+}
+
+func TestInject(t *testing.T) {
+	type injectConfig struct {
+		Name  string     `posix:"n,name,Name"`
+		DB    *testDB    `inject:""`
+		Cache *testCache `inject:""`
+		Other *testDB
+	}
+	
+	var closed []string
+	services := NewServices()
+	Provide(services, func(ctx context.Context) (*testDB, error) {
+		return &testDB{name: "db", closed: &closed}, nil
+	})
+	ctx := WithServices(context.Background(), services)
+	
+	var config injectConfig
+	err := services.Inject(ctx, &config)
+	if err == nil || !strings.Contains(err.Error(), "field Cache: no provider for service *core.testCache") {
+		t.Fatalf("Inject() error = %v, want no provider for Cache", err)
+	}
+	
+	Provide(services, func(ctx context.Context) (*testCache, error) {
+		return &testCache{}, nil
+	})
+	if err := services.Inject(ctx, &config); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if config.DB == nil || config.Cache == nil || config.Other != nil {
+		t.Errorf("Inject() = %+v, want DB and Cache set", config)
+	}
+	
+	if err := services.Inject(ctx, config); err == nil {
+		t.Error("Inject() of a struct value succeeded, want an error")
+	}
+}
+
+func TestInjectThroughExecutor(t *testing.T) {
+	type reportConfig struct {
+		Month string  `posix:"m,month,Month"`
+		DB    *testDB `inject:""`
+	}
+	
+	var closed []string
+	services := NewServices()
+	Provide(services, func(ctx context.Context) (*testDB, error) {
+		return &testDB{name: "db", closed: &closed}, nil
+	})
+	
+	var got []reportConfig
+	registry := NewRegistry()
+	err := Register(registry, NewCommand("report", "Report", func(ctx context.Context, c reportConfig) error {
+		db, err := Service[*testDB](ctx)
+		if err != nil || db != c.DB {
+			t.Errorf("Service() = %v, %v, want the injected db", db, err)
+		}
+		got = append(got, c)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	executor := NewExecutor(registry)
+	executor.SetServices(services)
+	
+	if err := executor.Execute(context.Background(), "report", []string{"-m", "may"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Month != "may" || got[0].DB == nil {
+		t.Errorf("report ran with %+v, want month and db", got)
+	}
+	if len(closed) != 0 {
+		t.Errorf("closed = %v, want services open until the application closes them", closed)
+	}
+}
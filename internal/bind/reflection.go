@@ -1,10 +1,14 @@
 package bind
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // FieldInfo contains metadata about a struct field
@@ -21,6 +25,38 @@ type FieldInfo struct {
 	Positional  bool
 	Environment string
 	Validator   func(any) error
+	
+	// Secret values are resolved from files, stdin or providers and never logged
+	Secret bool
+	// Optional flags may be given without a value, which then is OptionalValue
+	Optional      bool
+	OptionalValue string
+	// Passthrough positionals capture the remaining arguments verbatim, flags included
+	Passthrough bool
+	
+	// Min and Max bound numbers, or the length of strings and slices
+	Min *float64
+	Max *float64
+	// Pattern is a regular expression string values must match
+	Pattern string
+	pattern *regexp.Regexp
+	
+	// Index is the field index path, usable with reflect.Value.FieldByIndex
+	Index []int
+	// Path is the dotted configuration key, e.g. "database.host"
+	Path string
+	// Section is the dotted path of the enclosing section, empty at top level
+	Section string
+}
+
+// SectionInfo describes a nested struct mapped to a configuration section
+type SectionInfo struct {
+	Name        string
+	Path        string
+	Description string
+	Prefix      string
+	EnvPrefix   string
+	Index       []int
 }
 
 // StructMetadata contains all field information for a struct
@@ -30,8 +66,15 @@ type StructMetadata struct {
 	ShortMap    map[string]*FieldInfo
 	Positional  []*FieldInfo
 	Environment map[string]*FieldInfo
+	Sections    []SectionInfo
 }
 
+// textUnmarshalerType is used to keep value types such as time.Time out of sections
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// durationType is parsed with time.ParseDuration rather than as an integer
+var durationType = reflect.TypeOf(time.Duration(0))
+
 // Analyzer provides reflection-based struct analysis
 type Analyzer struct {
 	tagName string
@@ -45,7 +88,7 @@ func NewAnalyzer(tagName string) *Analyzer {
 	return &Analyzer{tagName: tagName}
 }
 
-// Analyze extracts metadata from a struct type
+// Analyze extracts metadata from a struct type, cached by type
 func (a *Analyzer) Analyze(structType reflect.Type) (*StructMetadata, error) {
 	if structType.Kind() == reflect.Ptr {
 		structType = structType.Elem()
@@ -55,14 +98,171 @@ func (a *Analyzer) Analyze(structType reflect.Type) (*StructMetadata, error) {
 		return nil, fmt.Errorf("expected struct type, got %s", structType.Kind())
 	}
 	
+	key := metadataKey{tagName: a.tagName, structType: structType}
+	if cached, ok := metadataCache.Load(key); ok {
+		entry := cached.(metadataEntry)
+		return entry.metadata, entry.err
+	}
+	
+	metadata, err := a.analyze(structType)
+	metadataCache.Store(key, metadataEntry{metadata: metadata, err: err})
+	return metadata, err
+}
+
+// metadataKey identifies analyzed struct metadata
+type metadataKey struct {
+	tagName    string
+	structType reflect.Type
+}
+
+// metadataEntry is the result of analyzing a struct type
+type metadataEntry struct {
+	metadata *StructMetadata
+	err      error
+}
+
+// metadataCache holds the metadata of analyzed struct types. Metadata is not modified
+// after analysis, so parsing, binding and validation share it instead of analyzing a
+// config type again for every use.
+var metadataCache sync.Map
+
+// analyze extracts the metadata of a struct type
+func (a *Analyzer) analyze(structType reflect.Type) (*StructMetadata, error) {
 	metadata := &StructMetadata{
 		Fields:      make([]FieldInfo, 0),
 		FieldMap:    make(map[string]*FieldInfo),
 		ShortMap:    make(map[string]*FieldInfo),
 		Positional:  make([]*FieldInfo, 0),
 		Environment: make(map[string]*FieldInfo),
+		Sections:    make([]SectionInfo, 0),
+	}
+	
+	if err := a.analyzeStruct(structType, &SectionInfo{}, metadata); err != nil {
+		return nil, err
+	}
+	
+	if err := metadata.validatePositional(); err != nil {
+		return nil, err
+	}
+	
+	return metadata, nil
+}
+
+// validatePositional checks that positional fields can be told apart: required ones
+// come first and a slice taking the remaining arguments comes last
+func (m *StructMetadata) validatePositional() error {
+	optional := ""
+	for i, field := range m.Positional {
+		if IsListType(field.Type) && i != len(m.Positional)-1 {
+			if field.Passthrough {
+				return fmt.Errorf("passthrough field %s must be the last positional field", field.Name)
+			}
+			return fmt.Errorf("slice positional field %s must be the last positional field", field.Name)
+		}
+		if !IsListType(field.Type) && field.Required && optional != "" {
+			return fmt.Errorf("required positional field %s cannot follow optional %s", field.Name, optional)
+		}
+		if !field.Required {
+			optional = field.Name
+		}
+	}
+	return nil
+}
+
+// PositionalArity returns the minimum and maximum number of positional arguments; the
+// maximum is -1 when a slice field takes any number of them
+func (m *StructMetadata) PositionalArity() (int, int) {
+	minArgs, maxArgs := 0, 0
+	for _, field := range m.Positional {
+		if !IsListType(field.Type) {
+			maxArgs++
+			if field.Required {
+				minArgs++
+			}
+			continue
+		}
+		
+		minItems, maxItems := field.itemArity()
+		minArgs += minItems
+		if maxItems < 0 {
+			return minArgs, -1
+		}
+		maxArgs += maxItems
+	}
+	return minArgs, maxArgs
+}
+
+// CheckPositional checks the number of positional arguments against the positional fields
+func (m *StructMetadata) CheckPositional(args []string) error {
+	minArgs, maxArgs := m.PositionalArity()
+	
+	if maxArgs >= 0 && len(args) > maxArgs {
+		if maxArgs == 0 {
+			return fmt.Errorf("unexpected argument %q, the command takes no arguments", args[maxArgs])
+		}
+		return fmt.Errorf("unexpected argument %q, expected at most %d", args[maxArgs], maxArgs)
+	}
+	
+	if len(args) >= minArgs {
+		return nil
 	}
 	
+	for i, field := range m.Positional {
+		if IsListType(field.Type) {
+			minItems, _ := field.itemArity()
+			return fmt.Errorf("missing argument %s, expected at least %d", field.Usage(), minItems)
+		}
+		if field.Required && i >= len(args) {
+			return fmt.Errorf("missing argument %s", field.Usage())
+		}
+	}
+	return nil
+}
+
+// itemArity returns the minimum and maximum number of arguments taken by a slice
+// positional field, from min and max or required; the maximum is -1 when unbounded
+func (f *FieldInfo) itemArity() (int, int) {
+	minItems, maxItems := 0, -1
+	if f.Required {
+		minItems = 1
+	}
+	if f.Min != nil {
+		minItems = int(*f.Min)
+	}
+	if f.Max != nil {
+		maxItems = int(*f.Max)
+	}
+	return minItems, maxItems
+}
+
+// Usage returns how a positional field appears in usage lines: <src> when required,
+// [src] when optional, and <files>... or [files...] when it takes several arguments
+func (f *FieldInfo) Usage() string {
+	if IsListType(f.Type) {
+		if minItems, _ := f.itemArity(); minItems > 0 {
+			return "<" + f.Long + ">..."
+		}
+		return "[" + f.Long + "...]"
+	}
+	if f.Required {
+		return "<" + f.Long + ">"
+	}
+	return "[" + f.Long + "]"
+}
+
+// PassthroughIndex returns the position of the passthrough field among the positional
+// fields, or -1 when there is none
+func (m *StructMetadata) PassthroughIndex() int {
+	for i, field := range m.Positional {
+		if field.Passthrough {
+			return i
+		}
+	}
+	return -1
+}
+
+// analyzeStruct collects the fields of structType into metadata, descending into sections
+func (a *Analyzer) analyzeStruct(structType reflect.Type, section *SectionInfo, metadata *StructMetadata) error {
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
 		
@@ -71,15 +271,70 @@ func (a *Analyzer) Analyze(structType reflect.Type) (*StructMetadata, error) {
 			continue
 		}
 		
+		index := append(append([]int{}, section.Index...), i)
+		
+		// Nested structs map to configuration sections
+		if IsSectionType(field.Type) {
+			tag := field.Tag.Get(a.tagName)
+			if tag == "-" {
+				continue
+			}
+			
+			// Untagged embedded structs are flattened into the parent
+			if field.Anonymous && tag == "" {
+				embedded := *section
+				embedded.Index = index
+				if err := a.analyzeStruct(field.Type, &embedded, metadata); err != nil {
+					return err
+				}
+				continue
+			}
+			
+			if tag == "" {
+				continue // Skip fields without tags
+			}
+			
+			child, err := a.parseSection(field, section, index)
+			if err != nil {
+				return fmt.Errorf("error parsing section %s: %w", field.Name, err)
+			}
+			
+			metadata.Sections = append(metadata.Sections, *child)
+			if err := a.analyzeStruct(field.Type, child, metadata); err != nil {
+				return err
+			}
+			continue
+		}
+		
 		fieldInfo, err := a.parseField(field)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing field %s: %w", field.Name, err)
+			return fmt.Errorf("error parsing field %s: %w", field.Name, err)
 		}
 		
 		if fieldInfo == nil {
 			continue // Skip fields without tags
 		}
 		
+		fieldInfo.Index = index
+		fieldInfo.Path = fieldInfo.Long
+		
+		if section.Path != "" {
+			if fieldInfo.Positional {
+				return fmt.Errorf("positional field %s cannot be nested in section %s", field.Name, section.Path)
+			}
+			
+			fieldInfo.Section = section.Path
+			fieldInfo.Path = section.Path + "." + fieldInfo.Long
+			if section.Prefix != "" {
+				fieldInfo.Long = section.Prefix + "-" + fieldInfo.Long
+			} else {
+				fieldInfo.Long = fieldInfo.Path
+			}
+			if fieldInfo.Environment != "" {
+				fieldInfo.Environment = section.EnvPrefix + "_" + fieldInfo.Environment
+			}
+		}
+		
 		metadata.Fields = append(metadata.Fields, *fieldInfo)
 		
 		// Build lookup maps
@@ -87,9 +342,12 @@ func (a *Analyzer) Analyze(structType reflect.Type) (*StructMetadata, error) {
 			metadata.Positional = append(metadata.Positional, fieldInfo)
 		} else {
 			metadata.FieldMap[fieldInfo.Long] = fieldInfo
+			if fieldInfo.Path != fieldInfo.Long {
+				metadata.FieldMap[fieldInfo.Path] = fieldInfo
+			}
 			if fieldInfo.Short != "" {
 				if _, exists := metadata.ShortMap[fieldInfo.Short]; exists {
-					return nil, fmt.Errorf("duplicate short flag: -%s", fieldInfo.Short)
+					return fmt.Errorf("duplicate short flag: -%s", fieldInfo.Short)
 				}
 				metadata.ShortMap[fieldInfo.Short] = fieldInfo
 			}
@@ -100,7 +358,91 @@ func (a *Analyzer) Analyze(structType reflect.Type) (*StructMetadata, error) {
 		}
 	}
 	
-	return metadata, nil
+	return nil
+}
+
+// parseSection extracts SectionInfo from a struct field tagged as "short,name,description,flags".
+// Supported flags are prefix=NAME (flags become --NAME-field instead of --section.field)
+// and env=NAME (environment variables become NAME_FIELD instead of SECTION_FIELD).
+func (a *Analyzer) parseSection(field reflect.StructField, parent *SectionInfo, index []int) (*SectionInfo, error) {
+	parts := strings.Split(field.Tag.Get(a.tagName), ",")
+	
+	name := strings.ToLower(field.Name)
+	if len(parts) > 1 && parts[1] != "" {
+		name = parts[1]
+	}
+	
+	if parts[0] != "" {
+		return nil, fmt.Errorf("section %s cannot have a short flag", name)
+	}
+	
+	section := &SectionInfo{
+		Name:      name,
+		Path:      name,
+		EnvPrefix: envName(name),
+		Index:     index,
+	}
+	
+	if len(parts) > 2 {
+		section.Description = parts[2]
+	}
+	
+	if len(parts) > 3 {
+		for _, flag := range strings.Split(parts[3], "|") {
+			flag = strings.TrimSpace(flag)
+			switch {
+			case flag == "":
+			case strings.HasPrefix(flag, "prefix="):
+				section.Prefix = strings.TrimPrefix(flag, "prefix=")
+			case strings.HasPrefix(flag, "env="):
+				section.EnvPrefix = strings.TrimPrefix(flag, "env=")
+			default:
+				return nil, fmt.Errorf("unknown section flag: %s", flag)
+			}
+		}
+	}
+	
+	// Nested sections extend their parent's path and prefixes
+	if parent.Path != "" {
+		section.Path = parent.Path + "." + name
+		if section.Prefix == "" && parent.Prefix != "" {
+			section.Prefix = parent.Prefix + "-" + name
+		} else if section.Prefix != "" && parent.Prefix != "" {
+			section.Prefix = parent.Prefix + "-" + section.Prefix
+		}
+		section.EnvPrefix = parent.EnvPrefix + "_" + section.EnvPrefix
+	}
+	
+	return section, nil
+}
+
+// IsSectionType reports whether a field type is a nested struct mapped to a section
+func IsSectionType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// IsListType reports whether a field type holds several values, such as []string. Value
+// types such as net.IP are slices too, but parse themselves from a single value.
+func IsListType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// IsInjected reports whether a field is set to a service, tagged inject, rather than
+// from flags and config files
+func IsInjected(field reflect.StructField) bool {
+	_, inject := field.Tag.Lookup("inject")
+	return inject
+}
+
+// envName converts a section name to its environment variable form
+func envName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
 }
 
 // parseField extracts FieldInfo from a reflect.StructField
@@ -134,9 +476,9 @@ func (a *Analyzer) parseField(field reflect.StructField) (*FieldInfo, error) {
 		info.Description = parts[2]
 	}
 	
-	// Flags
+	// Flags, which may contain commas inside a pattern
 	if len(parts) > 3 {
-		flagStr := parts[3]
+		flagStr := strings.Join(parts[3:], ",")
 		if err := a.parseFlags(info, flagStr); err != nil {
 			return nil, err
 		}
@@ -154,7 +496,7 @@ func (a *Analyzer) parseField(field reflect.StructField) (*FieldInfo, error) {
 func (a *Analyzer) parseFlags(info *FieldInfo, flagStr string) error {
 	flags := strings.Split(flagStr, "|")
 	
-	for _, flag := range flags {
+	for i, flag := range flags {
 		flag = strings.TrimSpace(flag)
 		
 		switch {
@@ -164,13 +506,41 @@ func (a *Analyzer) parseFlags(info *FieldInfo, flagStr string) error {
 			info.Hidden = true
 		case flag == "positional":
 			info.Positional = true
+		case flag == "passthrough":
+			info.Positional = true
+			info.Passthrough = true
+		case flag == "secret":
+			info.Secret = true
 		case strings.HasPrefix(flag, "default="):
 			info.Default = strings.TrimPrefix(flag, "default=")
 		case strings.HasPrefix(flag, "env="):
 			info.Environment = strings.TrimPrefix(flag, "env=")
+		case strings.HasPrefix(flag, "optional="):
+			info.Optional = true
+			info.OptionalValue = strings.TrimPrefix(flag, "optional=")
 		case strings.HasPrefix(flag, "choices="):
 			choicesStr := strings.TrimPrefix(flag, "choices=")
 			info.Choices = strings.Split(choicesStr, ";")
+		case strings.HasPrefix(flag, "min="), strings.HasPrefix(flag, "max="):
+			name, value, _ := strings.Cut(flag, "=")
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("field %s has invalid %s: %s", info.Name, name, value)
+			}
+			if name == "min" {
+				info.Min = &bound
+			} else {
+				info.Max = &bound
+			}
+		case strings.HasPrefix(flag, "pattern="):
+			// The pattern is the last flag and may itself contain '|'
+			info.Pattern = strings.TrimPrefix(strings.Join(flags[i:], "|"), "pattern=")
+			compiled, err := regexp.Compile(info.Pattern)
+			if err != nil {
+				return fmt.Errorf("field %s has invalid pattern: %w", info.Name, err)
+			}
+			info.pattern = compiled
+			return nil
 		default:
 			return fmt.Errorf("unknown flag: %s", flag)
 		}
@@ -179,11 +549,47 @@ func (a *Analyzer) parseFlags(info *FieldInfo, flagStr string) error {
 	return nil
 }
 
+// CheckValue checks a value of the field against its min, max and pattern rules.
+// Numbers are bounded by value, strings and slices by length.
+func (f *FieldInfo) CheckValue(value reflect.Value) error {
+	var size float64
+	unit := ""
+	
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = value.Float()
+	case reflect.String:
+		size = float64(len(value.String()))
+		unit = " characters"
+		if f.pattern != nil && !f.pattern.MatchString(value.String()) {
+			return fmt.Errorf("must match pattern %s", f.Pattern)
+		}
+	case reflect.Slice:
+		size = float64(value.Len())
+		unit = " items"
+	default:
+		return nil
+	}
+	
+	if f.Min != nil && size < *f.Min {
+		return fmt.Errorf("must be at least %s%s", strconv.FormatFloat(*f.Min, 'f', -1, 64), unit)
+	}
+	if f.Max != nil && size > *f.Max {
+		return fmt.Errorf("must be at most %s%s", strconv.FormatFloat(*f.Max, 'f', -1, 64), unit)
+	}
+	
+	return nil
+}
+
 // validateField validates the field configuration
 func (a *Analyzer) validateField(info *FieldInfo) error {
-	// Positional fields can't have short/long flags
-	if info.Positional && (info.Short != "" || info.Long != strings.ToLower(info.Name)) {
-		return fmt.Errorf("positional field %s cannot have short/long flags", info.Name)
+	// Positional fields can't have short flags, the long name names them in usage
+	if info.Positional && info.Short != "" {
+		return fmt.Errorf("positional field %s cannot have a short flag", info.Name)
 	}
 	
 	// Required validation
@@ -191,6 +597,26 @@ func (a *Analyzer) validateField(info *FieldInfo) error {
 		return fmt.Errorf("field %s cannot be both required and have a default", info.Name)
 	}
 	
+	if info.Min != nil && info.Max != nil && *info.Min > *info.Max {
+		return fmt.Errorf("field %s has min greater than max", info.Name)
+	}
+	
+	if info.Pattern != "" && info.Type.Kind() != reflect.String {
+		return fmt.Errorf("pattern on non-string field %s", info.Name)
+	}
+	
+	if info.Optional && (info.Positional || info.Type.Kind() == reflect.Bool) {
+		return fmt.Errorf("optional value on positional or boolean field %s", info.Name)
+	}
+	
+	if info.Passthrough && info.Type != reflect.TypeOf([]string(nil)) {
+		return fmt.Errorf("passthrough field %s must be a []string", info.Name)
+	}
+	
+	if info.Secret && info.Type.Kind() != reflect.String {
+		return fmt.Errorf("secret field %s must be a string", info.Name)
+	}
+	
 	// Type-specific validation
 	switch info.Type.Kind() {
 	case reflect.Bool:
@@ -198,7 +624,7 @@ func (a *Analyzer) validateField(info *FieldInfo) error {
 			return fmt.Errorf("boolean field %s has invalid default: %s", info.Name, info.Default)
 		}
 	case reflect.Slice:
-		if !info.Positional {
+		if !info.Positional && IsListType(info.Type) {
 			return fmt.Errorf("slice field %s must be positional", info.Name)
 		}
 	}
@@ -249,7 +675,7 @@ func (b *Binder) BindValues(target any, values map[string]any, positional []stri
 			continue // Skip unknown flags
 		}
 		
-		field := targetStruct.FieldByName(fieldInfo.Name)
+		field := targetStruct.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() || !field.CanSet() {
 			continue
 		}
@@ -261,16 +687,16 @@ func (b *Binder) BindValues(target any, values map[string]any, positional []stri
 	
 	// Set positional values
 	for i, fieldInfo := range metadata.Positional {
-		field := targetStruct.FieldByName(fieldInfo.Name)
+		field := targetStruct.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() || !field.CanSet() {
 			continue
 		}
 		
 		// Handle slice types (remaining arguments)
-		if fieldInfo.Type.Kind() == reflect.Slice {
-			remaining := positional[i:]
+		if IsListType(fieldInfo.Type) {
+			remaining := positional[min(i, len(positional)):]
 			if err := b.setSliceValue(field, fieldInfo.Type, remaining); err != nil {
-				return fmt.Errorf("error setting positional field %s: %w", fieldInfo.Name, err)
+				return fmt.Errorf("invalid value for argument %s: %w", fieldInfo.Usage(), err)
 			}
 			break
 		}
@@ -278,17 +704,28 @@ func (b *Binder) BindValues(target any, values map[string]any, positional []stri
 		// Handle single positional argument
 		if i < len(positional) {
 			if err := b.setValue(field, fieldInfo.Type, positional[i]); err != nil {
-				return fmt.Errorf("error setting positional field %s: %w", fieldInfo.Name, err)
+				return fmt.Errorf("invalid value %q for argument %s: %w", positional[i], fieldInfo.Usage(), err)
 			}
 		}
 	}
 	
-	// Apply defaults
-	if err := b.applyDefaults(targetStruct, metadata); err != nil {
+	return nil
+}
+
+// ApplyDefaults sets the tag default of every field that has the zero value. Defaults
+// are applied before any other source, so a source can still set a field to zero.
+func (b *Binder) ApplyDefaults(target any) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a pointer to struct")
+	}
+	
+	metadata, err := b.analyzer.Analyze(targetValue.Elem().Type())
+	if err != nil {
 		return err
 	}
 	
-	return nil
+	return b.applyDefaults(targetValue.Elem(), metadata)
 }
 
 // setValue sets a single value on a reflect.Value
@@ -342,8 +779,41 @@ func (b *Binder) convertValue(value any, targetType reflect.Type) (any, error) {
 	return nil, fmt.Errorf("cannot convert %T to %s", value, targetType)
 }
 
+// ConvertString converts a string to the target type using the same rules as flag binding.
+// Slice types accept comma-separated values.
+func (b *Binder) ConvertString(value string, targetType reflect.Type) (any, error) {
+	if !IsListType(targetType) {
+		return b.convertFromString(value, targetType)
+	}
+	
+	items := strings.Split(value, ",")
+	slice := reflect.MakeSlice(targetType, len(items), len(items))
+	for i, item := range items {
+		converted, err := b.convertFromString(strings.TrimSpace(item), targetType.Elem())
+		if err != nil {
+			return nil, err
+		}
+		slice.Index(i).Set(reflect.ValueOf(converted).Convert(targetType.Elem()))
+	}
+	
+	return slice.Interface(), nil
+}
+
 // convertFromString converts string values to target types
 func (b *Binder) convertFromString(value string, targetType reflect.Type) (any, error) {
+	// Value types such as net.IP or time.Time parse themselves
+	if reflect.PointerTo(targetType).Implements(textUnmarshalerType) {
+		ptr := reflect.New(targetType)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	
+	if targetType == durationType {
+		return time.ParseDuration(value)
+	}
+	
 	switch targetType.Kind() {
 	case reflect.String:
 		return value, nil
@@ -394,7 +864,7 @@ func (b *Binder) applyDefaults(targetStruct reflect.Value, metadata *StructMetad
 			continue
 		}
 		
-		field := targetStruct.FieldByName(fieldInfo.Name)
+		field := targetStruct.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() || !field.CanSet() {
 			continue
 		}
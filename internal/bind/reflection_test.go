@@ -0,0 +1,264 @@
+package bind
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDatabase struct {
+	Host string `posix:",host,Database host,env=HOST"`
+	Port int    `posix:",port,Database port"`
+}
+
+type CommonFlags struct {
+	Verbose bool `posix:"v,verbose,Verbose"`
+}
+
+type testConfig struct {
+	CommonFlags
+	Name     string       `posix:"n,name,Name,env=NAME"`
+	Since    time.Time    `posix:",since,Since"`
+	Database testDatabase `posix:",database,Database settings"`
+}
+
+func TestAnalyzeSections(t *testing.T) {
+	metadata, err := NewAnalyzer("posix").Analyze(reflect.TypeOf(testConfig{}))
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	
+	tests := []struct {
+		name        string
+		long        string
+		path        string
+		environment string
+		index       []int
+		section     string
+	}{
+		{name: "Verbose", long: "verbose", path: "verbose", index: []int{0, 0}},
+		{name: "Name", long: "name", path: "name", environment: "NAME", index: []int{1}},
+		{name: "Since", long: "since", path: "since", index: []int{2}},
+		{name: "Host", long: "database.host", path: "database.host", environment: "DATABASE_HOST", index: []int{3, 0}, section: "database"},
+		{name: "Port", long: "database.port", path: "database.port", index: []int{3, 1}, section: "database"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, exists := metadata.FieldMap[tt.long]
+			if !exists {
+				t.Fatalf("no field with long name %s", tt.long)
+			}
+			if field.Name != tt.name || field.Path != tt.path || field.Environment != tt.environment || field.Section != tt.section {
+				t.Errorf("field = %s path=%s env=%s section=%s, want %s path=%s env=%s section=%s",
+					field.Name, field.Path, field.Environment, field.Section, tt.name, tt.path, tt.environment, tt.section)
+			}
+			if !reflect.DeepEqual(field.Index, tt.index) {
+				t.Errorf("field index = %v, want %v", field.Index, tt.index)
+			}
+		})
+	}
+	
+	if len(metadata.Sections) != 1 || metadata.Sections[0].Path != "database" || metadata.Sections[0].Description != "Database settings" {
+		t.Errorf("sections = %+v, want one database section", metadata.Sections)
+	}
+}
+
+func TestIsSectionType(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want bool
+	}{
+		{name: "struct", typ: reflect.TypeOf(testDatabase{}), want: true},
+		{name: "time.Time", typ: reflect.TypeOf(time.Time{}), want: false},
+		{name: "duration", typ: reflect.TypeOf(time.Duration(0)), want: false},
+		{name: "string", typ: reflect.TypeOf(""), want: false},
+		{name: "pointer", typ: reflect.TypeOf(&testDatabase{}), want: false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSectionType(tt.typ); got != tt.want {
+				t.Errorf("IsSectionType(%v) = %v, want %v", tt.typ, got, tt.want)
+			}
+		})
+	}
+}
+
+type copyConfig struct {
+	Verbose bool     `posix:"v,verbose,Verbose"`
+	Src     string   `posix:",src,Source file,positional|required"`
+	Dst     string   `posix:",dst,Destination,positional|required"`
+	Files   []string `posix:",files,More sources,positional|max=2"`
+}
+
+type typedConfig struct {
+	Count   int           `posix:",count,Count,positional|required"`
+	Wait    time.Duration `posix:",wait,Wait,positional"`
+	Started time.Time     `posix:",started,Started,positional"`
+	Addr    net.IP        `posix:",addr,Address,positional"`
+}
+
+func TestPositionalArity(t *testing.T) {
+	type noArgs struct {
+		Verbose bool `posix:"v,verbose,Verbose"`
+	}
+	type atLeastOne struct {
+		Files []string `posix:",files,Files,positional|required"`
+	}
+	type bounded struct {
+		Name  string   `posix:",name,Name,positional"`
+		Files []string `posix:",files,Files,positional|min=2|max=3"`
+	}
+	
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantMin int
+		wantMax int
+		usage   []string
+	}{
+		{name: "no positionals", typ: reflect.TypeOf(noArgs{}), wantMin: 0, wantMax: 0},
+		{name: "required and bounded slice", typ: reflect.TypeOf(copyConfig{}), wantMin: 2, wantMax: 4, usage: []string{"<src>", "<dst>", "[files...]"}},
+		{name: "required slice", typ: reflect.TypeOf(atLeastOne{}), wantMin: 1, wantMax: -1, usage: []string{"<files>..."}},
+		{name: "min and max", typ: reflect.TypeOf(bounded{}), wantMin: 2, wantMax: 4, usage: []string{"[name]", "<files>..."}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := NewAnalyzer("posix").Analyze(tt.typ)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			
+			gotMin, gotMax := metadata.PositionalArity()
+			if gotMin != tt.wantMin || gotMax != tt.wantMax {
+				t.Errorf("PositionalArity() = %d, %d, want %d, %d", gotMin, gotMax, tt.wantMin, tt.wantMax)
+			}
+			
+			var usage []string
+			for _, field := range metadata.Positional {
+				usage = append(usage, field.Usage())
+			}
+			if !reflect.DeepEqual(usage, tt.usage) {
+				t.Errorf("Usage() = %q, want %q", usage, tt.usage)
+			}
+		})
+	}
+}
+
+func TestCheckPositional(t *testing.T) {
+	type noArgs struct {
+		Verbose bool `posix:"v,verbose,Verbose"`
+	}
+	type bounded struct {
+		Files []string `posix:",files,Files,positional|min=2"`
+	}
+	
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		args    []string
+		wantErr string
+	}{
+		{name: "exact", typ: reflect.TypeOf(copyConfig{}), args: []string{"a", "b"}},
+		{name: "with optional", typ: reflect.TypeOf(copyConfig{}), args: []string{"a", "b", "c", "d"}},
+		{name: "missing first", typ: reflect.TypeOf(copyConfig{}), args: nil, wantErr: "missing argument <src>"},
+		{name: "missing second", typ: reflect.TypeOf(copyConfig{}), args: []string{"a"}, wantErr: "missing argument <dst>"},
+		{name: "too many", typ: reflect.TypeOf(copyConfig{}), args: []string{"a", "b", "c", "d", "e"}, wantErr: `unexpected argument "e", expected at most 4`},
+		{name: "no arguments taken", typ: reflect.TypeOf(noArgs{}), args: []string{"x"}, wantErr: `unexpected argument "x", the command takes no arguments`},
+		{name: "value type slices take one argument", typ: reflect.TypeOf(typedConfig{}), args: []string{"3", "1s", "2024-05-01T12:00:00Z", "10.0.0.1", "x"}, wantErr: `unexpected argument "x", expected at most 4`},
+		{name: "slice minimum", typ: reflect.TypeOf(bounded{}), args: []string{"a"}, wantErr: "missing argument <files>..., expected at least 2"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := NewAnalyzer("posix").Analyze(tt.typ)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			
+			err = metadata.CheckPositional(tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckPositional(%q) error = %v", tt.args, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("CheckPositional(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPositionalFieldRules(t *testing.T) {
+	type sliceNotLast struct {
+		Files []string `posix:",files,Files,positional"`
+		Name  string   `posix:",name,Name,positional"`
+	}
+	type requiredAfterOptional struct {
+		Name string `posix:",name,Name,positional"`
+		Dst  string `posix:",dst,Destination,positional|required"`
+	}
+	type shortName struct {
+		Name string `posix:"n,name,Name,positional"`
+	}
+	
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantErr string
+	}{
+		{name: "slice not last", typ: reflect.TypeOf(sliceNotLast{}), wantErr: "slice positional field Files must be the last positional field"},
+		{name: "required after optional", typ: reflect.TypeOf(requiredAfterOptional{}), wantErr: "required positional field Dst cannot follow optional Name"},
+		{name: "short flag", typ: reflect.TypeOf(shortName{}), wantErr: "positional field Name cannot have a short flag"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAnalyzer("posix").Analyze(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Analyze() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindTypedPositionals(t *testing.T) {
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	
+	tests := []struct {
+		name    string
+		args    []string
+		want    typedConfig
+		wantErr string
+	}{
+		{name: "all types", args: []string{"3", "1m30s", "2024-05-01T12:00:00Z", "10.0.0.1"}, want: typedConfig{Count: 3, Wait: 90 * time.Second, Started: started, Addr: net.ParseIP("10.0.0.1")}},
+		{name: "optional left out", args: []string{"3"}, want: typedConfig{Count: 3}},
+		{name: "invalid int", args: []string{"three"}, wantErr: `invalid value "three" for argument <count>`},
+		{name: "invalid duration", args: []string{"3", "soon"}, wantErr: `invalid value "soon" for argument [wait]`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got typedConfig
+			err := NewBinder("posix").BindValues(&got, map[string]any{}, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("BindValues(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BindValues(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BindValues(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
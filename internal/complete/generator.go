@@ -2,11 +2,14 @@ package complete
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 
 	"github.com/eugener/clix/core"
 	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/posix"
 )
 
 // CompletionType represents the type of completion
@@ -29,24 +32,43 @@ type CompletionItem struct {
 
 // Generator generates shell completions
 type Generator struct {
-	registry *core.Registry
-	analyzer *bind.Analyzer
+	registry  *core.Registry
+	analyzer  *bind.Analyzer
+	argsFiles bool
+	external  func() map[string]string
 }
 
 // NewGenerator creates a new completion generator
 func NewGenerator(registry *core.Registry) *Generator {
 	return &Generator{
-		registry: registry,
-		analyzer: bind.NewAnalyzer("posix"),
+		registry:  registry,
+		analyzer:  bind.NewAnalyzer("posix"),
+		argsFiles: true,
 	}
 }
 
+// SetArgsFiles enables or disables completing file names after @ for args files
+func (g *Generator) SetArgsFiles(enabled bool) {
+	g.argsFiles = enabled
+}
+
+// SetExternalCommands sets a source of commands that are not registered, such as
+// plugins, by name with their descriptions
+func (g *Generator) SetExternalCommands(commands func() map[string]string) {
+	g.external = commands
+}
+
 // Complete generates completions for the given command line
 func (g *Generator) Complete(args []string, cursorPos int) ([]CompletionItem, error) {
 	if len(args) == 0 {
 		return g.completeCommands(""), nil
 	}
 	
+	// An argument starting with @ names an args file
+	if last := args[len(args)-1]; g.argsFiles && posix.IsArgsFile(last) {
+		return g.completeArgsFiles(last), nil
+	}
+	
 	// If we're completing the first argument, complete commands
 	if len(args) == 1 {
 		return g.completeCommands(args[0]), nil
@@ -59,6 +81,38 @@ func (g *Generator) Complete(args []string, cursorPos int) ([]CompletionItem, er
 	return g.completeForCommand(commandName, commandArgs, cursorPos)
 }
 
+// completeArgsFiles returns the files and directories matching an @file argument
+func (g *Generator) completeArgsFiles(arg string) []CompletionItem {
+	prefix := strings.TrimPrefix(arg, posix.ArgsFilePrefix)
+	dir, base := filepath.Split(prefix)
+	
+	listDir := dir
+	if listDir == "" {
+		listDir = "."
+	}
+	entries, err := os.ReadDir(listDir)
+	if err != nil {
+		return nil
+	}
+	
+	var items []CompletionItem
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		
+		item := CompletionItem{Value: posix.ArgsFilePrefix + dir + name, Type: CompletionFiles}
+		if entry.IsDir() {
+			item.Value += string(filepath.Separator)
+			item.Type = CompletionDirectories
+		}
+		items = append(items, item)
+	}
+	
+	return items
+}
+
 // completeCommands returns command completions
 func (g *Generator) completeCommands(prefix string) []CompletionItem {
 	var items []CompletionItem
@@ -73,6 +127,18 @@ func (g *Generator) completeCommands(prefix string) []CompletionItem {
 		}
 	}
 	
+	if g.external != nil {
+		for name, desc := range g.external() {
+			if strings.HasPrefix(name, prefix) {
+				items = append(items, CompletionItem{
+					Value:       name,
+					Description: desc,
+					Type:        CompletionCommands,
+				})
+			}
+		}
+	}
+	
 	return items
 }
 
@@ -0,0 +1,296 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/eugener/clix/internal/bind"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an editable configuration file. YAML documents keep their
+// comments and key order; JSON documents are rewritten with sorted keys.
+type Document struct {
+	path string
+	root *yaml.Node
+}
+
+// OpenDocument reads the configuration file at path for editing.
+// A missing file yields an empty document that is created on Save.
+func OpenDocument(path string) (*Document, error) {
+	doc := &Document{path: path}
+	
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	
+	// YAML is a superset of JSON, so both formats parse into a node tree
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	
+	if root.Kind == 0 {
+		root = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	
+	if root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping", path)
+	}
+	
+	doc.root = &root
+	return doc, nil
+}
+
+// Path returns the file path of the document
+func (d *Document) Path() string {
+	return d.path
+}
+
+// Get returns the raw value stored at the dotted key
+func (d *Document) Get(key string) (any, bool, error) {
+	node, _, _ := d.find(strings.Split(key, "."), false)
+	if node == nil {
+		return nil, false, nil
+	}
+	
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, false, fmt.Errorf("%s: %s: %w", d.path, key, err)
+	}
+	
+	return value, true, nil
+}
+
+// Set stores a value at the dotted key, creating sections as needed
+func (d *Document) Set(key string, value any) error {
+	parts := strings.Split(key, ".")
+	
+	parent, err := d.section(parts[:len(parts)-1])
+	if err != nil {
+		return err
+	}
+	
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("cannot encode value for %s: %w", key, err)
+	}
+	
+	name := parts[len(parts)-1]
+	for i := 0; i < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == name {
+			// Keep comments attached to the previous value
+			valueNode.HeadComment = parent.Content[i+1].HeadComment
+			valueNode.LineComment = parent.Content[i+1].LineComment
+			valueNode.FootComment = parent.Content[i+1].FootComment
+			parent.Content[i+1] = &valueNode
+			return nil
+		}
+	}
+	
+	parent.Content = append(parent.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+		&valueNode,
+	)
+	return nil
+}
+
+// Unset removes the dotted key, reporting whether it was present
+func (d *Document) Unset(key string) bool {
+	node, parent, index := d.find(strings.Split(key, "."), true)
+	if node == nil {
+		return false
+	}
+	
+	// Keep comments around the key, e.g. a file header above the first key
+	keyNode := parent.Content[index]
+	hasPrevious, hasNext := index > 0, index+2 < len(parent.Content)
+	switch {
+	case hasNext:
+		next := parent.Content[index+2]
+		next.HeadComment = joinComments(keyNode.HeadComment, next.HeadComment)
+	case hasPrevious:
+		previous := parent.Content[index-2]
+		previous.FootComment = joinComments(previous.FootComment, keyNode.HeadComment)
+	}
+	switch {
+	case hasPrevious:
+		previous := parent.Content[index-2]
+		previous.FootComment = joinComments(previous.FootComment, keyNode.FootComment)
+	case hasNext:
+		next := parent.Content[index+2]
+		next.HeadComment = joinComments(keyNode.FootComment, next.HeadComment)
+	}
+	
+	parent.Content = append(parent.Content[:index], parent.Content[index+2:]...)
+	return true
+}
+
+// joinComments joins two comment blocks, either of which may be empty
+func joinComments(first, second string) string {
+	if first == "" || second == "" {
+		return first + second
+	}
+	return first + "\n" + second
+}
+
+// Save writes the document back to its file
+func (d *Document) Save() error {
+	var buf bytes.Buffer
+	
+	switch strings.ToLower(filepath.Ext(d.path)) {
+	case ".json":
+		var data any
+		if err := d.root.Decode(&data); err != nil {
+			return err
+		}
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		buf.WriteByte('\n')
+	default:
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d.root); err != nil {
+			return err
+		}
+		encoder.Close()
+	}
+	
+	return WriteFileAtomic(d.path, buf.Bytes())
+}
+
+// find locates the value node of a key along with its parent mapping and key index
+func (d *Document) find(parts []string, withParent bool) (*yaml.Node, *yaml.Node, int) {
+	current := d.root.Content[0]
+	
+	for depth, part := range parts {
+		if current.Kind != yaml.MappingNode {
+			return nil, nil, 0
+		}
+		
+		found := false
+		for i := 0; i < len(current.Content); i += 2 {
+			if current.Content[i].Value != part {
+				continue
+			}
+			if depth == len(parts)-1 {
+				return current.Content[i+1], current, i
+			}
+			current = current.Content[i+1]
+			found = true
+			break
+		}
+		
+		if !found {
+			return nil, nil, 0
+		}
+	}
+	
+	return nil, nil, 0
+}
+
+// section returns the mapping node for a section path, creating missing sections
+func (d *Document) section(parts []string) (*yaml.Node, error) {
+	current := d.root.Content[0]
+	
+	for depth, part := range parts {
+		var next *yaml.Node
+		for i := 0; i < len(current.Content); i += 2 {
+			if current.Content[i].Value == part {
+				next = current.Content[i+1]
+				break
+			}
+		}
+		
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			current.Content = append(current.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part},
+				next,
+			)
+		}
+		
+		if next.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a section", strings.Join(parts[:depth+1], "."))
+		}
+		
+		current = next
+	}
+	
+	return current, nil
+}
+
+// WriteFileAtomic replaces the file at path with content, keeping its permissions
+func WriteFileAtomic(path string, content []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	
+	return os.Rename(tmp.Name(), path)
+}
+
+// ResolveKey maps a dotted config key onto a field of structType, returning the
+// field index path and type. Keys follow the same naming rules as loading.
+func (l *Loader) ResolveKey(structType reflect.Type, key string) ([]int, reflect.Type, error) {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	var index []int
+	current := structType
+	parts := strings.Split(key, ".")
+	
+	for depth, part := range parts {
+		if !bind.IsSectionType(current) {
+			return nil, nil, fmt.Errorf("%s is not a section", strings.Join(parts[:depth], "."))
+		}
+		
+		mapping, exists := l.buildFieldMapping(current)[part]
+		if !exists {
+			return nil, nil, fmt.Errorf("unknown key %s", strings.Join(parts[:depth+1], "."))
+		}
+		
+		index = append(index, mapping.Index...)
+		current = mapping.Type
+	}
+	
+	if bind.IsSectionType(current) {
+		return nil, nil, fmt.Errorf("%s is a section, not a value", key)
+	}
+	
+	return index, current, nil
+}
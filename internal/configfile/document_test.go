@@ -0,0 +1,191 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		ext     string
+		edit    func(d *Document) error
+		want    string
+		wantErr string
+	}{
+		{
+			name: "set keeps comments and order",
+			file: "# service\nname: api # the name\nregion: eu\n",
+			edit: func(d *Document) error { return d.Set("name", "web") },
+			want: "# service\nname: web # the name\nregion: eu\n",
+		},
+		{
+			name: "set creates sections",
+			file: "name: api\n",
+			edit: func(d *Document) error { return d.Set("commands.deploy.replicas", 3) },
+			want: "name: api\ncommands:\n  deploy:\n    replicas: 3\n",
+		},
+		{
+			name: "set in a missing file",
+			edit: func(d *Document) error { return d.Set("name", "api") },
+			want: "name: api\n",
+		},
+		{
+			name:    "set below a value",
+			file:    "name: api\n",
+			edit:    func(d *Document) error { return d.Set("name.first", "x") },
+			wantErr: "name is not a section",
+		},
+		{
+			name: "unset",
+			file: "name: api\ndatabase:\n  host: db\n  port: 5432\n",
+			edit: func(d *Document) error {
+				if !d.Unset("database.host") {
+					t.Error("Unset() = false, want true")
+				}
+				if d.Unset("database.missing") {
+					t.Error("Unset() = true for a missing key")
+				}
+				return nil
+			},
+			want: "name: api\ndatabase:\n  port: 5432\n",
+		},
+		{
+			name: "unset keeps comments",
+			file: "# tool config\nname: api # the name\nregion: eu\ndatabase:\n  host: db\n  port: 5432\n  # end of database\n# end of file\n",
+			edit: func(d *Document) error {
+				d.Unset("name")
+				d.Unset("database.port")
+				return nil
+			},
+			want: "# tool config\nregion: eu\ndatabase:\n  host: db\n  # end of database\n# end of file\n",
+		},
+		{
+			name: "unset last key keeps the file footer",
+			file: "name: api\nregion: eu\n# end of file\n",
+			edit: func(d *Document) error {
+				d.Unset("region")
+				return nil
+			},
+			want: "name: api\n# end of file\n",
+		},
+		{
+			name: "json is rewritten with sorted keys",
+			file: `{"region": "eu", "name": "api"}`,
+			ext:  ".json",
+			edit: func(d *Document) error { return d.Set("replicas", 2) },
+			want: "{\n  \"name\": \"api\",\n  \"region\": \"eu\",\n  \"replicas\": 2\n}\n",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := tt.ext
+			if ext == "" {
+				ext = ".yaml"
+			}
+			path := filepath.Join(t.TempDir(), "config"+ext)
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0600); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+			
+			doc, err := OpenDocument(path)
+			if err != nil {
+				t.Fatalf("OpenDocument() error = %v", err)
+			}
+			
+			err = tt.edit(doc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("edit error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			if err := doc.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("saved file = %q, want %q", content, tt.want)
+			}
+			
+			if tt.file != "" {
+				if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+					t.Errorf("saved file mode = %v, want 0600", info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("name: api\ndatabase:\n  port: 5432\ntags: [a, b]\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	doc, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+	
+	tests := []struct {
+		key       string
+		want      any
+		wantFound bool
+	}{
+		{key: "name", want: "api", wantFound: true},
+		{key: "database.port", want: 5432, wantFound: true},
+		{key: "tags", want: []any{"a", "b"}, wantFound: true},
+		{key: "database.host"},
+		{key: "name.first"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, found, err := doc.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if found != tt.wantFound || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestOpenDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{name: "invalid yaml", file: "name: [\n", wantErr: "failed to parse"},
+		{name: "top level list", file: "- a\n- b\n", wantErr: "top level must be a mapping"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			if _, err := OpenDocument(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("OpenDocument() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package configfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// IncludeKey is the top-level key listing files to include. Paths are relative
+// to the including file and may be glob patterns.
+const IncludeKey = "include"
+
+// readWithIncludes parses the file at path and merges its included files beneath it,
+// so the including file overrides what it includes. The stack holds the absolute
+// paths being read for cycle detection, and positions records where each dotted
+// key was read from.
+func (l *Loader) readWithIncludes(path string, stack []string, positions Positions) (map[string]any, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config file %s: %w", path, err)
+	}
+	
+	if slices.Contains(stack, absPath) {
+		return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), absPath)
+	}
+	stack = append(stack, absPath)
+	
+	data, filePositions, err := l.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Include errors point at the include directive
+	includePos := filePositions.Find(IncludeKey)
+	if includePos.File == "" {
+		includePos.File = path
+	}
+	
+	patterns, err := includePatterns(data[IncludeKey])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", includePos, err)
+	}
+	delete(data, IncludeKey)
+	
+	merged := make(map[string]any)
+	
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include pattern %s: %w", includePos, pattern, err)
+		}
+		
+		// A plain path must exist, a glob may match nothing
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("%s: included file %s not found", includePos, pattern)
+		}
+		
+		sort.Strings(matches)
+		for _, match := range matches {
+			included, err := l.readWithIncludes(match, stack, positions)
+			if err != nil {
+				return nil, err
+			}
+			MergeMaps(merged, included)
+		}
+	}
+	
+	positions.merge(filePositions)
+	return MergeMaps(merged, data), nil
+}
+
+// includePatterns normalizes the include directive to a list of patterns
+func includePatterns(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		patterns := make([]string, 0, len(v))
+		for _, item := range v {
+			pattern, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s entries must be strings, got %T", IncludeKey, item)
+			}
+			patterns = append(patterns, pattern)
+		}
+		return patterns, nil
+	default:
+		return nil, fmt.Errorf("%s must be a path or a list of paths, got %T", IncludeKey, value)
+	}
+}
+
+// JoinKey joins a dotted key prefix and a key
+func JoinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,169 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIncludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "including file overrides included values",
+			files: map[string]string{
+				"config.yaml": "include: base.yaml\nname: main\n",
+				"base.yaml":   "name: base\nregion: eu\n",
+			},
+			want: map[string]any{"name": "main", "region": "eu"},
+		},
+		{
+			name: "later includes override earlier ones",
+			files: map[string]string{
+				"config.yaml": "include: [a.yaml, b.yaml]\n",
+				"a.yaml":      "name: a\nregion: eu\n",
+				"b.yaml":      "name: b\n",
+			},
+			want: map[string]any{"name": "b", "region": "eu"},
+		},
+		{
+			name: "sections are merged",
+			files: map[string]string{
+				"config.yaml": "include: base.yaml\ndatabase:\n  host: main\n",
+				"base.yaml":   "database:\n  host: base\n  port: 5432\n",
+			},
+			want: map[string]any{"database": map[string]any{"host": "main", "port": 5432}},
+		},
+		{
+			name: "glob in sorted order",
+			files: map[string]string{
+				"config.yaml":   "include: conf.d/*.yaml\n",
+				"conf.d/2.yaml": "name: two\n",
+				"conf.d/1.yaml": "name: one\nregion: eu\n",
+			},
+			want: map[string]any{"name": "two", "region": "eu"},
+		},
+		{
+			name: "glob matching nothing",
+			files: map[string]string{
+				"config.yaml": "include: conf.d/*.yaml\nname: main\n",
+			},
+			want: map[string]any{"name": "main"},
+		},
+		{
+			name: "nested includes are relative to their file",
+			files: map[string]string{
+				"config.yaml":        "include: shared/base.yaml\n",
+				"shared/base.yaml":   "include: common.yaml\nname: base\n",
+				"shared/common.yaml": "region: eu\n",
+			},
+			want: map[string]any{"name": "base", "region": "eu"},
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"config.yaml": "include: missing.yaml\n",
+			},
+			wantErr: "missing.yaml not found",
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"config.yaml": "include: a.yaml\n",
+				"a.yaml":      "include: config.yaml\n",
+			},
+			wantErr: "include cycle",
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				"config.yaml": "include: config.yaml\n",
+			},
+			wantErr: "include cycle",
+		},
+		{
+			name: "invalid directive",
+			files: map[string]string{
+				"config.yaml": "include:\n  path: a.yaml\n",
+			},
+			wantErr: "config.yaml:1:1: include must be a path or a list of paths",
+		},
+		{
+			name: "invalid list entry",
+			files: map[string]string{
+				"config.yaml": "include: [1]\n",
+			},
+			wantErr: "include entries must be strings",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+			
+			got, err := NewLoader("test").ReadMap(filepath.Join(dir, "config.yaml"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadMap() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludePositions(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	config := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(base, []byte("region: eu\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(config, []byte("include: base.yaml\nname: main\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	_, positions, err := NewLoader("test").ReadMapPositions(config)
+	if err != nil {
+		t.Fatalf("ReadMapPositions() error = %v", err)
+	}
+	
+	tests := []struct {
+		key  string
+		file string
+		line int
+	}{
+		{key: "region", file: base, line: 1},
+		{key: "name", file: config, line: 2},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			pos := positions.Find(tt.key)
+			if pos.File != tt.file || pos.Line != tt.line {
+				t.Errorf("Find(%s) = %s:%d, want %s:%d", tt.key, pos.File, pos.Line, tt.file, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Interpolation references have the form ${source:name} with an optional
+// ":-fallback" suffix. Supported sources are:
+//
+//	${VAR} or ${env:VAR}   environment variable
+//	${file:path}           file contents, relative to the config file
+//	${self:other.key}      another value in the same configuration
+//
+// A literal "${" is written as "$${".
+
+// interpolator resolves ${...} references in parsed configuration data
+type interpolator struct {
+	root      map[string]any
+	positions Positions
+	resolving map[string]bool
+	resolved  map[string]bool
+}
+
+// interpolate resolves all references in data in place
+func interpolate(data map[string]any, positions Positions) error {
+	in := &interpolator{
+		root:      data,
+		positions: positions,
+		resolving: make(map[string]bool),
+		resolved:  make(map[string]bool),
+	}
+	return in.walkMap(data, "")
+}
+
+// walkMap resolves references in every value of a map
+func (in *interpolator) walkMap(data map[string]any, prefix string) error {
+	for key, value := range data {
+		path := JoinKey(prefix, key)
+		resolved, err := in.walk(value, path)
+		if err != nil {
+			return err
+		}
+		data[key] = resolved
+	}
+	return nil
+}
+
+// walk resolves references in a single value
+func (in *interpolator) walk(value any, path string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, in.walkMap(v, path)
+	case []any:
+		for i, item := range v {
+			resolved, err := in.walk(item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	case string:
+		if in.resolved[path] {
+			return v, nil
+		}
+		resolved, err := in.expand(v, path)
+		if err != nil {
+			return nil, err
+		}
+		in.resolved[path] = true
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// expand resolves the references in a string. A string consisting of a single
+// reference keeps the type of the referenced value.
+func (in *interpolator) expand(s, path string) (any, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	
+	if in.resolving[path] {
+		return nil, in.errorf(path, "reference cycle through %s", path)
+	}
+	in.resolving[path] = true
+	defer delete(in.resolving, path)
+	
+	var sb strings.Builder
+	var single any
+	parts := 0
+	
+	for rest := s; rest != ""; {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			sb.WriteString(rest)
+			parts++
+			break
+		}
+		
+		// $${ is an escaped literal
+		if start > 0 && rest[start-1] == '$' {
+			sb.WriteString(rest[:start-1])
+			sb.WriteString("${")
+			rest = rest[start+2:]
+			parts += 2
+			continue
+		}
+		
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return nil, in.errorf(path, "unterminated reference in %q", s)
+		}
+		end += start
+		
+		if start > 0 {
+			sb.WriteString(rest[:start])
+			parts++
+		}
+		
+		value, err := in.resolve(rest[start+2:end], path)
+		if err != nil {
+			return nil, err
+		}
+		single = value
+		sb.WriteString(fmt.Sprint(value))
+		parts++
+		
+		rest = rest[end+1:]
+	}
+	
+	if parts == 1 && single != nil {
+		return single, nil
+	}
+	
+	return sb.String(), nil
+}
+
+// resolve looks up a single reference body such as "env:HOST:-localhost"
+func (in *interpolator) resolve(ref, path string) (any, error) {
+	body, fallback, hasFallback := strings.Cut(ref, ":-")
+	
+	source, name, hasSource := strings.Cut(body, ":")
+	if !hasSource {
+		source, name = "env", body
+	}
+	
+	if name == "" {
+		return nil, in.errorf(path, "empty reference ${%s}", ref)
+	}
+	
+	switch source {
+	case "env":
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		if hasFallback {
+			return fallback, nil
+		}
+		return nil, in.errorf(path, "${%s}: environment variable %s is not set", ref, name)
+	
+	case "file":
+		filePath := name
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(filepath.Dir(in.positions.Find(path).File), filePath)
+		}
+		content, err := os.ReadFile(filePath)
+		if err == nil {
+			return strings.TrimRight(string(content), "\r\n"), nil
+		}
+		if hasFallback {
+			return fallback, nil
+		}
+		return nil, in.errorf(path, "${%s}: %v", ref, err)
+	
+	case "self":
+		value, found, err := in.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return value, nil
+		}
+		if hasFallback {
+			return fallback, nil
+		}
+		return nil, in.errorf(path, "${%s}: key %s is not defined", ref, name)
+	
+	default:
+		return nil, in.errorf(path, "${%s}: unknown reference source %q", ref, source)
+	}
+}
+
+// lookup finds a dotted key in the configuration, resolving its own references first
+func (in *interpolator) lookup(key string) (any, bool, error) {
+	var current any = in.root
+	parts := strings.Split(key, ".")
+	
+	for i, part := range parts {
+		section, ok := current.(map[string]any)
+		if !ok {
+			return nil, false, nil
+		}
+		value, exists := section[part]
+		if !exists {
+			return nil, false, nil
+		}
+		
+		if i == len(parts)-1 {
+			switch value.(type) {
+			case map[string]any, []any:
+				return nil, false, in.errorf(key, "referenced key %s is not a scalar value", key)
+			}
+			resolved, err := in.walk(value, key)
+			if err != nil {
+				return nil, false, err
+			}
+			section[part] = resolved
+			return resolved, true, nil
+		}
+		
+		current = value
+	}
+	
+	return nil, false, nil
+}
+
+// errorf creates an interpolation error pointing to the file and key
+func (in *interpolator) errorf(path, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if pos := in.positions.Find(path); pos.File != "" {
+		return fmt.Errorf("%s: %s: %s", pos, path, msg)
+	}
+	return fmt.Errorf("%s: %s", path, msg)
+}
@@ -0,0 +1,136 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("CLIX_TEST_HOST", "db.example.com")
+	t.Setenv("CLIX_TEST_PORT", "5432")
+	os.Unsetenv("CLIX_TEST_UNSET")
+	
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "password"), []byte("s3cret\n"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	tests := []struct {
+		name    string
+		file    string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "environment variable",
+			file: "host: ${CLIX_TEST_HOST}\n",
+			want: map[string]any{"host": "db.example.com"},
+		},
+		{
+			name: "env source inside text",
+			file: "url: postgres://${env:CLIX_TEST_HOST}:${CLIX_TEST_PORT}/app\n",
+			want: map[string]any{"url": "postgres://db.example.com:5432/app"},
+		},
+		{
+			name: "fallback",
+			file: "host: ${CLIX_TEST_UNSET:-localhost}\n",
+			want: map[string]any{"host": "localhost"},
+		},
+		{
+			name: "file relative to the config file",
+			file: "password: ${file:password}\n",
+			want: map[string]any{"password": "s3cret"},
+		},
+		{
+			name: "missing file with fallback",
+			file: "password: ${file:missing:-none}\n",
+			want: map[string]any{"password": "none"},
+		},
+		{
+			name: "self reference keeps the value type",
+			file: "database:\n  port: 5432\nport: ${self:database.port}\nurl: db:${self:database.port}\n",
+			want: map[string]any{"database": map[string]any{"port": 5432}, "port": 5432, "url": "db:5432"},
+		},
+		{
+			name: "chained self references",
+			file: "a: ${self:b}\nb: ${self:c}\nc: value\n",
+			want: map[string]any{"a": "value", "b": "value", "c": "value"},
+		},
+		{
+			name: "references in lists",
+			file: "hosts:\n  - ${CLIX_TEST_HOST}\n  - other\n",
+			want: map[string]any{"hosts": []any{"db.example.com", "other"}},
+		},
+		{
+			name: "escaped reference",
+			file: "template: $${CLIX_TEST_HOST}\n",
+			want: map[string]any{"template": "${CLIX_TEST_HOST}"},
+		},
+		{
+			name:    "unset variable",
+			file:    "host: ${CLIX_TEST_UNSET}\n",
+			wantErr: "config.yaml:1:1: host: ${CLIX_TEST_UNSET}: environment variable CLIX_TEST_UNSET is not set",
+		},
+		{
+			name:    "missing file",
+			file:    "password: ${file:missing}\n",
+			wantErr: "password: ${file:missing}",
+		},
+		{
+			name:    "undefined self key",
+			file:    "a: ${self:b}\n",
+			wantErr: "${self:b}: key b is not defined",
+		},
+		{
+			name:    "self reference to a section",
+			file:    "database:\n  port: 1\na: ${self:database}\n",
+			wantErr: "referenced key database is not a scalar value",
+		},
+		{
+			name:    "reference cycle",
+			file:    "a: ${self:b}\nb: ${self:a}\n",
+			wantErr: "reference cycle",
+		},
+		{
+			name:    "unterminated reference",
+			file:    "a: ${CLIX_TEST_HOST\n",
+			wantErr: "unterminated reference",
+		},
+		{
+			name:    "unknown source",
+			file:    "a: ${vault:secret}\n",
+			wantErr: `unknown reference source "vault"`,
+		},
+		{
+			name:    "empty reference",
+			file:    "a: ${}\n",
+			wantErr: "empty reference",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			
+			got, err := NewLoader("test").ReadMap(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadMap() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadMap() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
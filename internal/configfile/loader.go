@@ -1,161 +1,295 @@
 package configfile
 
 import (
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/eugener/clix/internal/bind"
 	"gopkg.in/yaml.v3"
 )
 
 // Loader handles loading configuration from various file formats
 type Loader struct {
-	searchPaths []string
-	fileName    string
+	locations []SearchLocation
+	fileName  string
 }
 
-// NewLoader creates a new configuration file loader
+// NewLoader creates a new configuration file loader searching for <fileName>.* in
+// the given directories, listed from highest to lowest precedence. Without search
+// paths the XDG locations of an application named fileName are searched.
 func NewLoader(fileName string, searchPaths ...string) *Loader {
 	if len(searchPaths) == 0 {
-		// Default search paths
-		searchPaths = []string{
-			".",                    // Current directory
-			"$HOME/.config",        // User config directory
-			"/etc",                 // System config directory
-		}
+		return NewLocationLoader(fileName, DefaultLocations(fileName, fileName)...)
+	}
+	
+	locations := make([]SearchLocation, len(searchPaths))
+	for i, path := range searchPaths {
+		locations[i] = SearchLocation{Dir: path, Names: []string{fileName}}
 	}
 	
+	return NewLocationLoader(fileName, locations...)
+}
+
+// NewLocationLoader creates a configuration file loader searching the given
+// locations, listed from highest to lowest precedence
+func NewLocationLoader(fileName string, locations ...SearchLocation) *Loader {
 	return &Loader{
-		searchPaths: searchPaths,
-		fileName:    fileName,
+		locations: locations,
+		fileName:  fileName,
 	}
 }
 
 // Load loads configuration from file into the target struct
 func (l *Loader) Load(target any) error {
-	// Find configuration file
-	configPath, err := l.findConfigFile()
+	data, _, err := l.LoadMap()
 	if err != nil {
 		return err
 	}
 	
-	if configPath == "" {
+	if data == nil {
 		// No config file found, that's okay
 		return nil
 	}
 	
-	// Read file content
-	content, err := os.ReadFile(configPath)
+	return l.mapToStruct(data, target)
+}
+
+// LoadFromPath loads configuration from a specific file path
+func (l *Loader) LoadFromPath(path string, target any) error {
+	data, err := l.ReadMap(path)
 	if err != nil {
-		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+		return err
 	}
 	
-	// Determine format from file extension
-	ext := strings.ToLower(filepath.Ext(configPath))
+	return l.mapToStruct(data, target)
+}
+
+// LoadMap finds every configuration file and merges them into a generic map, files
+// with higher precedence overriding lower ones. It also returns the path of the file
+// with the highest precedence, or a nil map and an empty path when no file is found.
+func (l *Loader) LoadMap() (map[string]any, string, error) {
+	files := l.FindFiles()
+	if len(files) == 0 {
+		return nil, "", nil
+	}
+	
+	merged := make(map[string]any)
+	for _, file := range files {
+		data, err := l.ReadMap(file.Path)
+		if err != nil {
+			return nil, file.Path, err
+		}
+		MergeMaps(merged, data)
+	}
 	
-	switch ext {
-	case ".yaml", ".yml":
-		return l.loadYAML(content, target)
-	case ".json":
-		return l.loadJSON(content, target)
-	case ".toml":
-		return l.loadTOML(content, target)
-	default:
-		// Try to detect format from content
-		return l.loadWithAutoDetect(content, target)
+	return merged, files[len(files)-1].Path, nil
+}
+
+// ReadMap parses the configuration file at path into a generic map,
+// resolving include directives and ${...} interpolation
+func (l *Loader) ReadMap(path string) (map[string]any, error) {
+	data, _, err := l.ReadMapPositions(path)
+	return data, err
+}
+
+// ReadMapPositions is like ReadMap and also returns the file position of every key
+func (l *Loader) ReadMapPositions(path string) (map[string]any, Positions, error) {
+	positions := make(Positions)
+	
+	data, err := l.readWithIncludes(path, nil, positions)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	if err := interpolate(data, positions); err != nil {
+		return nil, nil, err
 	}
+	
+	return data, positions, nil
 }
 
-// LoadFromPath loads configuration from a specific file path
-func (l *Loader) LoadFromPath(path string, target any) error {
+// parseFile parses a single configuration file into a generic map and the positions of its keys
+func (l *Loader) parseFile(path string) (map[string]any, Positions, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read config file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
 	}
 	
+	var data map[string]any
+	
+	// Determine format from file extension
 	ext := strings.ToLower(filepath.Ext(path))
 	
 	switch ext {
 	case ".yaml", ".yml":
-		return l.loadYAML(content, target)
+		data, err = l.parseYAML(content)
 	case ".json":
-		return l.loadJSON(content, target)
+		data, err = l.parseJSON(content)
 	case ".toml":
-		return l.loadTOML(content, target)
+		return nil, nil, fmt.Errorf("%s: TOML support not yet implemented", path)
 	default:
-		return l.loadWithAutoDetect(content, target)
+		// Try to detect format from content
+		if data, err = l.parseJSON(content); err != nil {
+			if data, err = l.parseYAML(content); err != nil {
+				return nil, nil, fmt.Errorf("%s: unable to detect configuration file format", path)
+			}
+		}
+	}
+	
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, nil, fmt.Errorf("%s: %w", offsetPosition(content, syntaxErr.Offset, path), err)
+		}
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	
+	return data, nodePositions(content, path), nil
+}
+
+// MapToStruct maps already parsed configuration data onto the target struct
+func (l *Loader) MapToStruct(data map[string]any, target any) error {
+	return l.mapToStruct(data, target)
+}
+
+// SetFields returns the index paths of the fields of structType that data sets,
+// including fields set to zero values, sorted by index
+func (l *Loader) SetFields(data map[string]any, structType reflect.Type) [][]int {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	var fields [][]int
+	l.collectSetFields(data, structType, nil, &fields)
+	slices.SortFunc(fields, slices.Compare)
+	return slices.CompactFunc(fields, slices.Equal)
+}
+
+// collectSetFields adds the index paths of the fields set by data, descending into sections
+func (l *Loader) collectSetFields(data map[string]any, structType reflect.Type, index []int, fields *[][]int) {
+	fieldMap := l.buildFieldMapping(structType)
+	
+	for key, value := range data {
+		mapping, exists := fieldMap[key]
+		if !exists {
+			continue
+		}
+		
+		fieldIndex := append(slices.Clone(index), mapping.Index...)
+		if section, ok := value.(map[string]any); ok && bind.IsSectionType(mapping.Type) {
+			l.collectSetFields(section, mapping.Type, fieldIndex, fields)
+			continue
+		}
+		*fields = append(*fields, fieldIndex)
 	}
 }
 
-// findConfigFile searches for configuration file in search paths
-func (l *Loader) findConfigFile() (string, error) {
-	// Expand environment variables in search paths
-	expandedPaths := make([]string, len(l.searchPaths))
-	for i, path := range l.searchPaths {
-		expandedPaths[i] = os.ExpandEnv(path)
+// FindFile returns the path of the configuration file with the highest precedence,
+// or an empty string if none exists
+func (l *Loader) FindFile() (string, error) {
+	return l.findFile("")
+}
+
+// FindFiles returns every existing configuration file, at most one per search
+// location, ordered from lowest to highest precedence for merging
+func (l *Loader) FindFiles() []File {
+	var files []File
+	for i := len(l.locations) - 1; i >= 0; i-- {
+		if path := l.findInLocation(l.locations[i], ""); path != "" {
+			files = append(files, File{Path: path, Scope: l.locations[i].Scope})
+		}
+	}
+	return files
+}
+
+// SearchOrder describes the searched file patterns from lowest to highest precedence,
+// such as /etc/xdg/app/config.*
+func (l *Loader) SearchOrder() []string {
+	var patterns []string
+	for i := len(l.locations) - 1; i >= 0; i-- {
+		location := l.locations[i]
+		for _, name := range location.Names {
+			patterns = append(patterns, filepath.Join(os.ExpandEnv(location.Dir), name+".*"))
+		}
+	}
+	return patterns
+}
+
+// DefaultPath returns the path where a new configuration file is created:
+// a YAML file in the search location with the highest precedence
+func (l *Loader) DefaultPath() string {
+	dir, name := ".", l.fileName
+	if len(l.locations) > 0 {
+		dir = os.ExpandEnv(l.locations[0].Dir)
+		if len(l.locations[0].Names) > 0 {
+			name = l.locations[0].Names[0]
+		}
+	}
+	return filepath.Join(dir, name+".yaml")
+}
+
+// findFile returns the first file with the given name suffix in the search locations
+func (l *Loader) findFile(suffix string) (string, error) {
+	for _, location := range l.locations {
+		if path := l.findInLocation(location, suffix); path != "" {
+			return path, nil
+		}
 	}
 	
+	return "", nil // No file found
+}
+
+// findInLocation returns the first existing file in a location whose name is one of the
+// location's base names followed by suffix and a supported extension
+func (l *Loader) findInLocation(location SearchLocation, suffix string) string {
+	dir := os.ExpandEnv(location.Dir)
+	
 	// Try different extensions
 	extensions := []string{".yaml", ".yml", ".json", ".toml", ""}
 	
-	for _, searchPath := range expandedPaths {
+	for _, name := range location.Names {
 		for _, ext := range extensions {
-			configPath := filepath.Join(searchPath, l.fileName+ext)
-			if _, err := os.Stat(configPath); err == nil {
-				return configPath, nil
+			configPath := filepath.Join(dir, name+suffix+ext)
+			if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+				return configPath
 			}
 		}
 	}
 	
-	return "", nil // No file found
+	return ""
 }
 
-// loadYAML loads YAML configuration
-func (l *Loader) loadYAML(content []byte, target any) error {
-	// First unmarshal to a map to handle CLI-specific struct tags
+// parseYAML parses YAML configuration into a generic map
+func (l *Loader) parseYAML(content []byte) (map[string]any, error) {
 	var yamlData map[string]any
 	if err := yaml.Unmarshal(content, &yamlData); err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
-	
-	// Map YAML keys to struct fields using struct tags
-	return l.mapToStruct(yamlData, target)
+	if yamlData == nil {
+		yamlData = make(map[string]any)
+	}
+	return yamlData, nil
 }
 
-// loadJSON loads JSON configuration
-func (l *Loader) loadJSON(content []byte, target any) error {
-	// First unmarshal to a map to handle CLI-specific struct tags
+// parseJSON parses JSON configuration into a generic map
+func (l *Loader) parseJSON(content []byte) (map[string]any, error) {
 	var jsonData map[string]any
 	if err := json.Unmarshal(content, &jsonData); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
-	}
-	
-	// Map JSON keys to struct fields using struct tags
-	return l.mapToStruct(jsonData, target)
-}
-
-// loadTOML loads TOML configuration (placeholder - would need TOML library)
-func (l *Loader) loadTOML(content []byte, target any) error {
-	// For now, return an error - TOML support would require external library
-	return fmt.Errorf("TOML support not yet implemented")
-}
-
-// loadWithAutoDetect attempts to detect format and load
-func (l *Loader) loadWithAutoDetect(content []byte, target any) error {
-	// Try JSON first
-	if err := l.loadJSON(content, target); err == nil {
-		return nil
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
-	
-	// Try YAML
-	if err := l.loadYAML(content, target); err == nil {
-		return nil
+	if jsonData == nil {
+		jsonData = make(map[string]any)
 	}
-	
-	return fmt.Errorf("unable to detect configuration file format")
+	return jsonData, nil
 }
 
 // mapToStruct maps configuration data to struct fields using struct tags
@@ -186,7 +320,7 @@ func (l *Loader) mapToStruct(data map[string]any, target any) error {
 // FieldMapping contains information about struct field mapping
 type FieldMapping struct {
 	Name      string
-	Index     int
+	Index     []int // Index path of the field, through flattened embedded structs
 	Type      reflect.Type
 	JSONKey   string
 	YAMLKey   string
@@ -196,17 +330,29 @@ type FieldMapping struct {
 // buildFieldMapping builds a mapping from config keys to struct fields
 func (l *Loader) buildFieldMapping(structType reflect.Type) map[string]*FieldMapping {
 	fieldMap := make(map[string]*FieldMapping)
-	
+	l.addFieldMappings(structType, nil, fieldMap)
+	return fieldMap
+}
+
+// addFieldMappings adds the fields of structType to fieldMap. Untagged embedded
+// structs are flattened into the parent, as in flags and environment variables.
+func (l *Loader) addFieldMappings(structType reflect.Type, index []int, fieldMap map[string]*FieldMapping) {
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
 		
-		if !field.IsExported() {
+		if !field.IsExported() || bind.IsInjected(field) {
+			continue
+		}
+		
+		fieldIndex := append(slices.Clone(index), i)
+		if field.Anonymous && bind.IsSectionType(field.Type) && field.Tag.Get("posix") == "" {
+			l.addFieldMappings(field.Type, fieldIndex, fieldMap)
 			continue
 		}
 		
 		mapping := &FieldMapping{
 			Name:  field.Name,
-			Index: i,
+			Index: fieldIndex,
 			Type:  field.Type,
 		}
 		
@@ -248,25 +394,32 @@ func (l *Loader) buildFieldMapping(structType reflect.Type) map[string]*FieldMap
 			fieldMap[field.Name] = mapping
 		}
 	}
-	
-	return fieldMap
 }
 
 // setFieldValue sets a field value with type conversion
 func (l *Loader) setFieldValue(targetStruct reflect.Value, fieldInfo *FieldMapping, value any) error {
-	field := targetStruct.Field(fieldInfo.Index)
+	field := targetStruct.FieldByIndex(fieldInfo.Index)
 	
 	if !field.CanSet() {
 		return fmt.Errorf("field %s cannot be set", fieldInfo.Name)
 	}
 	
+	// Nested structs are populated from their own section
+	if bind.IsSectionType(field.Type()) {
+		section, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected a section for %s, got %T", fieldInfo.Name, value)
+		}
+		return l.mapToStruct(section, field.Addr().Interface())
+	}
+	
 	// Convert value to appropriate type
 	convertedValue, err := l.convertValue(value, fieldInfo.Type)
 	if err != nil {
 		return err
 	}
 	
-	field.Set(reflect.ValueOf(convertedValue))
+	assign(field, convertedValue)
 	return nil
 }
 
@@ -283,6 +436,15 @@ func (l *Loader) convertValue(value any, targetType reflect.Type) (any, error) {
 		return value, nil
 	}
 	
+	// Value types such as time.Time parse themselves
+	if text, ok := value.(string); ok && reflect.PointerTo(targetType).Implements(textUnmarshalerType) {
+		ptr := reflect.New(targetType)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	
 	// Handle different type conversions
 	switch targetType.Kind() {
 	case reflect.String:
@@ -309,6 +471,9 @@ func (l *Loader) convertValue(value any, targetType reflect.Type) (any, error) {
 		case float64:
 			return int64(v), nil
 		case string:
+			if targetType == reflect.TypeOf(time.Duration(0)) {
+				return time.ParseDuration(v)
+			}
 			return parseInt64(v)
 		}
 		
@@ -336,6 +501,15 @@ func (l *Loader) convertValue(value any, targetType reflect.Type) (any, error) {
 	return nil, fmt.Errorf("cannot convert %T to %s", value, targetType)
 }
 
+// assign sets dst to value, converting between compatible types such as int64 and int
+func assign(dst reflect.Value, value any) {
+	converted := reflect.ValueOf(value)
+	if converted.Type() != dst.Type() && converted.Type().ConvertibleTo(dst.Type()) {
+		converted = converted.Convert(dst.Type())
+	}
+	dst.Set(converted)
+}
+
 // convertSlice converts value to slice type
 func (l *Loader) convertSlice(value any, targetType reflect.Type) (any, error) {
 	valueReflect := reflect.ValueOf(value)
@@ -347,7 +521,7 @@ func (l *Loader) convertSlice(value any, targetType reflect.Type) (any, error) {
 		if err != nil {
 			return nil, err
 		}
-		slice.Index(0).Set(reflect.ValueOf(elem))
+		assign(slice.Index(0), elem)
 		return slice.Interface(), nil
 	}
 	
@@ -358,7 +532,7 @@ func (l *Loader) convertSlice(value any, targetType reflect.Type) (any, error) {
 		if err != nil {
 			return nil, err
 		}
-		slice.Index(i).Set(reflect.ValueOf(elem))
+		assign(slice.Index(i), elem)
 	}
 	
 	return slice.Interface(), nil
@@ -435,7 +609,7 @@ func (cg *ConfigGenerator) generateExampleStruct(structType reflect.Type) map[st
 	for i := 0; i < structType.NumField(); i++ {
 		field := structType.Field(i)
 		
-		if !field.IsExported() {
+		if !field.IsExported() || bind.IsInjected(field) {
 			continue
 		}
 		
@@ -445,6 +619,16 @@ func (cg *ConfigGenerator) generateExampleStruct(structType reflect.Type) map[st
 			configKey = strings.ToLower(field.Name)
 		}
 		
+		// Untagged embedded structs are flattened, nested structs become sections
+		if field.Anonymous && bind.IsSectionType(field.Type) && field.Tag.Get("posix") == "" {
+			maps.Copy(example, cg.generateExampleStruct(field.Type))
+			continue
+		}
+		if bind.IsSectionType(field.Type) {
+			example[configKey] = cg.generateExampleStruct(field.Type)
+			continue
+		}
+		
 		// Generate example value
 		exampleValue := cg.generateExampleValue(field.Type, field.Tag)
 		example[configKey] = exampleValue
@@ -0,0 +1,164 @@
+package configfile
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDatabase struct {
+	Host string `posix:",host,Database host"`
+	Port int    `posix:",port,Database port"`
+}
+
+type CommonFlags struct {
+	Verbose bool `posix:"v,verbose,Verbose"`
+}
+
+type testConfig struct {
+	CommonFlags
+	Name     string        `posix:",name,Name"`
+	Timeout  time.Duration `posix:",timeout,Timeout"`
+	Since    time.Time     `posix:",since,Since"`
+	Database testDatabase  `posix:",database,Database settings"`
+}
+
+func TestMapToStruct(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    testConfig
+		wantErr bool
+	}{
+		{
+			name: "flat values",
+			data: map[string]any{"name": "api", "timeout": "30s"},
+			want: testConfig{Name: "api", Timeout: 30 * time.Second},
+		},
+		{
+			name: "nested section",
+			data: map[string]any{"database": map[string]any{"host": "db", "port": 5432}},
+			want: testConfig{Database: testDatabase{Host: "db", Port: 5432}},
+		},
+		{
+			name: "text unmarshaler from string",
+			data: map[string]any{"since": "2024-05-01T12:00:00Z"},
+			want: testConfig{Since: since},
+		},
+		{
+			name: "text unmarshaler from YAML timestamp",
+			data: map[string]any{"since": since},
+			want: testConfig{Since: since},
+		},
+		{
+			name: "embedded struct is flattened",
+			data: map[string]any{"verbose": true},
+			want: testConfig{CommonFlags: CommonFlags{Verbose: true}},
+		},
+		{
+			name:    "value for a section",
+			data:    map[string]any{"database": "db"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			data:    map[string]any{"since": "yesterday"},
+			wantErr: true,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testConfig
+			err := NewLoader("test").MapToStruct(tt.data, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MapToStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MapToStruct() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateExampleStruct(t *testing.T) {
+	example := NewConfigGenerator().generateExampleStruct(reflect.TypeOf(testConfig{}))
+	
+	tests := []struct {
+		key     string
+		section bool
+	}{
+		{key: "verbose"},
+		{key: "name"},
+		{key: "since"},
+		{key: "database", section: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, exists := example[tt.key]
+			if !exists {
+				t.Fatalf("example has no key %s: %v", tt.key, example)
+			}
+			if _, section := value.(map[string]any); section != tt.section {
+				t.Errorf("key %s section = %v, want %v", tt.key, section, tt.section)
+			}
+		})
+	}
+	
+	if _, exists := example["testcommon"]; exists {
+		t.Errorf("embedded struct became a section: %v", example)
+	}
+}
+
+func TestResolveKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantIndex []int
+		wantErr   bool
+	}{
+		{key: "name", wantIndex: []int{1}},
+		{key: "verbose", wantIndex: []int{0, 0}},
+		{key: "since", wantIndex: []int{3}},
+		{key: "database.port", wantIndex: []int{4, 1}},
+		{key: "database", wantErr: true},
+		{key: "since.year", wantErr: true},
+		{key: "missing", wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			index, _, err := NewLoader("test").ResolveKey(reflect.TypeOf(testConfig{}), tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(index, tt.wantIndex) {
+				t.Errorf("ResolveKey() index = %v, want %v", index, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestSetFields(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]any
+		want [][]int
+	}{
+		{name: "empty", data: map[string]any{}, want: nil},
+		{name: "zero values are set", data: map[string]any{"name": "", "verbose": false}, want: [][]int{{0, 0}, {1}}},
+		{name: "section fields", data: map[string]any{"database": map[string]any{"port": 0}}, want: [][]int{{4, 1}}},
+		{name: "unknown keys are ignored", data: map[string]any{"replicas": 2, "timeout": "0s"}, want: [][]int{{2}}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewLoader("test").SetFields(tt.data, reflect.TypeOf(&testConfig{}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package configfile
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is a location in a configuration file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+	
+	// Location of a scalar value, reported for errors about the value
+	valueLine   int
+	valueColumn int
+}
+
+// String formats the position as file:line:column, omitting unknown parts
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// Positions maps dotted keys to the position where their value is defined.
+// List items are keyed as key[index].
+type Positions map[string]Position
+
+// Find returns the position of a key, falling back to its closest parent
+func (p Positions) Find(key string) Position {
+	for current := key; current != ""; {
+		if pos, ok := p[current]; ok {
+			return pos
+		}
+		if i := strings.LastIndexAny(current, ".["); i >= 0 {
+			current = current[:i]
+		} else {
+			current = ""
+		}
+	}
+	
+	return Position{}
+}
+
+// FindValue returns the position of the value of a key, falling back to the
+// position of the key or its closest parent
+func (p Positions) FindValue(key string) Position {
+	pos := p.Find(key)
+	if found, ok := p[key]; ok && found.valueLine > 0 {
+		return Position{File: found.File, Line: found.valueLine, Column: found.valueColumn}
+	}
+	return pos
+}
+
+// Files returns the files the positions were read from, sorted and without duplicates
+func (p Positions) Files() []string {
+	var files []string
+	for _, pos := range p {
+		if pos.File != "" {
+			files = append(files, pos.File)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files)
+}
+
+// merge copies the positions of src into p, replacing existing keys
+func (p Positions) merge(src Positions) {
+	for key, pos := range src {
+		p[key] = pos
+	}
+}
+
+// nodePositions records the position of every key in a parsed YAML or JSON document
+func nodePositions(content []byte, file string) Positions {
+	positions := make(Positions)
+	
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil || len(root.Content) == 0 {
+		return positions
+	}
+	
+	recordNode(root.Content[0], "", file, positions)
+	return positions
+}
+
+// recordNode walks a mapping or sequence node, recording key positions
+func recordNode(node *yaml.Node, prefix, file string, positions Positions) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := JoinKey(prefix, keyNode.Value)
+			pos := Position{File: file, Line: keyNode.Line, Column: keyNode.Column}
+			if valueNode.Kind == yaml.ScalarNode {
+				pos.valueLine, pos.valueColumn = valueNode.Line, valueNode.Column
+			}
+			positions[key] = pos
+			recordNode(valueNode, key, file, positions)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			positions[key] = Position{File: file, Line: item.Line, Column: item.Column}
+			recordNode(item, key, file, positions)
+		}
+	}
+}
+
+// offsetPosition converts a byte offset in content to a line and column position
+func offsetPosition(content []byte, offset int64, file string) Position {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	
+	return Position{File: file, Line: line, Column: column}
+}
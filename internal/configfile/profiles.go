@@ -0,0 +1,121 @@
+package configfile
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrProfileNotFound is returned when the selected profile is not defined
+var ErrProfileNotFound = errors.New("profile not found")
+
+const (
+	// ProfilesKey is the top-level key holding named profiles
+	ProfilesKey = "profiles"
+	
+	// ExtendsKey names the profile a profile inherits from
+	ExtendsKey = "extends"
+)
+
+// Profiles returns the named profiles defined in the configuration
+func Profiles(data map[string]any) (map[string]any, error) {
+	raw, exists := data[ProfilesKey]
+	if !exists || raw == nil {
+		return map[string]any{}, nil
+	}
+	
+	profiles, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a mapping of profile names to settings, got %T", ProfilesKey, raw)
+	}
+	
+	return profiles, nil
+}
+
+// WithoutProfiles returns a copy of data with the profiles section removed
+func WithoutProfiles(data map[string]any) map[string]any {
+	result := make(map[string]any, len(data))
+	for key, value := range data {
+		if key == ProfilesKey {
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// ProfileChain resolves the extends chain of a profile, returning profile names
+// from the root ancestor to the requested profile
+func ProfileChain(profiles map[string]any, name string) ([]string, error) {
+	var chain []string
+	visited := make(map[string]bool)
+	
+	for current := name; current != ""; {
+		if visited[current] {
+			return nil, fmt.Errorf("profile inheritance cycle: %s -> %s", strings.Join(chain, " -> "), current)
+		}
+		visited[current] = true
+		
+		raw, exists := profiles[current]
+		if !exists {
+			if current == name {
+				return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+			}
+			return nil, fmt.Errorf("profile %q extends unknown profile %q", chain[len(chain)-1], current)
+		}
+		chain = append(chain, current)
+		
+		profile, ok := raw.(map[string]any)
+		if !ok {
+			if raw == nil {
+				break
+			}
+			return nil, fmt.Errorf("%s.%s must be a section, got %T", ProfilesKey, current, raw)
+		}
+		
+		parent, _ := profile[ExtendsKey].(string)
+		current = parent
+	}
+	
+	// Reverse so the root ancestor comes first
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	
+	return chain, nil
+}
+
+// ProfileSettings returns the settings of a single profile without its extends key
+func ProfileSettings(profiles map[string]any, name string) map[string]any {
+	settings := make(map[string]any)
+	profile, _ := profiles[name].(map[string]any)
+	for key, value := range profile {
+		if key == ExtendsKey {
+			continue
+		}
+		settings[key] = value
+	}
+	return settings
+}
+
+// LoadProfileMap loads a profile from its own file, e.g. app.prod.yaml next to app.yaml.
+// It returns a nil map and an empty path when no profile file exists.
+func (l *Loader) LoadProfileMap(profile string) (map[string]any, string, error) {
+	profilePath, err := l.FindProfileFile(profile)
+	if err != nil || profilePath == "" {
+		return nil, "", err
+	}
+	
+	data, err := l.ReadMap(profilePath)
+	if err != nil {
+		return nil, profilePath, err
+	}
+	
+	return data, profilePath, nil
+}
+
+// FindProfileFile returns the path of a profile's own file, or an empty string if none exists.
+// The file is named after the configuration file, e.g. config.prod.yaml next to config.yaml.
+func (l *Loader) FindProfileFile(profile string) (string, error) {
+	return l.findFile("." + profile)
+}
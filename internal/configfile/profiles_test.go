@@ -0,0 +1,96 @@
+package configfile
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProfileChain(t *testing.T) {
+	profiles := map[string]any{
+		"base":    map[string]any{"region": "eu"},
+		"staging": map[string]any{"extends": "base", "replicas": 2},
+		"prod":    map[string]any{"extends": "staging", "replicas": 5},
+		"empty":   nil,
+		"orphan":  map[string]any{"extends": "missing"},
+		"loop-a":  map[string]any{"extends": "loop-b"},
+		"loop-b":  map[string]any{"extends": "loop-a"},
+		"self":    map[string]any{"extends": "self"},
+		"broken":  "not a section",
+	}
+	
+	tests := []struct {
+		name      string
+		want      []string
+		wantErr   string
+		wantIsErr error
+	}{
+		{name: "base", want: []string{"base"}},
+		{name: "prod", want: []string{"base", "staging", "prod"}},
+		{name: "empty", want: []string{"empty"}},
+		{name: "unknown", wantErr: "profile not found: unknown", wantIsErr: ErrProfileNotFound},
+		{name: "orphan", wantErr: `profile "orphan" extends unknown profile "missing"`},
+		{name: "loop-a", wantErr: "profile inheritance cycle: loop-a -> loop-b -> loop-a"},
+		{name: "self", wantErr: "profile inheritance cycle: self -> self"},
+		{name: "broken", wantErr: "profiles.broken must be a section"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProfileChain(profiles, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ProfileChain() error = %v, want %q", err, tt.wantErr)
+				}
+				if tt.wantIsErr != nil && !errors.Is(err, tt.wantIsErr) {
+					t.Errorf("ProfileChain() error = %v, want errors.Is %v", err, tt.wantIsErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProfileChain() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProfileChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    map[string]any
+		wantErr bool
+	}{
+		{name: "no profiles", data: map[string]any{"name": "x"}, want: map[string]any{}},
+		{name: "profiles", data: map[string]any{"profiles": map[string]any{"prod": nil}}, want: map[string]any{"prod": nil}},
+		{name: "not a mapping", data: map[string]any{"profiles": []any{"prod"}}, wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Profiles(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Profiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Profiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileSettings(t *testing.T) {
+	profiles := map[string]any{
+		"prod": map[string]any{"extends": "base", "replicas": 5},
+	}
+	
+	got := ProfileSettings(profiles, "prod")
+	want := map[string]any{"replicas": 5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ProfileSettings() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,304 @@
+package configfile
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eugener/clix/internal/bind"
+)
+
+// SchemaDialect is the JSON Schema version of generated schemas
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches Go duration strings such as 1h30m or 250ms
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// textUnmarshalerType identifies struct values written as strings, such as time.Time
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// SchemaGenerator generates JSON Schema documents for configuration files
+type SchemaGenerator struct {
+	analyzer *bind.Analyzer
+	binder   *bind.Binder
+	keys     *ConfigGenerator
+}
+
+// NewSchemaGenerator creates a new schema generator
+func NewSchemaGenerator() *SchemaGenerator {
+	return &SchemaGenerator{
+		analyzer: bind.NewAnalyzer("posix"),
+		binder:   bind.NewBinder("posix"),
+		keys:     NewConfigGenerator(),
+	}
+}
+
+// CommandSchema returns the schema of a command's configuration, including
+// its required settings
+func (sg *SchemaGenerator) CommandSchema(structType reflect.Type) (map[string]any, error) {
+	schema, err := sg.structSchema(structType, true)
+	if err != nil {
+		return nil, err
+	}
+	
+	schema["$schema"] = SchemaDialect
+	return schema, nil
+}
+
+// FileSchema returns the schema of a configuration file shared by the given commands:
+// shared keys, the global section, per-command sections, profiles and includes.
+// Required settings are not enforced since they may come from flags or the environment.
+func (sg *SchemaGenerator) FileSchema(title string, commands map[string]reflect.Type) (map[string]any, error) {
+	names := slices.Sorted(maps.Keys(commands))
+	
+	// Shared keys accept any command's settings; the first command defines a key's schema
+	shared := make(map[string]any)
+	commandSchemas := make(map[string]any)
+	for _, name := range names {
+		schema, err := sg.structSchema(commands[name], false)
+		if err != nil {
+			return nil, fmt.Errorf("command %s: %w", name, err)
+		}
+		commandSchemas[name] = schema
+		
+		for key, property := range schema["properties"].(map[string]any) {
+			if _, exists := shared[key]; !exists {
+				shared[key] = property
+			}
+		}
+	}
+	
+	layer := func() map[string]any {
+		properties := maps.Clone(shared)
+		properties[GlobalKey] = map[string]any{
+			"type":                 "object",
+			"description":          "Settings shared by all commands",
+			"properties":           maps.Clone(shared),
+			"additionalProperties": false,
+		}
+		properties[CommandsKey] = map[string]any{
+			"type":                 "object",
+			"description":          "Per-command settings",
+			"properties":           commandSchemas,
+			"additionalProperties": false,
+		}
+		return properties
+	}
+	
+	profile := layer()
+	profile[ExtendsKey] = map[string]any{
+		"type":        "string",
+		"description": "Name of the profile this profile extends",
+	}
+	
+	root := layer()
+	root[IncludeKey] = map[string]any{
+		"description": "Files to include, relative to this file; globs are allowed",
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+	root[ProfilesKey] = map[string]any{
+		"type":                 "object",
+		"description":          "Named profiles selected with --profile",
+		"additionalProperties": map[string]any{"$ref": "#/$defs/profile"},
+	}
+	
+	return map[string]any{
+		"$schema":              SchemaDialect,
+		"title":                title,
+		"type":                 "object",
+		"properties":           root,
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"profile": map[string]any{
+				"type":                 "object",
+				"properties":           profile,
+				"additionalProperties": false,
+			},
+		},
+	}, nil
+}
+
+// structSchema returns the object schema of a config struct
+func (sg *SchemaGenerator) structSchema(structType reflect.Type, withRequired bool) (map[string]any, error) {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	metadata, err := sg.analyzer.Analyze(structType)
+	if err != nil {
+		return nil, err
+	}
+	
+	fields := make(map[string]bind.FieldInfo)
+	for _, field := range metadata.Fields {
+		fields[fmt.Sprint(field.Index)] = field
+	}
+	
+	sections := make(map[string]bind.SectionInfo)
+	for _, section := range metadata.Sections {
+		sections[fmt.Sprint(section.Index)] = section
+	}
+	
+	return sg.objectSchema(structType, nil, fields, sections, withRequired), nil
+}
+
+// objectSchema describes the fields of a struct, descending into nested sections
+func (sg *SchemaGenerator) objectSchema(structType reflect.Type, index []int, fields map[string]bind.FieldInfo, sections map[string]bind.SectionInfo, withRequired bool) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() || bind.IsInjected(field) {
+			continue
+		}
+		
+		key := sg.keys.getConfigKey(field)
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		fieldIndex := append(slices.Clone(index), i)
+		
+		// Untagged embedded structs are flattened into the parent
+		if field.Anonymous && bind.IsSectionType(field.Type) && field.Tag.Get("posix") == "" {
+			embedded := sg.objectSchema(field.Type, fieldIndex, fields, sections, withRequired)
+			maps.Copy(properties, embedded["properties"].(map[string]any))
+			if embeddedRequired, ok := embedded["required"].([]string); ok {
+				required = append(required, embeddedRequired...)
+			}
+			continue
+		}
+		
+		if bind.IsSectionType(field.Type) {
+			section := sg.objectSchema(field.Type, fieldIndex, fields, sections, withRequired)
+			if info, ok := sections[fmt.Sprint(fieldIndex)]; ok && info.Description != "" {
+				section["description"] = info.Description
+			}
+			properties[key] = section
+			continue
+		}
+		
+		info, described := fields[fmt.Sprint(fieldIndex)]
+		if described && info.Positional {
+			continue
+		}
+		
+		properties[key] = sg.fieldSchema(field.Type, info)
+		if withRequired && info.Required {
+			required = append(required, key)
+		}
+	}
+	
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	
+	return schema
+}
+
+// fieldSchema describes a single setting from its type and posix tag
+func (sg *SchemaGenerator) fieldSchema(fieldType reflect.Type, info bind.FieldInfo) map[string]any {
+	schema := typeSchema(fieldType)
+	
+	if info.Description != "" {
+		schema["description"] = info.Description
+	}
+	
+	if info.Default != "" {
+		schema["default"] = sg.typedValue(info.Default, fieldType)
+	}
+	
+	if len(info.Choices) > 0 {
+		target := schema
+		if items, ok := schema["items"].(map[string]any); ok {
+			target = items
+			fieldType = fieldType.Elem()
+		}
+		enum := make([]any, len(info.Choices))
+		for i, choice := range info.Choices {
+			enum[i] = sg.typedValue(choice, fieldType)
+		}
+		target["enum"] = enum
+	}
+	
+	if info.Pattern != "" {
+		schema["pattern"] = info.Pattern
+	}
+	
+	// Bounds apply to numbers by value and to strings and lists by length
+	minKey, maxKey := "minimum", "maximum"
+	switch schema["type"] {
+	case "string":
+		minKey, maxKey = "minLength", "maxLength"
+	case "array":
+		minKey, maxKey = "minItems", "maxItems"
+	}
+	if info.Min != nil {
+		schema[minKey] = *info.Min
+	}
+	if info.Max != nil {
+		schema[maxKey] = *info.Max
+	}
+	
+	return schema
+}
+
+// typedValue converts a tag value to the JSON type of the field, keeping the
+// string when it does not convert
+func (sg *SchemaGenerator) typedValue(value string, fieldType reflect.Type) any {
+	if fieldType.Kind() == reflect.String || fieldType == reflect.TypeOf(time.Duration(0)) {
+		return value
+	}
+	
+	converted, err := sg.binder.ConvertString(value, fieldType)
+	if err != nil {
+		return value
+	}
+	return converted
+}
+
+// typeSchema returns the JSON Schema type of a Go type
+func typeSchema(t reflect.Type) map[string]any {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]any{"type": []any{"string", "integer"}, "pattern": durationPattern}
+	}
+	
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+	
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	}
+	
+	return map[string]any{}
+}
@@ -0,0 +1,113 @@
+package configfile
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaConfig struct {
+	CommonFlags
+	Env      string        `posix:"e,env,Environment,choices=dev;prod|required"`
+	Workers  int           `posix:"w,workers,Worker count,default=4|min=1|max=64"`
+	Name     string        `posix:"n,name,Release name,pattern=^[a-z]+$"`
+	Timeout  time.Duration `posix:",timeout,Timeout,default=30s"`
+	Since    time.Time     `posix:",since,Since"`
+	Database testDatabase  `posix:",database,Database settings"`
+	Files    []string      `posix:",,Files,positional"`
+}
+
+func TestCommandSchema(t *testing.T) {
+	schema, err := NewSchemaGenerator().CommandSchema(reflect.TypeOf(schemaConfig{}))
+	if err != nil {
+		t.Fatalf("CommandSchema() error = %v", err)
+	}
+	
+	if schema["$schema"] != SchemaDialect {
+		t.Errorf("$schema = %v, want %s", schema["$schema"], SchemaDialect)
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"env"}) {
+		t.Errorf("required = %v, want [env]", schema["required"])
+	}
+	
+	properties := schema["properties"].(map[string]any)
+	
+	tests := []struct {
+		key  string
+		want map[string]any
+	}{
+		{key: "verbose", want: map[string]any{"type": "boolean", "description": "Verbose"}},
+		{key: "env", want: map[string]any{"type": "string", "description": "Environment", "enum": []any{"dev", "prod"}}},
+		{key: "workers", want: map[string]any{"type": "integer", "description": "Worker count", "default": 4, "minimum": 1.0, "maximum": 64.0}},
+		{key: "name", want: map[string]any{"type": "string", "description": "Release name", "pattern": "^[a-z]+$"}},
+		{key: "timeout", want: map[string]any{"type": []any{"string", "integer"}, "pattern": durationPattern, "description": "Timeout", "default": "30s"}},
+		{key: "since", want: map[string]any{"type": "string", "description": "Since"}},
+		{key: "database", want: map[string]any{
+			"type":        "object",
+			"description": "Database settings",
+			"properties": map[string]any{
+				"host": map[string]any{"type": "string", "description": "Database host"},
+				"port": map[string]any{"type": "integer", "description": "Database port"},
+			},
+			"additionalProperties": false,
+		}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := properties[tt.key]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("properties[%s] = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+	
+	if _, exists := properties["files"]; exists {
+		t.Errorf("positional field in schema: %v", properties["files"])
+	}
+}
+
+func TestFileSchema(t *testing.T) {
+	schema, err := NewSchemaGenerator().FileSchema("tool", map[string]reflect.Type{
+		"deploy": reflect.TypeOf(schemaConfig{}),
+		"status": reflect.TypeOf(testConfig{}),
+	})
+	if err != nil {
+		t.Fatalf("FileSchema() error = %v", err)
+	}
+	
+	root := schema["properties"].(map[string]any)
+	profile := schema["$defs"].(map[string]any)["profile"].(map[string]any)["properties"].(map[string]any)
+	commands := root[CommandsKey].(map[string]any)["properties"].(map[string]any)
+	
+	tests := []struct {
+		name       string
+		properties map[string]any
+		key        string
+		want       bool
+	}{
+		{name: "shared key from deploy", properties: root, key: "workers", want: true},
+		{name: "shared key from status", properties: root, key: "since", want: true},
+		{name: "global section", properties: root, key: GlobalKey, want: true},
+		{name: "commands section", properties: root, key: CommandsKey, want: true},
+		{name: "include", properties: root, key: IncludeKey, want: true},
+		{name: "profiles", properties: root, key: ProfilesKey, want: true},
+		{name: "profile extends", properties: profile, key: ExtendsKey, want: true},
+		{name: "profile has no nested profiles", properties: profile, key: ProfilesKey},
+		{name: "profile has no include", properties: profile, key: IncludeKey},
+		{name: "deploy section", properties: commands, key: "deploy", want: true},
+		{name: "status section", properties: commands, key: "status", want: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, exists := tt.properties[tt.key]; exists != tt.want {
+				t.Errorf("key %s present = %v, want %v", tt.key, exists, tt.want)
+			}
+		})
+	}
+	
+	// Required settings may come from flags, so the file schema does not enforce them
+	if _, exists := commands["deploy"].(map[string]any)["required"]; exists {
+		t.Error("file schema requires command settings")
+	}
+}
@@ -0,0 +1,170 @@
+package configfile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/eugener/clix/internal/bind"
+)
+
+const (
+	// CommandsKey is the top-level key holding per-command sections
+	CommandsKey = "commands"
+	
+	// GlobalKey is the top-level key holding settings shared by all commands
+	GlobalKey = "global"
+)
+
+// SharedConfig returns the settings shared by all commands: the top-level keys
+// followed by the global section, with the reserved section keys removed
+func SharedConfig(data map[string]any) map[string]any {
+	shared := make(map[string]any)
+	
+	// Nested maps are copied so merging never modifies data
+	for key, value := range data {
+		if key == CommandsKey || key == GlobalKey || key == ProfilesKey {
+			continue
+		}
+		MergeMaps(shared, map[string]any{key: value})
+	}
+	
+	if global, ok := data[GlobalKey].(map[string]any); ok {
+		MergeMaps(shared, global)
+	}
+	
+	return shared
+}
+
+// CommandSections returns the per-command sections of the configuration
+func CommandSections(data map[string]any) (map[string]any, error) {
+	raw, exists := data[CommandsKey]
+	if !exists || raw == nil {
+		return map[string]any{}, nil
+	}
+	
+	sections, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a mapping of command names to sections, got %T", CommandsKey, raw)
+	}
+	
+	return sections, nil
+}
+
+// CommandConfig merges the configuration layers that apply to a command, in order:
+// top-level keys, the global section, then commands.<name>
+func CommandConfig(data map[string]any, command string) (map[string]any, error) {
+	merged := SharedConfig(data)
+	
+	sections, err := CommandSections(data)
+	if err != nil {
+		return nil, err
+	}
+	
+	raw, exists := sections[command]
+	if !exists || raw == nil {
+		return merged, nil
+	}
+	
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s must be a section, got %T", CommandsKey, command, raw)
+	}
+	
+	return MergeMaps(merged, section), nil
+}
+
+// CommandScope is a section of the configuration that applies to a command.
+// Prefix is the dotted key of the section, empty for top-level keys.
+type CommandScope struct {
+	Prefix string
+	Data   map[string]any
+}
+
+// CommandScopes returns the sections of data that apply to a command, in merge order:
+// top-level keys, the global section, then commands.<name>. Sections that are not
+// mappings are skipped; CommandConfig reports them.
+func CommandScopes(data map[string]any, command string) []CommandScope {
+	topLevel := make(map[string]any)
+	for key, value := range data {
+		if key != CommandsKey && key != GlobalKey && key != ProfilesKey {
+			topLevel[key] = value
+		}
+	}
+	
+	scopes := []CommandScope{{Prefix: "", Data: topLevel}}
+	
+	if global, ok := data[GlobalKey].(map[string]any); ok {
+		scopes = append(scopes, CommandScope{Prefix: GlobalKey, Data: global})
+	}
+	
+	if command != "" {
+		sections, _ := data[CommandsKey].(map[string]any)
+		if section, ok := sections[command].(map[string]any); ok {
+			scopes = append(scopes, CommandScope{Prefix: CommandsKey + "." + command, Data: section})
+		}
+	}
+	
+	return scopes
+}
+
+// MergeMaps deep-merges src into dst and returns dst. Nested maps are merged
+// key by key, any other value in src replaces the one in dst.
+func MergeMaps(dst, src map[string]any) map[string]any {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		
+		if srcIsMap && dstIsMap {
+			dst[key] = MergeMaps(dstMap, srcMap)
+			continue
+		}
+		
+		if srcIsMap {
+			dst[key] = MergeMaps(make(map[string]any), srcMap)
+			continue
+		}
+		
+		dst[key] = value
+	}
+	
+	return dst
+}
+
+// UnknownKeys returns the dotted keys in data that do not map onto a field of structType
+func (l *Loader) UnknownKeys(data map[string]any, structType reflect.Type) []string {
+	var unknown []string
+	l.collectUnknownKeys(data, structType, "", &unknown)
+	sort.Strings(unknown)
+	return unknown
+}
+
+// collectUnknownKeys walks data alongside structType, descending into nested sections
+func (l *Loader) collectUnknownKeys(data map[string]any, structType reflect.Type, prefix string, unknown *[]string) {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	if structType.Kind() != reflect.Struct {
+		return
+	}
+	
+	fieldMap := l.buildFieldMapping(structType)
+	
+	for key, value := range data {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		
+		mapping, exists := fieldMap[key]
+		if !exists {
+			*unknown = append(*unknown, path)
+			continue
+		}
+		
+		if section, ok := value.(map[string]any); ok && bind.IsSectionType(mapping.Type) {
+			l.collectUnknownKeys(section, mapping.Type, path, unknown)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package configfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandConfig(t *testing.T) {
+	data := map[string]any{
+		"name":   "top",
+		"region": "eu",
+		"database": map[string]any{
+			"host": "top-db",
+			"port": 5432,
+		},
+		GlobalKey: map[string]any{
+			"region": "us",
+		},
+		CommandsKey: map[string]any{
+			"deploy": map[string]any{
+				"name":     "deploy",
+				"database": map[string]any{"host": "deploy-db"},
+			},
+			"broken": "not a section",
+		},
+		ProfilesKey: map[string]any{
+			"prod": map[string]any{"name": "prod"},
+		},
+	}
+	
+	tests := []struct {
+		command string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			command: "deploy",
+			want: map[string]any{
+				"name":     "deploy",
+				"region":   "us",
+				"database": map[string]any{"host": "deploy-db", "port": 5432},
+			},
+		},
+		{
+			command: "status",
+			want: map[string]any{
+				"name":     "top",
+				"region":   "us",
+				"database": map[string]any{"host": "top-db", "port": 5432},
+			},
+		},
+		{
+			command: "broken",
+			wantErr: true,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got, err := CommandConfig(data, tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CommandConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CommandConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	
+	// Merging must not modify the source data
+	if host := data["database"].(map[string]any)["host"]; host != "top-db" {
+		t.Errorf("CommandConfig() modified data: database.host = %v", host)
+	}
+}
+
+func TestCommandScopes(t *testing.T) {
+	data := map[string]any{
+		"name":      "top",
+		GlobalKey:   map[string]any{"region": "us"},
+		CommandsKey: map[string]any{"deploy": map[string]any{"name": "deploy"}},
+	}
+	
+	tests := []struct {
+		command      string
+		wantPrefixes []string
+	}{
+		{command: "deploy", wantPrefixes: []string{"", "global", "commands.deploy"}},
+		{command: "status", wantPrefixes: []string{"", "global"}},
+		{command: "", wantPrefixes: []string{"", "global"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			var prefixes []string
+			for _, scope := range CommandScopes(data, tt.command) {
+				prefixes = append(prefixes, scope.Prefix)
+			}
+			if !reflect.DeepEqual(prefixes, tt.wantPrefixes) {
+				t.Errorf("CommandScopes() prefixes = %v, want %v", prefixes, tt.wantPrefixes)
+			}
+		})
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]any
+		want []string
+	}{
+		{
+			name: "known keys",
+			data: map[string]any{"name": "x", "verbose": true, "database": map[string]any{"host": "db"}},
+		},
+		{
+			name: "unknown top-level and nested keys",
+			data: map[string]any{"nmae": "x", "database": map[string]any{"hots": "db"}},
+			want: []string{"database.hots", "nmae"},
+		},
+		{
+			name: "time value is not a section",
+			data: map[string]any{"since": "2024-05-01T12:00:00Z"},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewLoader("test").UnknownKeys(tt.data, reflect.TypeOf(testConfig{}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
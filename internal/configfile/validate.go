@@ -0,0 +1,326 @@
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/help"
+)
+
+// ValidationError is a configuration problem at a position in a file
+type ValidationError struct {
+	Position Position
+	Key      string
+	Message  string
+}
+
+// Error formats the problem as file:line:column: key: message
+func (e *ValidationError) Error() string {
+	if e.Position.File == "" {
+		return fmt.Sprintf("%s: %s", e.Key, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Position, e.Key, e.Message)
+}
+
+// ValidationErrors is a list of configuration problems
+type ValidationErrors []*ValidationError
+
+// Error lists the problems one per line
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Sort orders the problems by file and position
+func (errs ValidationErrors) Sort() {
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i].Position, errs[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return errs[i].Key < errs[j].Key
+	})
+}
+
+// CheckValues checks the values in data against a command's config type: every known key
+// must convert to its field type and satisfy the field's choices. Unknown keys are ignored.
+// The prefix is the dotted key of data within the file, used for positions and messages.
+func (l *Loader) CheckValues(data map[string]any, structType reflect.Type, prefix string, positions Positions) ValidationErrors {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	// Allowed values and rules are declared in posix tags, keyed by field index path
+	fields := make(map[string]bind.FieldInfo)
+	if metadata, err := bind.NewAnalyzer("posix").Analyze(structType); err == nil {
+		for _, field := range metadata.Fields {
+			fields[fmt.Sprint(field.Index)] = field
+		}
+	}
+	
+	var errs ValidationErrors
+	l.checkValues(data, structType, nil, prefix, fields, positions, &errs)
+	return errs
+}
+
+// checkValues walks data alongside structType, descending into nested sections
+func (l *Loader) checkValues(data map[string]any, structType reflect.Type, index []int, prefix string, fields map[string]bind.FieldInfo, positions Positions, errs *ValidationErrors) {
+	fieldMap := l.buildFieldMapping(structType)
+	
+	for key, value := range data {
+		mapping, exists := fieldMap[key]
+		if !exists {
+			continue
+		}
+		
+		path := JoinKey(prefix, key)
+		fieldIndex := append(slices.Clone(index), mapping.Index...)
+		report := func(path, format string, args ...any) {
+			*errs = append(*errs, &ValidationError{
+				Position: positions.FindValue(path),
+				Key:      path,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+		
+		if bind.IsSectionType(mapping.Type) {
+			section, ok := value.(map[string]any)
+			if !ok {
+				report(path, "expected a section, got %s", describeValue(value))
+				continue
+			}
+			l.checkValues(section, mapping.Type, fieldIndex, path, fields, positions, errs)
+			continue
+		}
+		
+		if !l.validValue(value, mapping.Type) {
+			report(path, "expected %s, got %s", describeType(mapping.Type), describeValue(value))
+			continue
+		}
+		
+		field, described := fields[fmt.Sprint(fieldIndex)]
+		if !described {
+			continue
+		}
+		
+		if converted, err := l.convertValue(value, mapping.Type); err == nil {
+			if err := field.CheckValue(reflect.ValueOf(converted)); err != nil {
+				report(path, "%v", err)
+				continue
+			}
+		}
+		
+		allowed := field.Choices
+		if len(allowed) == 0 {
+			continue
+		}
+		
+		items, isList := value.([]any)
+		if !isList {
+			items = []any{value}
+		}
+		for i, item := range items {
+			itemPath := path
+			if isList {
+				itemPath = fmt.Sprintf("%s[%d]", path, i)
+			}
+			if !slices.Contains(allowed, fmt.Sprint(item)) {
+				report(itemPath, "invalid value %s, must be one of: %s", describeValue(item), strings.Join(allowed, ", "))
+			}
+		}
+	}
+}
+
+// validValue reports whether value converts to targetType. Unlike loading,
+// strings for booleans must be a recognizable boolean.
+func (l *Loader) validValue(value any, targetType reflect.Type) bool {
+	if _, err := l.convertValue(value, targetType); err != nil {
+		return false
+	}
+	
+	switch targetType.Kind() {
+	case reflect.Bool:
+		if s, ok := value.(string); ok {
+			switch strings.ToLower(s) {
+			case "true", "false", "yes", "no", "1", "0":
+				return true
+			}
+			return false
+		}
+	case reflect.Slice:
+		if items, ok := value.([]any); ok {
+			for _, item := range items {
+				if !l.validValue(item, targetType.Elem()) {
+					return false
+				}
+			}
+		}
+	}
+	
+	return true
+}
+
+// CheckUnknownKeys reports keys in data that do not map onto structType,
+// suggesting similar known keys
+func (l *Loader) CheckUnknownKeys(data map[string]any, structType reflect.Type, prefix string, positions Positions) ValidationErrors {
+	var errs ValidationErrors
+	known := l.KnownKeys(structType)
+	
+	for _, key := range l.UnknownKeys(data, structType) {
+		errs = append(errs, UnknownKeyError(key, prefix, positions, known))
+	}
+	
+	return errs
+}
+
+// UnknownKeyError creates the error for an unknown key, with "did you mean" suggestions from known
+func UnknownKeyError(key, prefix string, positions Positions, known []string) *ValidationError {
+	path := JoinKey(prefix, key)
+	message := "unknown key"
+	
+	if suggestions := help.NewSuggestionEngine().SuggestFlags(key, known); len(suggestions) > 0 {
+		message += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+	}
+	
+	return &ValidationError{Position: positions.Find(path), Key: path, Message: message}
+}
+
+// KnownKeys returns the sorted dotted keys accepted for structType, including nested sections
+func (l *Loader) KnownKeys(structType reflect.Type) []string {
+	var keys []string
+	l.collectKnownKeys(structType, "", &keys)
+	sort.Strings(keys)
+	return slices.Compact(keys)
+}
+
+// collectKnownKeys adds the keys of structType and its sections to keys
+func (l *Loader) collectKnownKeys(structType reflect.Type, prefix string, keys *[]string) {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	if structType.Kind() != reflect.Struct {
+		return
+	}
+	
+	for key, mapping := range l.buildFieldMapping(structType) {
+		// The exact Go field name is accepted but not suggested
+		if key == mapping.Name && key != strings.ToLower(key) {
+			continue
+		}
+		
+		path := JoinKey(prefix, key)
+		if bind.IsSectionType(mapping.Type) {
+			l.collectKnownKeys(mapping.Type, path, keys)
+			continue
+		}
+		*keys = append(*keys, path)
+	}
+}
+
+// CheckRequired reports required fields of structType that have no value in data,
+// no default and no environment variable set. The error points at the section
+// given by prefix.
+func (l *Loader) CheckRequired(data map[string]any, structType reflect.Type, prefix string, positions Positions) ValidationErrors {
+	metadata, err := bind.NewAnalyzer("posix").Analyze(structType)
+	if err != nil {
+		return nil
+	}
+	
+	var errs ValidationErrors
+	
+	for _, field := range metadata.Fields {
+		if !field.Required || field.Positional || field.Default != "" {
+			continue
+		}
+		if field.Environment != "" && os.Getenv(field.Environment) != "" {
+			continue
+		}
+		if l.hasValue(data, structType, field.Index) {
+			continue
+		}
+		
+		errs = append(errs, &ValidationError{
+			Position: positions.Find(prefix),
+			Key:      JoinKey(prefix, field.Path),
+			Message:  "missing required value",
+		})
+	}
+	
+	return errs
+}
+
+// hasValue reports whether data sets the field at the given index path
+func (l *Loader) hasValue(data map[string]any, structType reflect.Type, index []int) bool {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	
+	for key, value := range data {
+		mapping, exists := l.buildFieldMapping(structType)[key]
+		if !exists || !slices.Equal(mapping.Index, index[:min(len(mapping.Index), len(index))]) || value == nil {
+			continue
+		}
+		
+		if len(index) == len(mapping.Index) {
+			return true
+		}
+		
+		if section, ok := value.(map[string]any); ok && l.hasValue(section, mapping.Type, index[len(mapping.Index):]) {
+			return true
+		}
+	}
+	
+	return false
+}
+
+// describeType names a type the way it is written in configuration files
+func describeType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "a duration"
+	}
+	
+	switch t.Kind() {
+	case reflect.Slice:
+		return "a list of " + strings.TrimPrefix(describeType(t.Elem()), "a ")
+	case reflect.Map:
+		return "a mapping"
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t.Kind().String()
+	}
+	
+	return t.String()
+}
+
+// describeValue formats a parsed configuration value for error messages
+func describeValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case map[string]any:
+		return "a section"
+	case []any:
+		return "a list"
+	}
+	return fmt.Sprint(value)
+}
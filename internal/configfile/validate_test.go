@@ -0,0 +1,78 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type validateConfig struct {
+	Env      string       `posix:",env,Environment,choices=dev;prod"`
+	Replicas int          `posix:",replicas,Replicas"`
+	Database testDatabase `posix:",database,Database settings"`
+}
+
+func TestCheckValues(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "valid values",
+			file: "env: dev\nreplicas: 2\ndatabase:\n  port: 5432\nunknown: x\n",
+		},
+		{
+			name: "wrong type points at the value",
+			file: "replicas: three\n",
+			want: []string{`config.yaml:1:11: replicas: expected int, got "three"`},
+		},
+		{
+			name: "nested value",
+			file: "database:\n  port:   nope\n",
+			want: []string{`config.yaml:2:11: database.port: expected int, got "nope"`},
+		},
+		{
+			name: "choice",
+			file: "env: qa\n",
+			want: []string{`config.yaml:1:6: env: invalid value "qa", must be one of: dev, prod`},
+		},
+		{
+			name: "value for a section",
+			file: "database: db\n",
+			want: []string{`config.yaml:1:11: database: expected a section, got "db"`},
+		},
+		{
+			name: "section for a value points at the key",
+			file: "replicas:\n  count: 2\n",
+			want: []string{"config.yaml:1:1: replicas: expected int, got a section"},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			
+			loader := NewLoader("test")
+			data, positions, err := loader.ReadMapPositions(path)
+			if err != nil {
+				t.Fatalf("ReadMapPositions() error = %v", err)
+			}
+			
+			var got []string
+			for _, err := range loader.CheckValues(data, reflect.TypeOf(validateConfig{}), "", positions) {
+				rel, _ := filepath.Rel(dir, err.Position.File)
+				err.Position.File = rel
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckValues() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package configfile
+
+import (
+	"context"
+	"os"
+	"slices"
+	"time"
+)
+
+// Watcher polls configuration files and reports when any of them changes.
+// Polling works on every platform and file system, including network mounts
+// and files replaced by editors through a rename.
+type Watcher struct {
+	interval time.Duration
+	files    func() []string
+}
+
+// fileState is the observed state of a watched file
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// NewWatcher creates a watcher checking the files returned by files every interval.
+// The file list is refreshed after each change, so newly included files are picked up.
+func NewWatcher(interval time.Duration, files func() []string) *Watcher {
+	return &Watcher{
+		interval: interval,
+		files:    files,
+	}
+}
+
+// Watch calls onChange after any watched file is created, modified or removed,
+// until ctx is done
+func (w *Watcher) Watch(ctx context.Context, onChange func()) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	
+	files := w.files()
+	states := snapshot(files)
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		
+		current := snapshot(files)
+		if equalStates(states, current) {
+			continue
+		}
+		
+		states = current
+		onChange()
+		
+		if refreshed := w.files(); !slices.Equal(refreshed, files) {
+			files = refreshed
+			states = snapshot(files)
+		}
+	}
+}
+
+// snapshot records the state of each file
+func snapshot(files []string) map[string]fileState {
+	states := make(map[string]fileState, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			states[file] = fileState{}
+			continue
+		}
+		states[file] = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+	return states
+}
+
+// equalStates reports whether two snapshots describe the same files in the same state
+func equalStates(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, state := range a {
+		other, ok := b[file]
+		if !ok || other.exists != state.exists || other.size != state.size || !other.modTime.Equal(state.modTime) {
+			return false
+		}
+	}
+	return true
+}
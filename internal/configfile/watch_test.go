@@ -0,0 +1,101 @@
+package configfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	tests := []struct {
+		name   string
+		create bool
+		change func(path string) error
+	}{
+		{
+			name:   "modified",
+			create: true,
+			change: func(path string) error { return os.WriteFile(path, []byte("name: changed\n"), 0644) },
+		},
+		{
+			name:   "replaced through a rename",
+			create: true,
+			change: func(path string) error {
+				tmp := path + ".tmp"
+				if err := os.WriteFile(tmp, []byte("name: replaced\n"), 0644); err != nil {
+					return err
+				}
+				return os.Rename(tmp, path)
+			},
+		},
+		{
+			name:   "removed",
+			create: true,
+			change: os.Remove,
+		},
+		{
+			name:   "created",
+			change: func(path string) error { return os.WriteFile(path, []byte("name: new\n"), 0644) },
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.create {
+				if err := os.WriteFile(path, []byte("name: api\n"), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+			
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			
+			changed := make(chan struct{}, 1)
+			watcher := NewWatcher(5*time.Millisecond, func() []string { return []string{path} })
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				watcher.Watch(ctx, func() {
+					select {
+					case changed <- struct{}{}:
+					default:
+					}
+				})
+			}()
+			
+			// Let the watcher take its first snapshot
+			time.Sleep(20 * time.Millisecond)
+			if err := tt.change(path); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+			
+			select {
+			case <-changed:
+			case <-time.After(2 * time.Second):
+				t.Fatal("watcher did not report the change")
+			}
+			
+			cancel()
+			<-done
+		})
+	}
+}
+
+func TestWatcherUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("name: api\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	
+	calls := 0
+	NewWatcher(5*time.Millisecond, func() []string { return []string{path} }).Watch(ctx, func() { calls++ })
+	if calls != 0 {
+		t.Errorf("onChange called %d times for unchanged files", calls)
+	}
+}
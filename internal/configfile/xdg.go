@@ -0,0 +1,101 @@
+package configfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrFileNotFound is returned when an explicitly given configuration file does not exist
+var ErrFileNotFound = errors.New("config file not found")
+
+// Configuration file scopes, from lowest to highest precedence
+const (
+	ScopeSystem   = "system"
+	ScopeUser     = "user"
+	ScopeProject  = "project"
+	ScopeExplicit = "explicit"
+)
+
+// SearchLocation is a directory searched for configuration files and the
+// base file names tried in it, in order
+type SearchLocation struct {
+	Scope string
+	Dir   string
+	Names []string
+}
+
+// File is a configuration file found in a search location
+type File struct {
+	Path  string
+	Scope string
+}
+
+// DefaultLocations returns the XDG Base Directory search locations of an application,
+// from highest to lowest precedence: <fileName>.* in the current directory, then
+// config.* or <fileName>.* in $XDG_CONFIG_HOME/<app> (~/.config/<app> by default),
+// in each of $XDG_CONFIG_DIRS (/etc/xdg by default) and in /etc/<app>.
+func DefaultLocations(appName, fileName string) []SearchLocation {
+	appNames := []string{"config", fileName}
+	
+	locations := []SearchLocation{
+		{Scope: ScopeProject, Dir: ".", Names: []string{fileName}},
+	}
+	
+	if configHome := userConfigHome(); configHome != "" {
+		locations = append(locations, SearchLocation{
+			Scope: ScopeUser,
+			Dir:   filepath.Join(configHome, appName),
+			Names: appNames,
+		})
+	}
+	
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range filepath.SplitList(configDirs) {
+		// Relative paths are invalid per the specification and ignored
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		locations = append(locations, SearchLocation{
+			Scope: ScopeSystem,
+			Dir:   filepath.Join(dir, appName),
+			Names: appNames,
+		})
+	}
+	
+	return append(locations, SearchLocation{
+		Scope: ScopeSystem,
+		Dir:   filepath.Join("/etc", appName),
+		Names: appNames,
+	})
+}
+
+// userConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config
+func userConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config")
+}
+
+// EnvVarName returns the environment variable naming an application's explicit
+// configuration file, e.g. MY_TOOL_CONFIG for my-tool
+func EnvVarName(appName string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, appName)
+	
+	return strings.ToUpper(name) + "_CONFIG"
+}
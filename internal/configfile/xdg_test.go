@@ -0,0 +1,170 @@
+package configfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultLocations(t *testing.T) {
+	appNames := []string{"config", "tool"}
+	
+	tests := []struct {
+		name       string
+		configHome string
+		configDirs string
+		want       []SearchLocation
+	}{
+		{
+			name:       "defaults",
+			configHome: "/home/u/.config",
+			want: []SearchLocation{
+				{Scope: ScopeProject, Dir: ".", Names: []string{"tool"}},
+				{Scope: ScopeUser, Dir: "/home/u/.config/my-app", Names: appNames},
+				{Scope: ScopeSystem, Dir: "/etc/xdg/my-app", Names: appNames},
+				{Scope: ScopeSystem, Dir: "/etc/my-app", Names: appNames},
+			},
+		},
+		{
+			name:       "config dirs in order, relative dirs ignored",
+			configHome: "/xdg/home",
+			configDirs: "/xdg/a:relative:/xdg/b",
+			want: []SearchLocation{
+				{Scope: ScopeProject, Dir: ".", Names: []string{"tool"}},
+				{Scope: ScopeUser, Dir: "/xdg/home/my-app", Names: appNames},
+				{Scope: ScopeSystem, Dir: "/xdg/a/my-app", Names: appNames},
+				{Scope: ScopeSystem, Dir: "/xdg/b/my-app", Names: appNames},
+				{Scope: ScopeSystem, Dir: "/etc/my-app", Names: appNames},
+			},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			t.Setenv("XDG_CONFIG_DIRS", tt.configDirs)
+			
+			if got := DefaultLocations("my-app", "tool"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DefaultLocations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserConfigHome(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	
+	tests := []struct {
+		configHome string
+		want       string
+	}{
+		{configHome: "/xdg", want: "/xdg"},
+		{configHome: "", want: "/home/u/.config"},
+		{configHome: "relative", want: "/home/u/.config"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.configHome, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			if got := userConfigHome(); got != tt.want {
+				t.Errorf("userConfigHome() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	tests := []struct {
+		appName string
+		want    string
+	}{
+		{appName: "tool", want: "TOOL_CONFIG"},
+		{appName: "my-tool", want: "MY_TOOL_CONFIG"},
+		{appName: "my.tool2", want: "MY_TOOL2_CONFIG"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.appName, func(t *testing.T) {
+			if got := EnvVarName(tt.appName); got != tt.want {
+				t.Errorf("EnvVarName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		want      []File
+		wantFirst string
+	}{
+		{
+			name: "lowest precedence first",
+			files: []string{"project/tool.yaml", "user/config.yaml", "system/tool.json"},
+			want: []File{
+				{Path: "system/tool.json", Scope: ScopeSystem},
+				{Path: "user/config.yaml", Scope: ScopeUser},
+				{Path: "project/tool.yaml", Scope: ScopeProject},
+			},
+			wantFirst: "project/tool.yaml",
+		},
+		{
+			name:      "one file per location, config before the file name",
+			files:     []string{"user/config.yml", "user/tool.yaml"},
+			want:      []File{{Path: "user/config.yml", Scope: ScopeUser}},
+			wantFirst: "user/config.yml",
+		},
+		{
+			name:  "project location only searches the file name",
+			files: []string{"project/config.yaml"},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(dir, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				if err := os.WriteFile(path, []byte("name: x\n"), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+			
+			names := []string{"config", "tool"}
+			loader := NewLocationLoader("tool",
+				SearchLocation{Scope: ScopeProject, Dir: filepath.Join(dir, "project"), Names: []string{"tool"}},
+				SearchLocation{Scope: ScopeUser, Dir: filepath.Join(dir, "user"), Names: names},
+				SearchLocation{Scope: ScopeSystem, Dir: filepath.Join(dir, "system"), Names: names},
+			)
+			
+			var got []File
+			for _, file := range loader.FindFiles() {
+				rel, _ := filepath.Rel(dir, file.Path)
+				got = append(got, File{Path: rel, Scope: file.Scope})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindFiles() = %+v, want %+v", got, tt.want)
+			}
+			
+			first, err := loader.FindFile()
+			if err != nil {
+				t.Fatalf("FindFile() error = %v", err)
+			}
+			if first != "" {
+				first, _ = filepath.Rel(dir, first)
+			}
+			if first != tt.wantFirst {
+				t.Errorf("FindFile() = %s, want %s", first, tt.wantFirst)
+			}
+			
+			if want := filepath.Join(dir, "project", "tool.yaml"); loader.DefaultPath() != want {
+				t.Errorf("DefaultPath() = %s, want %s", loader.DefaultPath(), want)
+			}
+		})
+	}
+}
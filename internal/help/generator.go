@@ -6,8 +6,10 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/eugener/clix/internal/bind"
+	"github.com/eugener/clix/internal/posix"
 )
 
 // HelpConfig configures help generation
@@ -36,8 +38,11 @@ func DefaultHelpConfig(programName string) *HelpConfig {
 
 // Generator generates help text for commands
 type Generator struct {
-	config   *HelpConfig
-	analyzer *bind.Analyzer
+	config      *HelpConfig
+	analyzer    *bind.Analyzer
+	globalFlags []FlagHelp
+	topics      []TopicHelp
+	dialect     posix.Dialect
 }
 
 // NewGenerator creates a new help generator
@@ -48,9 +53,25 @@ func NewGenerator(config *HelpConfig) *Generator {
 	return &Generator{
 		config:   config,
 		analyzer: bind.NewAnalyzer("posix"),
+		dialect:  posix.POSIXDialect{},
 	}
 }
 
+// SetDialect sets the flag syntax used to render flags
+func (g *Generator) SetDialect(dialect posix.Dialect) {
+	g.dialect = dialect
+}
+
+// AddGlobalFlags adds application-level flags to the main help
+func (g *Generator) AddGlobalFlags(flags ...FlagHelp) {
+	g.globalFlags = append(g.globalFlags, flags...)
+}
+
+// AddTopics adds help topics to the main help
+func (g *Generator) AddTopics(topics ...TopicHelp) {
+	g.topics = append(g.topics, topics...)
+}
+
 // GenerateMainHelp generates help for the main CLI
 func (g *Generator) GenerateMainHelp(commands map[string]CommandInfo) string {
 	var sb strings.Builder
@@ -93,10 +114,39 @@ func (g *Generator) GenerateMainHelp(commands map[string]CommandInfo) string {
 		sb.WriteString("\n")
 	}
 	
-	// Global options (if any)
+	// Global options
+	globals := [][2]string{
+		{g.flagNames(g.dialect, FlagHelp{Short: "h", Long: "help", Type: "bool"}), "Show help"},
+		{g.flagNames(g.dialect, FlagHelp{Short: "v", Long: "version", Type: "bool"}), "Show version"},
+	}
+	for _, flag := range g.globalFlags {
+		globals = append(globals, [2]string{g.flagNames(g.dialect, flag), flag.Description})
+	}
+	
+	width := 0
+	for _, global := range globals {
+		width = max(width, len(global[0]))
+	}
+	
 	sb.WriteString("Global Options:\n")
-	sb.WriteString("  -h, --help     Show help\n")
-	sb.WriteString("  -v, --version  Show version\n\n")
+	for _, global := range globals {
+		sb.WriteString(fmt.Sprintf("  %-*s  %s\n", width, global[0], global[1]))
+	}
+	sb.WriteString("\n")
+	
+	// Help topics, shown with help <topic>
+	if len(g.topics) > 0 {
+		width = 0
+		for _, topic := range g.topics {
+			width = max(width, len(topic.Name))
+		}
+		
+		sb.WriteString("Help Topics:\n")
+		for _, topic := range g.topics {
+			sb.WriteString(fmt.Sprintf("  %-*s  %s\n", width, topic.Name, topic.Summary))
+		}
+		sb.WriteString("\n")
+	}
 	
 	// Footer
 	if g.config.Footer != "" {
@@ -115,13 +165,19 @@ func (g *Generator) GenerateCommandHelp(name string, info CommandInfo) (string,
 		return "", fmt.Errorf("failed to analyze command config: %w", err)
 	}
 	
+	dialect := info.Dialect
+	if dialect == nil {
+		dialect = g.dialect
+	}
+	
 	// Prepare template data
 	data := CommandHelpData{
 		ProgramName: g.config.ProgramName,
 		CommandName: name,
 		Description: info.Description,
 		Usage:       g.buildUsage(name, metadata),
-		Flags:       g.buildFlagsHelp(metadata),
+		Flags:       g.buildFlagsHelp(metadata, "", dialect),
+		Sections:    g.buildSectionsHelp(metadata, dialect),
 		Positional:  g.buildPositionalHelp(metadata),
 		Examples:    info.Examples,
 		MaxWidth:    g.config.MaxWidth,
@@ -151,29 +207,21 @@ func (g *Generator) buildUsage(commandName string, metadata *bind.StructMetadata
 		parts = append(parts, "[options]")
 	}
 	
-	// Add positional arguments
+	// Add positional arguments, e.g. <src> <dst> [files...]
 	for _, field := range metadata.Positional {
-		if field.Type.Kind() == reflect.Slice {
-			parts = append(parts, fmt.Sprintf("[%s...]", strings.ToUpper(field.Name)))
-		} else {
-			if field.Required {
-				parts = append(parts, fmt.Sprintf("<%s>", strings.ToUpper(field.Name)))
-			} else {
-				parts = append(parts, fmt.Sprintf("[%s]", strings.ToUpper(field.Name)))
-			}
-		}
+		parts = append(parts, field.Usage())
 	}
 	
 	return strings.Join(parts, " ")
 }
 
-// buildFlagsHelp builds the flags help section
-func (g *Generator) buildFlagsHelp(metadata *bind.StructMetadata) []FlagHelp {
+// buildFlagsHelp builds the flags help section for the given config section
+func (g *Generator) buildFlagsHelp(metadata *bind.StructMetadata, section string, dialect posix.Dialect) []FlagHelp {
 	var flags []FlagHelp
 	
 	// Collect all flags
 	for _, field := range metadata.Fields {
-		if field.Positional || field.Hidden {
+		if field.Positional || field.Hidden || field.Section != section {
 			continue
 		}
 		
@@ -185,8 +233,21 @@ func (g *Generator) buildFlagsHelp(metadata *bind.StructMetadata) []FlagHelp {
 			Required:    field.Required,
 			Default:     field.Default,
 			Choices:     field.Choices,
+			Section:     field.Section,
+		}
+		
+		// Optional values are attached with =, e.g. --color=always
+		if field.Optional {
+			flag.Optional = true
+			flag.OptionalValue = field.OptionalValue
+		}
+		
+		// Secret values may also be given as -, @file or with --<flag>-file
+		if field.Secret {
+			flag.Type = "secret"
 		}
 		
+		flag.Names = g.flagNames(dialect, flag)
 		flags = append(flags, flag)
 	}
 	
@@ -198,13 +259,33 @@ func (g *Generator) buildFlagsHelp(metadata *bind.StructMetadata) []FlagHelp {
 	return flags
 }
 
+// buildSectionsHelp groups the flags of nested config sections under their section name
+func (g *Generator) buildSectionsHelp(metadata *bind.StructMetadata, dialect posix.Dialect) []SectionHelp {
+	var sections []SectionHelp
+	
+	for _, section := range metadata.Sections {
+		flags := g.buildFlagsHelp(metadata, section.Path, dialect)
+		if len(flags) == 0 {
+			continue
+		}
+		
+		sections = append(sections, SectionHelp{
+			Name:        section.Path,
+			Description: section.Description,
+			Flags:       flags,
+		})
+	}
+	
+	return sections
+}
+
 // buildPositionalHelp builds the positional arguments help section
 func (g *Generator) buildPositionalHelp(metadata *bind.StructMetadata) []PositionalHelp {
 	var positional []PositionalHelp
 	
 	for _, field := range metadata.Positional {
 		pos := PositionalHelp{
-			Name:        field.Name,
+			Name:        field.Usage(),
 			Description: field.Description,
 			Type:        g.getTypeString(field.Type),
 			Required:    field.Required,
@@ -218,6 +299,10 @@ func (g *Generator) buildPositionalHelp(metadata *bind.StructMetadata) []Positio
 
 // getTypeString returns a human-readable type string
 func (g *Generator) getTypeString(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	
 	switch t.Kind() {
 	case reflect.String:
 		return "string"
@@ -230,6 +315,9 @@ func (g *Generator) getTypeString(t reflect.Type) string {
 	case reflect.Float32, reflect.Float64:
 		return "float"
 	case reflect.Slice:
+		if !bind.IsListType(t) {
+			return t.String()
+		}
 		elemType := g.getTypeString(t.Elem())
 		return fmt.Sprintf("[]%s", elemType)
 	default:
@@ -237,25 +325,34 @@ func (g *Generator) getTypeString(t reflect.Type) string {
 	}
 }
 
-// FormatFlag formats a single flag for display
-func (g *Generator) FormatFlag(flag FlagHelp) string {
-	var parts []string
-	
-	// Build flag part
-	if flag.Short != "" && flag.Long != "" {
-		parts = append(parts, fmt.Sprintf("-%s, --%s", flag.Short, flag.Long))
-	} else if flag.Short != "" {
-		parts = append(parts, fmt.Sprintf("-%s", flag.Short))
-	} else {
-		parts = append(parts, fmt.Sprintf("--%s", flag.Long))
+// flagNames renders the names and value placeholder of a flag in the dialect, e.g.
+// -p, --port <int>; flags without a short name are aligned with those that have one
+func (g *Generator) flagNames(dialect posix.Dialect, flag FlagHelp) string {
+	var names string
+	switch {
+	case flag.Short != "" && flag.Long != "":
+		names = dialect.FlagName(flag.Short, true) + ", " + dialect.FlagName(flag.Long, false)
+	case flag.Short != "":
+		names = dialect.FlagName(flag.Short, true)
+	default:
+		names = dialect.FlagName(flag.Long, false)
 	}
 	
-	// Add type if not boolean
-	if flag.Type != "bool" {
-		parts = append(parts, fmt.Sprintf("<%s>", flag.Type))
+	if flag.Optional {
+		names += dialect.FlagValue(flag.Type, true)
+	} else if flag.Type != "bool" {
+		names += dialect.FlagValue(flag.Type, false)
 	}
 	
-	flagStr := strings.Join(parts, " ")
+	return names
+}
+
+// FormatFlag formats a single flag for display
+func (g *Generator) FormatFlag(flag FlagHelp) string {
+	flagStr := flag.Names
+	if flagStr == "" {
+		flagStr = g.flagNames(g.dialect, flag)
+	}
 	
 	// Build description part
 	var descParts []string
@@ -271,6 +368,10 @@ func (g *Generator) FormatFlag(flag FlagHelp) string {
 		descParts = append(descParts, fmt.Sprintf("(default: %s)", flag.Default))
 	}
 	
+	if flag.Optional {
+		descParts = append(descParts, fmt.Sprintf("(without value: %s)", flag.OptionalValue))
+	}
+	
 	if len(flag.Choices) > 0 {
 		descParts = append(descParts, fmt.Sprintf("(choices: %s)", strings.Join(flag.Choices, ", ")))
 	}
@@ -296,6 +397,7 @@ type CommandInfo struct {
 	Description string
 	ConfigType  reflect.Type
 	Examples    []string
+	Dialect     posix.Dialect // Flag syntax of the command, the generator's when nil
 }
 
 // CommandHelpData contains data for command help template
@@ -305,11 +407,19 @@ type CommandHelpData struct {
 	Description string
 	Usage       string
 	Flags       []FlagHelp
+	Sections    []SectionHelp
 	Positional  []PositionalHelp
 	Examples    []string
 	MaxWidth    int
 }
 
+// SectionHelp contains the flags of a nested config section
+type SectionHelp struct {
+	Name        string
+	Description string
+	Flags       []FlagHelp
+}
+
 // FlagHelp contains flag help information
 type FlagHelp struct {
 	Short       string
@@ -319,6 +429,20 @@ type FlagHelp struct {
 	Required    bool
 	Default     string
 	Choices     []string
+	Section     string
+	
+	// Optional flags may be given without a value, which then is OptionalValue
+	Optional      bool
+	OptionalValue string
+	
+	// Names renders the flag in the command's dialect, e.g. -p, --port <int>
+	Names string
+}
+
+// TopicHelp is a help topic listed in the main help
+type TopicHelp struct {
+	Name    string
+	Summary string
 }
 
 // PositionalHelp contains positional argument help information
@@ -335,29 +459,34 @@ const DefaultUsageTemplate = `{{.Description}}
 Usage:
   {{.Usage}}
 
-{{- if .Flags}}
-
-Options:
-{{- range .Flags}}
-  {{- if .Short}}
-  -{{.Short}}, --{{.Long}}{{if ne .Type "bool"}} <{{.Type}}>{{end}}
-  {{- else}}
-      --{{.Long}}{{if ne .Type "bool"}} <{{.Type}}>{{end}}
-  {{- end}}
+{{- define "flag"}}
+  {{if not .Short}}    {{end}}{{.Names}}
     {{- if .Description}}
         {{.Description}}
     {{- end}}
     {{- if .Required}} (required){{end}}
     {{- if .Default}} (default: {{.Default}}){{end}}
+    {{- if .Optional}} (without value: {{.OptionalValue}}){{end}}
     {{- if .Choices}} (choices: {{range $i, $c := .Choices}}{{if $i}}, {{end}}{{$c}}{{end}}){{end}}
 {{- end}}
+
+{{- if .Flags}}
+
+Options:
+{{- range .Flags}}{{template "flag" .}}{{end}}
+{{- end}}
+
+{{- range .Sections}}
+
+{{.Name}}:{{if .Description}} {{.Description}}{{end}}
+{{- range .Flags}}{{template "flag" .}}{{end}}
 {{- end}}
 
 {{- if .Positional}}
 
 Arguments:
 {{- range .Positional}}
-  {{.Name}}{{if ne .Type "string"}} <{{.Type}}>{{end}}
+  {{.Name}}{{if and (ne .Type "string") (ne .Type "[]string")}} ({{.Type}}){{end}}
     {{- if .Description}}
         {{.Description}}
     {{- end}}
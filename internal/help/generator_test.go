@@ -0,0 +1,36 @@
+package help
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandHelpPositionals(t *testing.T) {
+	type copyConfig struct {
+		Verbose bool          `posix:"v,verbose,Verbose"`
+		Src     string        `posix:",src,Source file,positional|required"`
+		Wait    time.Duration `posix:",wait,Time to wait,positional"`
+		Files   []string      `posix:",files,More sources,positional"`
+	}
+	
+	text, err := NewGenerator(DefaultHelpConfig("tool")).GenerateCommandHelp("cp", CommandInfo{
+		Description: "Copy files",
+		ConfigType:  reflect.TypeOf(copyConfig{}),
+	})
+	if err != nil {
+		t.Fatalf("GenerateCommandHelp() error = %v", err)
+	}
+	
+	for _, want := range []string{
+		"tool cp [options] <src> [wait] [files...]",
+		"<src>\n        Source file (required)",
+		"[wait] (duration)\n        Time to wait",
+		"[files...]\n        More sources",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("GenerateCommandHelp() missing %q in:\n%s", want, text)
+		}
+	}
+}
@@ -74,7 +74,7 @@ func (v *Validator) Validate(config any) error {
 	
 	// Validate each field
 	for _, fieldInfo := range metadata.Fields {
-		field := configValue.FieldByName(fieldInfo.Name)
+		field := configValue.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() {
 			continue
 		}
@@ -160,30 +160,33 @@ func (v *Validator) validateChoices(fieldInfo bind.FieldInfo, value any) error {
 
 // validateString validates string fields
 func (v *Validator) validateString(fieldInfo bind.FieldInfo, value string) error {
-	// Add string-specific validation rules here
-	// For example: min/max length, regex patterns, etc.
-	
-	return nil
+	return v.checkRules(fieldInfo, value)
 }
 
 // validateInt validates integer fields
 func (v *Validator) validateInt(fieldInfo bind.FieldInfo, value int64) error {
-	// Add integer-specific validation rules here
-	// For example: min/max values, ranges, etc.
-	
-	return nil
+	return v.checkRules(fieldInfo, value)
 }
 
 // validateUint validates unsigned integer fields
 func (v *Validator) validateUint(fieldInfo bind.FieldInfo, value uint64) error {
-	// Add unsigned integer-specific validation rules here
-	
-	return nil
+	return v.checkRules(fieldInfo, value)
 }
 
 // validateFloat validates float fields
 func (v *Validator) validateFloat(fieldInfo bind.FieldInfo, value float64) error {
-	// Add float-specific validation rules here
+	return v.checkRules(fieldInfo, value)
+}
+
+// checkRules applies the field's min, max and pattern rules
+func (v *Validator) checkRules(fieldInfo bind.FieldInfo, value any) error {
+	if err := fieldInfo.CheckValue(reflect.ValueOf(value)); err != nil {
+		return &ValidationError{
+			Field:   fieldInfo.Name,
+			Value:   value,
+			Message: err.Error(),
+		}
+	}
 	
 	return nil
 }
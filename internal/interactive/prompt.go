@@ -47,7 +47,7 @@ func (p *Prompter) PromptForMissing(target any) error {
 			continue
 		}
 		
-		field := targetStruct.FieldByName(fieldInfo.Name)
+		field := targetStruct.FieldByIndex(fieldInfo.Index)
 		if !field.IsValid() || !field.CanSet() {
 			continue
 		}
@@ -0,0 +1,62 @@
+package posix
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AmbiguousError reports an abbreviation matching several names
+type AmbiguousError struct {
+	Input      string
+	Candidates []string
+}
+
+// Error lists the names the abbreviation could stand for
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("ambiguous %s, could be: %s", e.Input, strings.Join(e.Candidates, ", "))
+}
+
+// MatchPrefix returns the name matching input exactly, or the only name starting with
+// input. It returns an empty string when nothing matches, and the sorted matching names
+// when input is ambiguous.
+func MatchPrefix(input string, names []string) (string, []string) {
+	var matches []string
+	for _, name := range names {
+		if name == input {
+			return name, nil
+		}
+		if input != "" && strings.HasPrefix(name, input) {
+			matches = append(matches, name)
+		}
+	}
+	
+	sort.Strings(matches)
+	matches = slices.Compact(matches)
+	
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	}
+	
+	return "", matches
+}
+
+// matchLongFlag resolves an abbreviated long flag name among names. The name is
+// returned unchanged when nothing matches, so unknown flags are reported as before.
+func matchLongFlag(flagName string, names []string) (string, error) {
+	match, candidates := MatchPrefix(flagName, names)
+	if len(candidates) > 0 {
+		for i, candidate := range candidates {
+			candidates[i] = "--" + candidate
+		}
+		return "", &AmbiguousError{Input: "flag --" + flagName, Candidates: candidates}
+	}
+	if match == "" {
+		return flagName, nil
+	}
+	return match, nil
+}
@@ -0,0 +1,68 @@
+package posix
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMatchPrefix(t *testing.T) {
+	names := []string{"deploy", "delete", "describe", "status", "stat"}
+	
+	tests := []struct {
+		input          string
+		wantMatch      string
+		wantCandidates []string
+	}{
+		{input: "deploy", wantMatch: "deploy"},
+		{input: "dep", wantMatch: "deploy"},
+		{input: "desc", wantMatch: "describe"},
+		{input: "stat", wantMatch: "stat"},
+		{input: "statu", wantMatch: "status"},
+		{input: "de", wantCandidates: []string{"delete", "deploy", "describe"}},
+		{input: "x"},
+		{input: ""},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			match, candidates := MatchPrefix(tt.input, names)
+			if match != tt.wantMatch || !reflect.DeepEqual(candidates, tt.wantCandidates) {
+				t.Errorf("MatchPrefix(%q) = %q, %v, want %q, %v", tt.input, match, candidates, tt.wantMatch, tt.wantCandidates)
+			}
+		})
+	}
+}
+
+func TestMatchLongFlag(t *testing.T) {
+	names := []string{"verbose", "version", "name", "database.host"}
+	
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "verb", want: "verbose"},
+		{input: "vers", want: "version"},
+		{input: "name", want: "name"},
+		{input: "data", want: "database.host"},
+		{input: "unknown", want: "unknown"},
+		{input: "ver", wantErr: "ambiguous flag --ver, could be: --verbose, --version"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := matchLongFlag(tt.input, names)
+			if tt.wantErr != "" {
+				var ambiguous *AmbiguousError
+				if !errors.As(err, &ambiguous) || err.Error() != tt.wantErr {
+					t.Fatalf("matchLongFlag() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("matchLongFlag() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package posix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ArgsFilePrefix marks an argument naming a response file whose contents replace it
+const ArgsFilePrefix = "@"
+
+// ArgsFileExpander replaces @file arguments with the arguments read from the file.
+// Files contain shell-quoted arguments separated by whitespace, so one argument per
+// line works as is; lines starting with # are comments. Files may reference other
+// files, relative to their own directory. Expansion stops at --.
+type ArgsFileExpander struct {
+	// Keep reports whether the argument at index i is taken literally, e.g. the
+	// value of a flag that reads @file itself
+	Keep func(args []string, i int) bool
+}
+
+// NewArgsFileExpander creates a response file expander
+func NewArgsFileExpander() *ArgsFileExpander {
+	return &ArgsFileExpander{}
+}
+
+// IsArgsFile reports whether arg names a response file; @@ and a lone @ do not
+func IsArgsFile(arg string) bool {
+	return len(arg) > 1 && strings.HasPrefix(arg, ArgsFilePrefix) && !strings.HasPrefix(arg[1:], ArgsFilePrefix)
+}
+
+// Expand returns args with every response file expanded
+func (e *ArgsFileExpander) Expand(args []string) ([]string, error) {
+	endOfFlags := false
+	return e.expand(args, "", nil, &endOfFlags)
+}
+
+// expand expands args read from the file in dir, tracking the files being read in stack
+func (e *ArgsFileExpander) expand(args []string, dir string, stack []string, endOfFlags *bool) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	
+	for i, arg := range args {
+		if arg == "--" {
+			*endOfFlags = true
+		}
+		
+		if *endOfFlags || !strings.HasPrefix(arg, ArgsFilePrefix) || (e.Keep != nil && e.Keep(args, i)) {
+			expanded = append(expanded, arg)
+			continue
+		}
+		
+		// @@ escapes a literal leading @, a lone @ is kept
+		if !IsArgsFile(arg) {
+			if arg != ArgsFilePrefix {
+				arg = arg[len(ArgsFilePrefix):]
+			}
+			expanded = append(expanded, arg)
+			continue
+		}
+		
+		path := arg[len(ArgsFilePrefix):]
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve args file %s: %w", path, err)
+		}
+		if slices.Contains(stack, absPath) {
+			return nil, fmt.Errorf("args file cycle: %s -> %s", strings.Join(stack, " -> "), absPath)
+		}
+		
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read args file: %w", err)
+		}
+		
+		fileArgs, err := SplitArgs(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("args file %s: %w", path, err)
+		}
+		
+		nested, err := e.expand(fileArgs, filepath.Dir(path), append(slices.Clone(stack), absPath), endOfFlags)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, nested...)
+	}
+	
+	return expanded, nil
+}
+
+// SplitArgs splits text into arguments the way a POSIX shell does, without expansions:
+// whitespace separates arguments, single quotes are literal, double quotes allow
+// backslash escapes and # starts a comment at the beginning of a word
+func SplitArgs(text string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inWord := false
+	
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case c == '#' && !inWord:
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(text[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' && i+1 < len(text) && strings.IndexByte("\"\\$`\n", text[i+1]) >= 0 {
+					i++
+				}
+				current.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case c == '\\' && i+1 < len(text):
+			i++
+			if text[i] != '\n' {
+				current.WriteByte(text[i])
+				inWord = true
+			}
+		default:
+			current.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
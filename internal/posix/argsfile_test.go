@@ -0,0 +1,149 @@
+package posix
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr string
+	}{
+		{name: "whitespace", text: "a  b\tc\nd\r\n", want: []string{"a", "b", "c", "d"}},
+		{name: "empty", text: "  \n"},
+		{name: "single quotes are literal", text: `'a b' 'x\"y'`, want: []string{"a b", `x\"y`}},
+		{name: "double quotes with escapes", text: `"a \"b\" \\ \$c \n"`, want: []string{`a "b" \ $c \n`}},
+		{name: "quotes join a word", text: `--name="a b"'c'`, want: []string{"--name=a bc"}},
+		{name: "empty quoted argument", text: `"" ''`, want: []string{"", ""}},
+		{name: "backslash escapes", text: `a\ b c\\d`, want: []string{"a b", `c\d`}},
+		{name: "line continuation", text: "a\\\nb", want: []string{"ab"}},
+		{name: "comments", text: "# header\n--verbose # trailing\nvalue#hash\n", want: []string{"--verbose", "value#hash"}},
+		{name: "unterminated single quote", text: "'abc", wantErr: "unterminated single quote"},
+		{name: "unterminated double quote", text: `"abc`, wantErr: "unterminated double quote"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitArgs(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("SplitArgs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitArgs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgsFileExpander(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		args    []string
+		keep    func(args []string, i int) bool
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "file replaces the argument",
+			files: map[string]string{"build.args": "--verbose\n--name 'my app'\n"},
+			args:  []string{"build", "@build.args", "-x"},
+			want:  []string{"build", "--verbose", "--name", "my app", "-x"},
+		},
+		{
+			name: "nested files are relative to their file",
+			files: map[string]string{
+				"main.args":       "@sub/common.args --main",
+				"sub/common.args": "--common @more.args",
+				"sub/more.args":   "--more",
+			},
+			args: []string{"@main.args"},
+			want: []string{"--common", "--more", "--main"},
+		},
+		{
+			name: "escaped and lone at signs",
+			args: []string{"@@latest", "@"},
+			want: []string{"@latest", "@"},
+		},
+		{
+			name:  "expansion stops at --",
+			files: map[string]string{"a.args": "--a"},
+			args:  []string{"@a.args", "--", "@a.args"},
+			want:  []string{"--a", "--", "@a.args"},
+		},
+		{
+			name:  "-- inside a file ends expansion",
+			files: map[string]string{"a.args": "-- @b.args", "b.args": "--b"},
+			args:  []string{"@a.args", "@b.args"},
+			want:  []string{"--", "@b.args", "@b.args"},
+		},
+		{
+			name:  "kept arguments",
+			files: map[string]string{"a.args": "--a"},
+			args:  []string{"--token", "@a.args", "@a.args"},
+			keep:  func(args []string, i int) bool { return i > 0 && args[i-1] == "--token" },
+			want:  []string{"--token", "@a.args", "--a"},
+		},
+		{
+			name:    "missing file",
+			args:    []string{"@missing.args"},
+			wantErr: "failed to read args file",
+		},
+		{
+			name:    "cycle",
+			files:   map[string]string{"a.args": "@b.args", "b.args": "@a.args"},
+			args:    []string{"@a.args"},
+			wantErr: "args file cycle",
+		},
+		{
+			name:    "invalid quoting",
+			files:   map[string]string{"a.args": "'open"},
+			args:    []string{"@a.args"},
+			wantErr: "unterminated single quote",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+			t.Chdir(dir)
+			
+			expander := NewArgsFileExpander()
+			expander.Keep = tt.keep
+			got, err := expander.Expand(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expand() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package posix
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Dialect is a command line flag syntax. Arguments are normalized to POSIX form one
+// at a time as the parser reaches them, so flag values and arguments after the end
+// of flags are never rewritten.
+type Dialect interface {
+	// Normalize returns the POSIX form of an argument in flag position. isFlag reports
+	// whether a flag name, without dashes, is defined.
+	Normalize(arg string, isFlag func(name string) bool) string
+	
+	// FlagName renders a flag name for help and errors, short for one-character names
+	FlagName(name string, short bool) string
+	
+	// FlagValue renders the value placeholder of a flag, e.g. " <int>"
+	FlagValue(valueType string, optional bool) string
+}
+
+// POSIXDialect is the POSIX/GNU syntax: -v, -n5, --name value, --name=value
+type POSIXDialect struct{}
+
+// Normalize returns arg unchanged
+func (POSIXDialect) Normalize(arg string, isFlag func(name string) bool) string {
+	return arg
+}
+
+// FlagName renders -n or --name
+func (POSIXDialect) FlagName(name string, short bool) string {
+	if short {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// FlagValue renders " <type>", or "[=<type>]" for an optional value
+func (POSIXDialect) FlagValue(valueType string, optional bool) string {
+	if optional {
+		return "[=<" + valueType + ">]"
+	}
+	return " <" + valueType + ">"
+}
+
+// GoDialect is the syntax of Go's flag package: -name value, -name=value. Short
+// flags are single-dash too, without bundling; --name is accepted as well.
+type GoDialect struct{}
+
+// Normalize turns -name into --name
+func (GoDialect) Normalize(arg string, isFlag func(name string) bool) string {
+	if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && !isNegativeNumber(arg) {
+		if name, _, _ := strings.Cut(arg[1:], "="); len([]rune(name)) > 1 {
+			return "-" + arg
+		}
+	}
+	return arg
+}
+
+// FlagName renders -n or -name
+func (GoDialect) FlagName(name string, short bool) string {
+	return "-" + name
+}
+
+// FlagValue renders " <type>", or "[=<type>]" for an optional value
+func (GoDialect) FlagValue(valueType string, optional bool) string {
+	return POSIXDialect{}.FlagValue(valueType, optional)
+}
+
+// slashFlag matches a slash-style flag with an optional :value or =value
+var slashFlag = regexp.MustCompile(`^/([A-Za-z][A-Za-z0-9_.-]*)(?:[:=](.*))?$`)
+
+// SlashDialect is the Windows syntax: /name, /name:value, /? for help. Only defined
+// flags are taken as flags, so paths such as /tmp or /tmp/file are positional; POSIX
+// flags are accepted as well.
+type SlashDialect struct{}
+
+// Normalize turns /name:value into --name=value when name is a defined flag, and /? into --help
+func (SlashDialect) Normalize(arg string, isFlag func(name string) bool) string {
+	if arg == "/?" {
+		return "--help"
+	}
+	
+	match := slashFlag.FindStringSubmatch(arg)
+	if match == nil || !isFlag(match[1]) {
+		return arg
+	}
+	
+	name := POSIXDialect{}.FlagName(match[1], len([]rune(match[1])) == 1)
+	if strings.ContainsAny(arg, ":=") {
+		return name + "=" + match[2]
+	}
+	return name
+}
+
+// FlagName renders /n or /name
+func (SlashDialect) FlagName(name string, short bool) string {
+	return "/" + name
+}
+
+// FlagValue renders ":<type>", or "[:<type>]" for an optional value
+func (SlashDialect) FlagValue(valueType string, optional bool) string {
+	if optional {
+		return "[:<" + valueType + ">]"
+	}
+	return ":<" + valueType + ">"
+}
+
+// POSIXFlag converts a flag in POSIX form, e.g. --name, to the dialect
+func POSIXFlag(dialect Dialect, flag string) string {
+	if dialect == nil {
+		return flag
+	}
+	if name, found := strings.CutPrefix(flag, "--"); found {
+		return dialect.FlagName(name, false)
+	}
+	return dialect.FlagName(strings.TrimPrefix(flag, "-"), true)
+}
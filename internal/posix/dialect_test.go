@@ -0,0 +1,140 @@
+package posix
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDialectNormalize(t *testing.T) {
+	isFlag := func(name string) bool {
+		return slices.Contains([]string{"count", "v", "name"}, name)
+	}
+	
+	tests := []struct {
+		name    string
+		dialect Dialect
+		arg     string
+		want    string
+	}{
+		{name: "posix unchanged", dialect: POSIXDialect{}, arg: "-count", want: "-count"},
+		{name: "go long flag", dialect: GoDialect{}, arg: "-count", want: "--count"},
+		{name: "go long flag with value", dialect: GoDialect{}, arg: "-count=5", want: "--count=5"},
+		{name: "go short flag", dialect: GoDialect{}, arg: "-v", want: "-v"},
+		{name: "go double dash", dialect: GoDialect{}, arg: "--count", want: "--count"},
+		{name: "go negative number", dialect: GoDialect{}, arg: "-15", want: "-15"},
+		{name: "slash help", dialect: SlashDialect{}, arg: "/?", want: "--help"},
+		{name: "slash long flag", dialect: SlashDialect{}, arg: "/count", want: "--count"},
+		{name: "slash colon value", dialect: SlashDialect{}, arg: "/count:5", want: "--count=5"},
+		{name: "slash equals value", dialect: SlashDialect{}, arg: "/name=a:b", want: "--name=a:b"},
+		{name: "slash empty value", dialect: SlashDialect{}, arg: "/name:", want: "--name="},
+		{name: "slash short flag", dialect: SlashDialect{}, arg: "/v", want: "-v"},
+		{name: "slash unknown name is a path", dialect: SlashDialect{}, arg: "/tmp", want: "/tmp"},
+		{name: "slash path", dialect: SlashDialect{}, arg: "/tmp/dst", want: "/tmp/dst"},
+		{name: "slash path under a flag name", dialect: SlashDialect{}, arg: "/count/file", want: "/count/file"},
+		{name: "slash drive path", dialect: SlashDialect{}, arg: "C:/src", want: "C:/src"},
+		{name: "slash posix flag", dialect: SlashDialect{}, arg: "--count=5", want: "--count=5"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Normalize(tt.arg, isFlag); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDialectRendering(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		flag      string
+		short     bool
+		optional  bool
+		wantName  string
+		wantValue string
+	}{
+		{name: "posix long", dialect: POSIXDialect{}, flag: "count", wantName: "--count", wantValue: " <int>"},
+		{name: "posix short", dialect: POSIXDialect{}, flag: "c", short: true, wantName: "-c", wantValue: " <int>"},
+		{name: "posix optional", dialect: POSIXDialect{}, flag: "count", optional: true, wantName: "--count", wantValue: "[=<int>]"},
+		{name: "go long", dialect: GoDialect{}, flag: "count", wantName: "-count", wantValue: " <int>"},
+		{name: "slash long", dialect: SlashDialect{}, flag: "count", wantName: "/count", wantValue: ":<int>"},
+		{name: "slash optional", dialect: SlashDialect{}, flag: "count", optional: true, wantName: "/count", wantValue: "[:<int>]"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.FlagName(tt.flag, tt.short); got != tt.wantName {
+				t.Errorf("FlagName(%q) = %q, want %q", tt.flag, got, tt.wantName)
+			}
+			if got := tt.dialect.FlagValue("int", tt.optional); got != tt.wantValue {
+				t.Errorf("FlagValue() = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+	
+	if got := POSIXFlag(SlashDialect{}, "--count"); got != "/count" {
+		t.Errorf("POSIXFlag(--count) = %q, want /count", got)
+	}
+	if got := POSIXFlag(GoDialect{}, "-v"); got != "-v" {
+		t.Errorf("POSIXFlag(-v) = %q, want -v", got)
+	}
+}
+
+func TestDialectParse(t *testing.T) {
+	tests := []struct {
+		name           string
+		dialect        Dialect
+		args           []string
+		wantFlags      map[string]any
+		wantPositional []string
+	}{
+		{
+			name:           "go",
+			dialect:        GoDialect{},
+			args:           []string{"-name", "web", "-replicas=2", "-v", "file"},
+			wantFlags:      map[string]any{"name": "web", "replicas": 2, "verbose": true},
+			wantPositional: []string{"file"},
+		},
+		{
+			name:           "slash",
+			dialect:        SlashDialect{},
+			args:           []string{"/name:web", "/v", "/tmp", "/replicas", "2", "/tmp/dst"},
+			wantFlags:      map[string]any{"name": "web", "replicas": 2, "verbose": true},
+			wantPositional: []string{"/tmp", "/tmp/dst"},
+		},
+		{
+			name:           "slash values are not rewritten",
+			dialect:        SlashDialect{},
+			args:           []string{"/name", "/verbose"},
+			wantFlags:      map[string]any{"name": "/verbose"},
+			wantPositional: []string{},
+		},
+		{
+			name:           "slash after end of flags",
+			dialect:        SlashDialect{},
+			args:           []string{"--", "/v"},
+			wantFlags:      map[string]any{},
+			wantPositional: []string{"/v"},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newScanTestParser(true)
+			parser.SetDialect(tt.dialect)
+			
+			result, err := parser.Parse(tt.args)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(result.Flags, tt.wantFlags) {
+				t.Errorf("Parse(%q) flags = %v, want %v", tt.args, result.Flags, tt.wantFlags)
+			}
+			if !reflect.DeepEqual(result.Positional, tt.wantPositional) {
+				t.Errorf("Parse(%q) positional = %q, want %q", tt.args, result.Positional, tt.wantPositional)
+			}
+		})
+	}
+}
@@ -20,121 +20,9 @@ func NewPOSIXParser() *POSIXParser {
 	return &POSIXParser{}
 }
 
-// Parse parses command line arguments in POSIX style
+// Parse parses command line arguments in POSIX style, guessing whether flags take values
 func (p *POSIXParser) Parse(args []string) (*ParseResult, error) {
-	result := &ParseResult{
-		Flags:      make(map[string]any),
-		Positional: make([]string, 0),
-		Remaining:  make([]string, 0),
-	}
-	
-	i := 0
-	endOfFlags := false
-	
-	for i < len(args) {
-		arg := args[i]
-		
-		// Handle -- (end of flags)
-		if arg == "--" {
-			endOfFlags = true
-			i++
-			continue
-		}
-		
-		// Handle positional arguments after --
-		if endOfFlags || !strings.HasPrefix(arg, "-") {
-			result.Positional = append(result.Positional, arg)
-			i++
-			continue
-		}
-		
-		// Handle single - (stdin/stdout indicator)
-		if arg == "-" {
-			result.Positional = append(result.Positional, arg)
-			i++
-			continue
-		}
-		
-		// Handle long flags --flag or --flag=value
-		if strings.HasPrefix(arg, "--") {
-			if err := p.parseLongFlag(args, &i, result); err != nil {
-				return nil, err
-			}
-			continue
-		}
-		
-		// Handle short flags -f or -abc (bundled)
-		if strings.HasPrefix(arg, "-") {
-			if err := p.parseShortFlags(args, &i, result); err != nil {
-				return nil, err
-			}
-			continue
-		}
-		
-		i++
-	}
-	
-	return result, nil
-}
-
-// parseLongFlag handles --flag and --flag=value forms
-func (p *POSIXParser) parseLongFlag(args []string, i *int, result *ParseResult) error {
-	arg := args[*i]
-	flagName := arg[2:] // Remove --
-	
-	var value any = true // Default for boolean flags
-	hasValue := false
-	
-	// Check for --flag=value form
-	if eqIndex := strings.Index(flagName, "="); eqIndex != -1 {
-		value = flagName[eqIndex+1:]
-		flagName = flagName[:eqIndex]
-		hasValue = true
-	}
-	
-	// If no explicit value and next arg doesn't start with -, use it as value
-	if !hasValue && *i+1 < len(args) && !strings.HasPrefix(args[*i+1], "-") {
-		// Try to determine if next argument is a value or positional
-		// For now, assume it's a value if it exists
-		*i++
-		value = args[*i]
-		hasValue = true
-	}
-	
-	result.Flags[flagName] = value
-	*i++
-	return nil
-}
-
-// parseShortFlags handles -f and -abc (bundled) forms
-func (p *POSIXParser) parseShortFlags(args []string, i *int, result *ParseResult) error {
-	arg := args[*i]
-	flags := arg[1:] // Remove -
-	
-	// Handle each character as a separate flag
-	for j, r := range flags {
-		flagName := string(r)
-		
-		// For bundled flags, all are boolean except possibly the last one
-		if j < len(flags)-1 {
-			// Middle flags in bundle are always boolean
-			result.Flags[flagName] = true
-		} else {
-			// Last flag might take a value
-			var value any = true // Default boolean
-			
-			// Check if next argument could be a value
-			if *i+1 < len(args) && !strings.HasPrefix(args[*i+1], "-") {
-				*i++
-				value = args[*i]
-			}
-			
-			result.Flags[flagName] = value
-		}
-	}
-	
-	*i++
-	return nil
+	return NewConfigurableParser(nil).Parse(args)
 }
 
 // FlagInfo represents metadata about a flag
@@ -147,6 +35,10 @@ type FlagInfo struct {
 	Required    bool
 	Default     any
 	Choices     []string
+	
+	// Optional flags may be given without a value, which then is OptionalValue
+	Optional      bool
+	OptionalValue string
 }
 
 // ParserConfig configures the POSIX parser behavior
@@ -156,6 +48,16 @@ type ParserConfig struct {
 	BooleanFlags  map[string]bool
 	StringFlags   map[string]bool
 	IntegerFlags  map[string]bool
+	
+	// AllowAbbreviations accepts unique prefixes of known long flags, e.g. --verb for --verbose
+	AllowAbbreviations bool
+	
+	// StopAfter is the number of positional arguments after which the remaining
+	// arguments are positional too, 0 to accept flags anywhere
+	StopAfter int
+	
+	// Dialect is the flag syntax, POSIX when nil
+	Dialect Dialect
 }
 
 // ConfigurableParser provides configurable POSIX parsing
@@ -202,176 +104,139 @@ func (cp *ConfigurableParser) AddFlag(info *FlagInfo) {
 	}
 }
 
+// SetAllowAbbreviations enables or disables unique-prefix matching of long flags
+func (cp *ConfigurableParser) SetAllowAbbreviations(enabled bool) {
+	cp.config.AllowAbbreviations = enabled
+}
+
+// SetStrictMode enables or disables rejecting unknown flags
+func (cp *ConfigurableParser) SetStrictMode(enabled bool) {
+	cp.config.StrictMode = enabled
+}
+
+// SetDialect sets the flag syntax
+func (cp *ConfigurableParser) SetDialect(dialect Dialect) {
+	cp.config.Dialect = dialect
+}
+
+// SetStopAfter stops flag parsing after n positional arguments, see StopAfter
+func (cp *ConfigurableParser) SetStopAfter(n int) {
+	cp.config.StopAfter = n
+}
+
 // Parse parses arguments with configuration
 func (cp *ConfigurableParser) Parse(args []string) (*ParseResult, error) {
-	result := &ParseResult{
-		Flags:      make(map[string]any),
-		Positional: make([]string, 0),
-		Remaining:  make([]string, 0),
-	}
+	s := &scanner{lookup: flagLookup{
+		long:   cp.lookupLong,
+		short:  cp.lookupShort,
+		strict: cp.config.StrictMode,
+	}, stopAfter: cp.config.StopAfter, dialect: cp.config.Dialect}
 	
-	i := 0
-	endOfFlags := false
+	result, err := s.scan(args)
+	if err != nil {
+		return nil, err
+	}
 	
-	for i < len(args) {
-		arg := args[i]
-		
-		if arg == "--" {
-			endOfFlags = true
-			i++
+	// Convert values based on type
+	for flagName, value := range result.Flags {
+		str, isString := value.(string)
+		if !isString {
 			continue
 		}
 		
-		if endOfFlags || !strings.HasPrefix(arg, "-") {
-			result.Positional = append(result.Positional, arg)
-			i++
-			continue
-		}
-		
-		if arg == "-" {
-			result.Positional = append(result.Positional, arg)
-			i++
-			continue
-		}
-		
-		if strings.HasPrefix(arg, "--") {
-			if err := cp.parseConfiguredLongFlag(args, &i, result); err != nil {
-				return nil, err
-			}
-			continue
-		}
-		
-		if strings.HasPrefix(arg, "-") {
-			if err := cp.parseConfiguredShortFlags(args, &i, result); err != nil {
-				return nil, err
-			}
-			continue
+		convertedValue, err := cp.convertValue(str, cp.config.KnownFlags[flagName])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for flag --%s: %w", flagName, err)
 		}
-		
-		i++
+		result.Flags[flagName] = convertedValue
 	}
 	
 	return result, nil
 }
 
-// parseConfiguredLongFlag handles long flags with configuration
-func (cp *ConfigurableParser) parseConfiguredLongFlag(args []string, i *int, result *ParseResult) error {
-	arg := args[*i]
-	flagName := arg[2:]
-	
-	var value string
-	hasValue := false
-	
-	if eqIndex := strings.Index(flagName, "="); eqIndex != -1 {
-		value = flagName[eqIndex+1:]
-		flagName = flagName[:eqIndex]
-		hasValue = true
-	}
-	
-	// Check if flag is known
-	flagInfo, known := cp.config.KnownFlags[flagName]
-	if cp.config.StrictMode && !known {
-		return fmt.Errorf("unknown flag: --%s", flagName)
+// FlagsEnd returns the index of the first argument that is not parsed for flags: the --
+// separator, or the argument after the positional stopping flag parsing
+func (cp *ConfigurableParser) FlagsEnd(args []string) int {
+	s := cp.lenientScanner()
+	if _, err := s.scan(args); err != nil {
+		return len(args)
 	}
-	
-	// Determine flag type - default to string if unknown
-	isBool := false
-	if known {
-		isBool = cp.config.BooleanFlags[flagName]
-		if flagInfo != nil {
-			isBool = flagInfo.Type == "bool"
-		}
-	} else {
-		// For unknown flags, check if next argument looks like a value
-		if !hasValue && *i+1 < len(args) && !strings.HasPrefix(args[*i+1], "-") {
-			// Treat as string flag
-			isBool = false
-		} else {
-			// Treat as boolean flag
-			isBool = true
+	return s.end
+}
+
+// Normalize returns args with the flags written in the dialect converted to POSIX form,
+// leaving flag values and arguments after the end of flags as they are
+func (cp *ConfigurableParser) Normalize(args []string) []string {
+	s := cp.lenientScanner()
+	s.scan(args)
+	return s.args
+}
+
+// lenientScanner creates a scanner accepting unknown flags, for inspecting arguments
+func (cp *ConfigurableParser) lenientScanner() *scanner {
+	return &scanner{lookup: flagLookup{
+		long:  cp.lookupLong,
+		short: cp.lookupShort,
+	}, stopAfter: cp.config.StopAfter, dialect: cp.config.Dialect}
+}
+
+// lookupLong describes a long flag to the scanner, resolving unique prefixes of known flags
+func (cp *ConfigurableParser) lookupLong(flagName string) (*flagSpec, error) {
+	if _, exact := cp.config.KnownFlags[flagName]; !exact && cp.config.AllowAbbreviations {
+		resolved, err := matchLongFlag(flagName, cp.longFlagNames())
+		if err != nil {
+			return nil, err
 		}
+		flagName = resolved
 	}
 	
-	if isBool {
-		result.Flags[flagName] = true
-		*i++
+	if _, known := cp.config.KnownFlags[flagName]; !known {
+		return nil, nil
+	}
+	return cp.spec(flagName), nil
+}
+
+// lookupShort describes a short flag to the scanner
+func (cp *ConfigurableParser) lookupShort(flagName string) *flagSpec {
+	if _, known := cp.config.KnownFlags[flagName]; !known {
 		return nil
 	}
-	
-	// Non-boolean flag needs a value
-	if !hasValue {
-		*i++
-		if *i >= len(args) {
-			return fmt.Errorf("flag --%s requires a value", flagName)
+	return cp.spec(flagName)
+}
+
+// spec describes a known flag to the scanner; values are keyed by the long name when there is one
+func (cp *ConfigurableParser) spec(flagName string) *flagSpec {
+	flagInfo := cp.config.KnownFlags[flagName]
+	if flagInfo == nil {
+		if cp.config.BooleanFlags[flagName] {
+			return &flagSpec{key: flagName, kind: flagBool}
 		}
-		value = args[*i]
+		return &flagSpec{key: flagName, kind: flagValue}
 	}
 	
-	// Convert value based on type
-	convertedValue, err := cp.convertValue(value, flagInfo)
-	if err != nil {
-		return fmt.Errorf("invalid value for flag --%s: %w", flagName, err)
+	key := flagName
+	if flagInfo.Long != "" {
+		key = flagInfo.Long
 	}
 	
-	result.Flags[flagName] = convertedValue
-	*i++
-	return nil
+	switch {
+	case flagInfo.Type == "bool":
+		return &flagSpec{key: key, kind: flagBool}
+	case flagInfo.Optional:
+		return &flagSpec{key: key, kind: flagOptional, optional: flagInfo.OptionalValue}
+	}
+	return &flagSpec{key: key, kind: flagValue}
 }
 
-// parseConfiguredShortFlags handles short flags with configuration
-func (cp *ConfigurableParser) parseConfiguredShortFlags(args []string, i *int, result *ParseResult) error {
-	arg := args[*i]
-	flags := arg[1:]
-	
-	for j, r := range flags {
-		flagName := string(r)
-		
-		flagInfo, known := cp.config.KnownFlags[flagName]
-		if cp.config.StrictMode && !known {
-			return fmt.Errorf("unknown flag: -%s", flagName)
+// longFlagNames returns the long names of the known flags
+func (cp *ConfigurableParser) longFlagNames() []string {
+	var names []string
+	for name, info := range cp.config.KnownFlags {
+		if info != nil && name == info.Long {
+			names = append(names, name)
 		}
-		
-		// Determine if boolean - default to true for unknown single-char flags
-		isBool := true
-		if known {
-			isBool = cp.config.BooleanFlags[flagName]
-			if flagInfo != nil {
-				isBool = flagInfo.Type == "bool"
-			}
-		} else {
-			// For unknown flags, assume boolean unless it's the last flag and has a potential value
-			if j == len(flags)-1 && *i+1 < len(args) && !strings.HasPrefix(args[*i+1], "-") {
-				isBool = false
-			}
-		}
-		
-		if isBool {
-			result.Flags[flagName] = true
-			continue
-		}
-		
-		// Non-boolean flag must be last in bundle
-		if j < len(flags)-1 {
-			return fmt.Errorf("non-boolean flag -%s must be last in bundle", flagName)
-		}
-		
-		// Get value
-		*i++
-		if *i >= len(args) {
-			return fmt.Errorf("flag -%s requires a value", flagName)
-		}
-		
-		value := args[*i]
-		convertedValue, err := cp.convertValue(value, flagInfo)
-		if err != nil {
-			return fmt.Errorf("invalid value for flag -%s: %w", flagName, err)
-		}
-		
-		result.Flags[flagName] = convertedValue
 	}
-	
-	*i++
-	return nil
+	return names
 }
 
 // convertValue converts string values based on flag configuration
@@ -1,14 +1,21 @@
 package posix
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Parser implements POSIX-compliant argument parsing
-type Parser struct{}
+type Parser struct {
+	// AllowAbbreviations accepts unique prefixes of long flags, e.g. --verb for --verbose
+	AllowAbbreviations bool
+	// POSIXOrder stops parsing flags at the first positional argument, like POSIXLY_CORRECT
+	POSIXOrder bool
+}
 
 // NewParser creates a new POSIX parser
 func NewParser() *Parser {
@@ -28,8 +35,15 @@ func (p *Parser) Parse(args []string, target any) error {
 	// Build field map from struct tags
 	fieldMap, positionalFields := p.buildFieldMap(targetType)
 	
+	passthroughIndex := -1
+	for i, field := range positionalFields {
+		if hasTagOption(field.Tag.Get("posix"), "passthrough") {
+			passthroughIndex = i
+		}
+	}
+	
 	// Parse arguments
-	result, err := p.parseArgs(args, fieldMap)
+	result, err := p.parseArgs(args, fieldMap, StopAfter(p.POSIXOrder, passthroughIndex))
 	if err != nil {
 		return err
 	}
@@ -65,8 +79,7 @@ func (p *Parser) ValidateStruct(target any) error {
 			continue
 		}
 		
-		parts := strings.Split(tag, ",")
-		if len(parts) >= 4 && strings.Contains(parts[3], "required") {
+		if hasTagOption(tag, "required") {
 			if p.isZeroValue(field) {
 				return fmt.Errorf("required field %s is missing", fieldType.Name)
 			}
@@ -76,160 +89,64 @@ func (p *Parser) ValidateStruct(target any) error {
 	return nil
 }
 
-// parseArgs implements the POSIX argument parsing state machine
-func (p *Parser) parseArgs(args []string, fieldMap map[string]fieldInfo) (*parseResult, error) {
-	result := &parseResult{
-		Flags:      make(map[string]any),
-		Positional: []string{},
-		Remaining:  []string{},
-	}
-	
-	i := 0
-	endOfFlags := false
-	
-	for i < len(args) {
-		arg := args[i]
-		
-		// Handle -- (end of flags)
-		if arg == "--" {
-			endOfFlags = true
-			i++
-			continue
-		}
-		
-		// Handle positional arguments after --
-		if endOfFlags || !strings.HasPrefix(arg, "-") {
-			result.Positional = append(result.Positional, arg)
-			i++
-			continue
-		}
-		
-		// Handle single - (stdin/stdout indicator)
-		if arg == "-" {
-			result.Positional = append(result.Positional, arg)
-			i++
-			continue
-		}
-		
-		// Handle long flags --flag or --flag=value
-		if strings.HasPrefix(arg, "--") {
-			if err := p.parseLongFlag(args, &i, result, fieldMap); err != nil {
-				return nil, err
+// parseArgs scans the arguments, rejecting unknown flags, and converts flag values to the field types
+func (p *Parser) parseArgs(args []string, fieldMap map[string]fieldInfo, stopAfter int) (*parseResult, error) {
+	s := &scanner{lookup: flagLookup{
+		long: func(name string) (*flagSpec, error) {
+			if _, exists := fieldMap[name]; !exists && p.AllowAbbreviations {
+				names := make([]string, 0, len(fieldMap))
+				for long := range fieldMap {
+					names = append(names, long)
+				}
+				resolved, err := matchLongFlag(name, names)
+				if err != nil {
+					return nil, err
+				}
+				name = resolved
 			}
-			continue
-		}
-		
-		// Handle short flags -f or -abc (bundled)
-		if strings.HasPrefix(arg, "-") {
-			if err := p.parseShortFlag(args, &i, result, fieldMap); err != nil {
-				return nil, err
+			field, exists := fieldMap[name]
+			if !exists {
+				return nil, nil
 			}
-			continue
-		}
-		
-		i++
-	}
-	
-	return result, nil
-}
-
-// parseLongFlag handles --flag and --flag=value forms
-func (p *Parser) parseLongFlag(args []string, i *int, result *parseResult, fieldMap map[string]fieldInfo) error {
-	arg := args[*i]
-	flagName := arg[2:] // Remove --
-	
-	var value string
-	hasValue := false
-	
-	// Check for --flag=value form
-	if eqIndex := strings.Index(flagName, "="); eqIndex != -1 {
-		value = flagName[eqIndex+1:]
-		flagName = flagName[:eqIndex]
-		hasValue = true
-	}
-	
-	field, exists := fieldMap[flagName]
-	if !exists {
-		return fmt.Errorf("unknown flag: --%s", flagName)
-	}
-	
-	// Handle boolean flags
-	if field.Type.Kind() == reflect.Bool {
-		result.Flags[flagName] = true
-		*i++
-		return nil
-	}
-	
-	// Get value for non-boolean flags
-	if !hasValue {
-		*i++
-		if *i >= len(args) {
-			return fmt.Errorf("flag --%s requires a value", flagName)
-		}
-		value = args[*i]
-	}
+			return field.spec(), nil
+		},
+		short: func(name string) *flagSpec {
+			for _, field := range fieldMap {
+				if field.Short == name {
+					return field.spec()
+				}
+			}
+			return nil
+		},
+		strict: true,
+	}, stopAfter: stopAfter}
 	
-	parsedValue, err := p.parseValue(value, field.Type)
+	scanned, err := s.scan(args)
 	if err != nil {
-		return fmt.Errorf("invalid value for flag --%s: %w", flagName, err)
+		return nil, err
 	}
 	
-	result.Flags[flagName] = parsedValue
-	*i++
-	return nil
-}
-
-// parseShortFlag handles -f and -abc (bundled) forms
-func (p *Parser) parseShortFlag(args []string, i *int, result *parseResult, fieldMap map[string]fieldInfo) error {
-	arg := args[*i]
-	flags := arg[1:] // Remove -
+	result := &parseResult{
+		Flags:      make(map[string]any),
+		Positional: scanned.Positional,
+		Remaining:  scanned.Remaining,
+	}
 	
-	for j, r := range flags {
-		flagName := string(r)
-		
-		// Find field by short name
-		var field fieldInfo
-		var exists bool
-		for _, f := range fieldMap {
-			if f.Short == flagName {
-				field = f
-				exists = true
-				break
-			}
-		}
-		
-		if !exists {
-			return fmt.Errorf("unknown flag: -%s", flagName)
-		}
-		
-		// Handle boolean flags
-		if field.Type.Kind() == reflect.Bool {
-			result.Flags[field.Long] = true
+	for flagName, value := range scanned.Flags {
+		str, isString := value.(string)
+		if !isString {
+			result.Flags[flagName] = value
 			continue
 		}
 		
-		// Non-boolean flag must be the last in a bundle
-		if j < len(flags)-1 {
-			return fmt.Errorf("non-boolean flag -%s must be last in bundle", flagName)
-		}
-		
-		// Get value
-		*i++
-		if *i >= len(args) {
-			return fmt.Errorf("flag -%s requires a value", flagName)
-		}
-		
-		value := args[*i]
-		parsedValue, err := p.parseValue(value, field.Type)
+		parsedValue, err := p.parseValue(str, fieldMap[flagName].Type)
 		if err != nil {
-			return fmt.Errorf("invalid value for flag -%s: %w", flagName, err)
+			return nil, fmt.Errorf("invalid value for flag --%s: %w", flagName, err)
 		}
-		
-		result.Flags[field.Long] = parsedValue
+		result.Flags[flagName] = parsedValue
 	}
 	
-	*i++
-	return nil
+	return result, nil
 }
 
 // Helper types and functions
@@ -245,6 +162,21 @@ type fieldInfo struct {
 	Type     reflect.Type
 	Required bool
 	Desc     string
+	
+	// Optional flags may be given without a value, which then is OptionalValue
+	Optional      bool
+	OptionalValue string
+}
+
+// spec describes the field's flag to the scanner
+func (f fieldInfo) spec() *flagSpec {
+	switch {
+	case f.Type.Kind() == reflect.Bool:
+		return &flagSpec{key: f.Long, kind: flagBool}
+	case f.Optional:
+		return &flagSpec{key: f.Long, kind: flagOptional, optional: f.OptionalValue}
+	}
+	return &flagSpec{key: f.Long, kind: flagValue}
 }
 
 func (p *Parser) buildFieldMap(structType reflect.Type) (map[string]fieldInfo, []reflect.StructField) {
@@ -260,7 +192,7 @@ func (p *Parser) buildFieldMap(structType reflect.Type) (map[string]fieldInfo, [
 		}
 		
 		parts := strings.Split(tag, ",")
-		if len(parts) >= 4 && strings.Contains(parts[3], "positional") {
+		if hasTagOption(tag, "positional") || hasTagOption(tag, "passthrough") {
 			positionalFields = append(positionalFields, field)
 			continue
 		}
@@ -278,8 +210,14 @@ func (p *Parser) buildFieldMap(structType reflect.Type) (map[string]fieldInfo, [
 			info.Long = strings.ToLower(field.Name)
 		}
 		if len(parts) > 3 {
-			info.Required = strings.Contains(parts[3], "required")
+			info.Required = hasTagOption(tag, "required")
 			info.Desc = strings.TrimSpace(parts[2])
+			for _, flag := range strings.Split(parts[3], "|") {
+				if value, found := strings.CutPrefix(flag, "optional="); found {
+					info.Optional = true
+					info.OptionalValue = value
+				}
+			}
 		}
 		
 		fieldMap[info.Long] = info
@@ -288,7 +226,43 @@ func (p *Parser) buildFieldMap(structType reflect.Type) (map[string]fieldInfo, [
 	return fieldMap, positionalFields
 }
 
+// textUnmarshalerType is implemented by value types that parse themselves
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// hasTagOption reports whether option is one of the |-separated options of a posix
+// tag, which start at its fourth comma-separated part, matching whole options only
+func hasTagOption(tag, option string) bool {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 4 {
+		return false
+	}
+	for _, opt := range strings.Split(strings.Join(parts[3:], ","), "|") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isListType reports whether a positional field takes the remaining arguments. Value
+// types such as net.IP are slices too, but take a single argument.
+func isListType(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
 func (p *Parser) parseValue(value string, targetType reflect.Type) (any, error) {
+	// Value types such as net.IP parse themselves
+	if ptr := reflect.New(targetType); ptr.Type().Implements(textUnmarshalerType) {
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	
+	if targetType == reflect.TypeOf(time.Duration(0)) {
+		return time.ParseDuration(value)
+	}
+	
 	switch targetType.Kind() {
 	case reflect.String:
 		return value, nil
@@ -333,31 +307,46 @@ func (p *Parser) setFieldValues(targetStruct reflect.Value, targetType reflect.T
 }
 
 func (p *Parser) setPositionalValues(targetStruct reflect.Value, targetType reflect.Type, positional []string, positionalFields []reflect.StructField) error {
+	variadic := len(positionalFields) > 0 && isListType(positionalFields[len(positionalFields)-1].Type)
+	if !variadic && len(positional) > len(positionalFields) {
+		return fmt.Errorf("unexpected argument %q", positional[len(positionalFields)])
+	}
+	
 	for i, field := range positionalFields {
-		if i >= len(positional) {
-			break
-		}
-		
 		structField := targetStruct.FieldByName(field.Name)
 		if !structField.IsValid() || !structField.CanSet() {
 			continue
 		}
+		name := strings.ToLower(field.Name)
 		
 		// Handle slice types for remaining positional args
-		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String {
-			remaining := positional[i:]
+		if isListType(field.Type) {
+			remaining := positional[min(i, len(positional)):]
 			slice := reflect.MakeSlice(field.Type, len(remaining), len(remaining))
 			for j, arg := range remaining {
-				slice.Index(j).SetString(arg)
+				value, err := p.parseValue(arg, field.Type.Elem())
+				if err != nil {
+					return fmt.Errorf("invalid value %q for argument %s: %w", arg, name, err)
+				}
+				slice.Index(j).Set(reflect.ValueOf(value).Convert(field.Type.Elem()))
 			}
 			structField.Set(slice)
 			break
 		}
 		
+		if i >= len(positional) {
+			if hasTagOption(field.Tag.Get("posix"), "required") {
+				return fmt.Errorf("missing argument <%s>", name)
+			}
+			continue
+		}
+		
 		// Handle single values
-		if field.Type.Kind() == reflect.String {
-			structField.SetString(positional[i])
+		value, err := p.parseValue(positional[i], field.Type)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for argument %s: %w", positional[i], name, err)
 		}
+		structField.Set(reflect.ValueOf(value).Convert(field.Type))
 	}
 	
 	return nil
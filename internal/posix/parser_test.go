@@ -0,0 +1,86 @@
+package posix
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pingConfig struct {
+	Verbose bool            `posix:"v,verbose,Verbose"`
+	Host    net.IP          `posix:",host,Host,positional|required"`
+	Count   int             `posix:",count,Count,positional"`
+	Waits   []time.Duration `posix:",waits,Waits,positional"`
+}
+
+func TestParserPositionals(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    pingConfig
+		wantErr string
+	}{
+		{name: "required only", args: []string{"10.0.0.1"}, want: pingConfig{Host: net.ParseIP("10.0.0.1"), Waits: []time.Duration{}}},
+		{name: "typed values", args: []string{"-v", "10.0.0.1", "3", "1s", "2m"}, want: pingConfig{Verbose: true, Host: net.ParseIP("10.0.0.1"), Count: 3, Waits: []time.Duration{time.Second, 2 * time.Minute}}},
+		{name: "missing required", args: []string{"-v"}, wantErr: "missing argument <host>"},
+		{name: "invalid text value", args: []string{"nowhere"}, wantErr: `invalid value "nowhere" for argument host`},
+		{name: "invalid int", args: []string{"10.0.0.1", "three"}, wantErr: `invalid value "three" for argument count`},
+		{name: "invalid slice item", args: []string{"10.0.0.1", "3", "soon"}, wantErr: `invalid value "soon" for argument waits`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got pingConfig
+			err := NewParser().Parse(tt.args, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParserTagOptions(t *testing.T) {
+	// Option names inside descriptions or other options are not options
+	type wrapConfig struct {
+		Mode  string   `posix:"m,mode,Mode,default=required"`
+		Tool  string   `posix:",tool,Tool taking passthrough args,positional"`
+		Extra []string `posix:",extra,Extra,passthrough"`
+	}
+	
+	var got wrapConfig
+	if err := NewParser().Parse([]string{"--mode", "x", "git", "-v", "--dry-run"}, &got); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := wrapConfig{Mode: "x", Tool: "git", Extra: []string{"-v", "--dry-run"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+	
+	if err := NewParser().ValidateStruct(&wrapConfig{}); err != nil {
+		t.Errorf("ValidateStruct() error = %v, want nil", err)
+	}
+}
+
+func TestParserExtraPositionals(t *testing.T) {
+	type moveConfig struct {
+		Src string `posix:",src,Source,positional"`
+		Dst string `posix:",dst,Destination,positional"`
+	}
+	
+	var got moveConfig
+	err := NewParser().Parse([]string{"a", "b", "c"}, &got)
+	if err == nil || err.Error() != `unexpected argument "c"` {
+		t.Errorf("Parse() error = %v, want unexpected argument", err)
+	}
+}
@@ -0,0 +1,268 @@
+package posix
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// flagKind is how a flag takes its value
+type flagKind int
+
+const (
+	flagValue    flagKind = iota // Requires a value, attached or in the next argument
+	flagBool                     // Takes no value, or an attached =true/=false
+	flagOptional                 // Takes an attached value, or its optional value
+)
+
+// flagSpec describes a flag to the scanner
+type flagSpec struct {
+	key      string // Key of the flag in ParseResult.Flags
+	kind     flagKind
+	optional string // Value of an optional-value flag given without one
+}
+
+// UnknownFlagError reports a flag that is not defined in strict mode
+type UnknownFlagError struct {
+	Flag string // The flag as given, e.g. --replics or -x
+}
+
+// Error names the unknown flag
+func (e *UnknownFlagError) Error() string {
+	return "unknown flag: " + e.Flag
+}
+
+// flagLookup resolves flag names for the scanner. Lookups return a nil spec for
+// unknown flags, which are errors in strict mode and guessed otherwise.
+type flagLookup struct {
+	long   func(name string) (*flagSpec, error)
+	short  func(name string) *flagSpec
+	strict bool
+}
+
+// scanner implements the POSIX/GNU argument syntax shared by all parsers:
+//
+//	--name=value, --name value    long flag with a value
+//	--name, --name=false          boolean long flag
+//	--name, --name=value          long flag with an optional value
+//	-abc                          bundled boolean short flags
+//	-n5, -n=5, -n 5, -vn5         short flag with an attached or separate value
+//	--                            end of flags
+//	-, -5, -1.5                   positional arguments
+//
+// Flag values are returned as strings, booleans as bool. Arguments after -- or after
+// stopAfter positional arguments are positional, and also returned as Remaining.
+type scanner struct {
+	lookup    flagLookup
+	stopAfter int     // Positional arguments after which flags are no longer parsed, 0 for none
+	dialect   Dialect // Syntax of flags, POSIX when nil
+	
+	// Set by scan: the arguments with flags in POSIX form, and the index of the first
+	// argument not parsed for flags
+	args []string
+	end  int
+}
+
+// scan parses args into flags and positional arguments
+func (s *scanner) scan(args []string) (*ParseResult, error) {
+	result := &ParseResult{
+		Flags:      make(map[string]any),
+		Positional: make([]string, 0),
+		Remaining:  make([]string, 0),
+	}
+	
+	endOfFlags := false
+	args = slices.Clone(args)
+	s.args = args
+	s.end = len(args)
+	
+	for i := 0; i < len(args); i++ {
+		if s.dialect != nil && !endOfFlags {
+			args[i] = s.dialect.Normalize(args[i], s.isFlag)
+		}
+		arg := args[i]
+		
+		var err error
+		switch {
+		case endOfFlags:
+			result.Positional = append(result.Positional, arg)
+			result.Remaining = append(result.Remaining, arg)
+			continue
+		case arg == "--":
+			endOfFlags = true
+			s.end = i
+			continue
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			// A lone - conventionally means stdin or stdout
+			result.Positional = append(result.Positional, arg)
+		case strings.HasPrefix(arg, "--"):
+			i, err = s.scanLong(args, i, result)
+		case isNegativeNumber(arg) && s.lookup.short(arg[1:2]) == nil:
+			result.Positional = append(result.Positional, arg)
+		default:
+			i, err = s.scanShort(args, i, result)
+		}
+		if err != nil {
+			return nil, err
+		}
+		
+		// Wrapped commands get the rest of the arguments untouched
+		if s.stopAfter > 0 && len(result.Positional) >= s.stopAfter {
+			endOfFlags = true
+			s.end = i + 1
+		}
+	}
+	
+	return result, nil
+}
+
+// isFlag reports whether name is a defined long or short flag. A long name that fails
+// to resolve, such as an ambiguous abbreviation, counts as a flag so its error is reported.
+func (s *scanner) isFlag(name string) bool {
+	if len([]rune(name)) == 1 && s.lookup.short(name) != nil {
+		return true
+	}
+	spec, err := s.lookup.long(name)
+	return spec != nil || err != nil
+}
+
+// scanLong handles a long flag at args[i] and returns the index of the last argument used
+func (s *scanner) scanLong(args []string, i int, result *ParseResult) (int, error) {
+	name, value, hasValue := strings.Cut(args[i][2:], "=")
+	
+	spec, err := s.lookup.long(name)
+	if err != nil {
+		return i, err
+	}
+	if spec == nil {
+		if s.lookup.strict {
+			return i, &UnknownFlagError{Flag: "--" + name}
+		}
+		spec = s.guess(name, args, i, hasValue)
+	}
+	
+	switch spec.kind {
+	case flagBool:
+		if !hasValue {
+			result.Flags[spec.key] = true
+			return i, nil
+		}
+		b, err := parseBool(value)
+		if err != nil {
+			return i, fmt.Errorf("invalid value for flag --%s: %w", name, err)
+		}
+		result.Flags[spec.key] = b
+		return i, nil
+	case flagOptional:
+		if !hasValue {
+			value = spec.optional
+		}
+	case flagValue:
+		if !hasValue {
+			if i+1 >= len(args) {
+				return i, fmt.Errorf("flag --%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+	}
+	
+	result.Flags[spec.key] = value
+	return i, nil
+}
+
+// scanShort handles bundled short flags at args[i] and returns the index of the last argument used.
+// The first flag taking a value consumes the rest of the bundle, or the next argument.
+func (s *scanner) scanShort(args []string, i int, result *ParseResult) (int, error) {
+	flags := []rune(args[i][1:])
+	
+	for j, r := range flags {
+		name := string(r)
+		rest := strings.TrimPrefix(string(flags[j+1:]), "=")
+		attached := j+1 < len(flags)
+		
+		spec := s.lookup.short(name)
+		if spec == nil {
+			if s.lookup.strict {
+				return i, &UnknownFlagError{Flag: "-" + name}
+			}
+			spec = s.guessShort(name, args, i, rest, attached)
+		}
+		
+		switch spec.kind {
+		case flagBool:
+			result.Flags[spec.key] = true
+			continue
+		case flagOptional:
+			if !attached {
+				rest = spec.optional
+			}
+		case flagValue:
+			if !attached {
+				if i+1 >= len(args) {
+					return i, fmt.Errorf("flag -%s requires a value", name)
+				}
+				i++
+				rest = args[i]
+			}
+		}
+		
+		result.Flags[spec.key] = rest
+		return i, nil
+	}
+	
+	return i, nil
+}
+
+// guess infers how an unknown long flag takes a value: from an attached value or
+// an argument after it that looks like a value
+func (s *scanner) guess(name string, args []string, i int, hasValue bool) *flagSpec {
+	if hasValue || (i+1 < len(args) && looksLikeValue(args[i+1])) {
+		return &flagSpec{key: name, kind: flagValue}
+	}
+	return &flagSpec{key: name, kind: flagBool}
+}
+
+// guessShort infers how an unknown short flag takes a value: digits attached to it,
+// or an argument after the last flag of a bundle that looks like a value
+func (s *scanner) guessShort(name string, args []string, i int, rest string, attached bool) *flagSpec {
+	if attached {
+		if _, err := strconv.ParseFloat(rest, 64); err == nil {
+			return &flagSpec{key: name, kind: flagValue}
+		}
+		return &flagSpec{key: name, kind: flagBool}
+	}
+	if i+1 < len(args) && looksLikeValue(args[i+1]) {
+		return &flagSpec{key: name, kind: flagValue}
+	}
+	return &flagSpec{key: name, kind: flagBool}
+}
+
+// StopAfter returns the number of positional arguments after which flags are no longer
+// parsed, or 0 to parse flags everywhere. POSIX ordering stops at the first positional;
+// a passthrough field stops at its position, so it captures the rest, flags included.
+func StopAfter(posixOrder bool, passthroughIndex int) int {
+	stop := 0
+	if passthroughIndex >= 0 {
+		stop = max(passthroughIndex, 1)
+	}
+	if posixOrder {
+		stop = 1
+	}
+	return stop
+}
+
+// looksLikeValue reports whether an argument is a value rather than a flag
+func looksLikeValue(arg string) bool {
+	return !strings.HasPrefix(arg, "-") || arg == "-" || isNegativeNumber(arg)
+}
+
+// isNegativeNumber reports whether arg is a negative number such as -5 or -1.5
+func isNegativeNumber(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err == nil
+}
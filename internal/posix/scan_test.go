@@ -0,0 +1,170 @@
+package posix
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// newScanTestParser creates a parser knowing --name/-n, --replicas/-r, --verbose/-v,
+// --all/-a and --color with an optional value
+func newScanTestParser(strict bool) *ConfigurableParser {
+	parser := NewConfigurableParser(nil)
+	parser.AddFlag(&FlagInfo{Long: "name", Short: "n", Type: "string"})
+	parser.AddFlag(&FlagInfo{Long: "replicas", Short: "r", Type: "int"})
+	parser.AddFlag(&FlagInfo{Long: "verbose", Short: "v", Type: "bool"})
+	parser.AddFlag(&FlagInfo{Long: "all", Short: "a", Type: "bool"})
+	parser.AddFlag(&FlagInfo{Long: "color", Type: "string", Optional: true, OptionalValue: "always"})
+	parser.SetStrictMode(strict)
+	return parser
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		strict         bool
+		stopAfter      int
+		wantFlags      map[string]any
+		wantPositional []string
+		wantRemaining  []string
+		wantErr        string
+		wantUnknown    string
+	}{
+		{name: "long with equals", args: []string{"--name=web"}, wantFlags: map[string]any{"name": "web"}},
+		{name: "long with separate value", args: []string{"--name", "web"}, wantFlags: map[string]any{"name": "web"}},
+		{name: "long value starting with dash", args: []string{"--name", "-x"}, wantFlags: map[string]any{"name": "-x"}},
+		{name: "empty attached value", args: []string{"--name="}, wantFlags: map[string]any{"name": ""}},
+		{name: "bool", args: []string{"--verbose"}, wantFlags: map[string]any{"verbose": true}},
+		{name: "bool set false", args: []string{"--verbose=false"}, wantFlags: map[string]any{"verbose": false}},
+		{name: "bool does not take next argument", args: []string{"--verbose", "false"}, wantFlags: map[string]any{"verbose": true}, wantPositional: []string{"false"}},
+		{name: "invalid bool", args: []string{"--verbose=maybe"}, wantErr: "invalid value for flag --verbose: invalid boolean value: maybe"},
+		{name: "int zero", args: []string{"--replicas=0"}, wantFlags: map[string]any{"replicas": 0}},
+		{name: "missing value", args: []string{"--name"}, wantErr: "flag --name requires a value"},
+		{name: "missing short value", args: []string{"-n"}, wantErr: "flag -n requires a value"},
+		{name: "short separate value", args: []string{"-n", "web"}, wantFlags: map[string]any{"name": "web"}},
+		{name: "short attached value", args: []string{"-nweb"}, wantFlags: map[string]any{"name": "web"}},
+		{name: "short attached with equals", args: []string{"-n=web"}, wantFlags: map[string]any{"name": "web"}},
+		{name: "short attached number", args: []string{"-r5"}, wantFlags: map[string]any{"replicas": 5}},
+		{name: "bundled bools", args: []string{"-va"}, wantFlags: map[string]any{"verbose": true, "all": true}},
+		{name: "bundle ending in value", args: []string{"-vn5"}, wantFlags: map[string]any{"verbose": true, "name": "5"}},
+		{name: "bundle ending in separate value", args: []string{"-van", "web"}, wantFlags: map[string]any{"verbose": true, "all": true, "name": "web"}},
+		{name: "optional without value", args: []string{"--color"}, wantFlags: map[string]any{"color": "always"}},
+		{name: "optional with value", args: []string{"--color=never"}, wantFlags: map[string]any{"color": "never"}},
+		{name: "optional does not take next argument", args: []string{"--color", "never"}, wantFlags: map[string]any{"color": "always"}, wantPositional: []string{"never"}},
+		{name: "negative number value", args: []string{"--replicas", "-3"}, wantFlags: map[string]any{"replicas": -3}},
+		{name: "negative number positional", args: []string{"-5", "-1.5"}, wantFlags: map[string]any{}, wantPositional: []string{"-5", "-1.5"}},
+		{name: "lone dash", args: []string{"-"}, wantFlags: map[string]any{}, wantPositional: []string{"-"}},
+		{name: "end of flags", args: []string{"--verbose", "--", "--name", "x"}, wantFlags: map[string]any{"verbose": true}, wantPositional: []string{"--name", "x"}, wantRemaining: []string{"--name", "x"}},
+		{name: "flags after positionals", args: []string{"file", "--verbose"}, wantFlags: map[string]any{"verbose": true}, wantPositional: []string{"file"}},
+		{name: "stop after positional", args: []string{"-v", "cmd", "--name", "x"}, stopAfter: 1, wantFlags: map[string]any{"verbose": true}, wantPositional: []string{"cmd", "--name", "x"}, wantRemaining: []string{"--name", "x"}},
+		{name: "unknown long guessed as value", args: []string{"--zone", "eu"}, wantFlags: map[string]any{"zone": "eu"}},
+		{name: "unknown long guessed as bool", args: []string{"--force", "--verbose"}, wantFlags: map[string]any{"force": true, "verbose": true}},
+		{name: "unknown short with digits", args: []string{"-x5"}, wantFlags: map[string]any{"x": "5"}},
+		{name: "unknown long in strict mode", args: []string{"--replics=2"}, strict: true, wantErr: "unknown flag: --replics", wantUnknown: "--replics"},
+		{name: "unknown short in strict mode", args: []string{"-vx"}, strict: true, wantErr: "unknown flag: -x", wantUnknown: "-x"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newScanTestParser(tt.strict)
+			parser.SetStopAfter(tt.stopAfter)
+			
+			result, err := parser.Parse(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				var unknown *UnknownFlagError
+				if tt.wantUnknown != "" && (!errors.As(err, &unknown) || unknown.Flag != tt.wantUnknown) {
+					t.Errorf("Parse(%q) error = %#v, want UnknownFlagError for %s", tt.args, err, tt.wantUnknown)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.args, err)
+			}
+			
+			if !reflect.DeepEqual(result.Flags, tt.wantFlags) {
+				t.Errorf("Parse(%q) flags = %v, want %v", tt.args, result.Flags, tt.wantFlags)
+			}
+			if tt.wantPositional == nil {
+				tt.wantPositional = []string{}
+			}
+			if !reflect.DeepEqual(result.Positional, tt.wantPositional) {
+				t.Errorf("Parse(%q) positional = %q, want %q", tt.args, result.Positional, tt.wantPositional)
+			}
+			if tt.wantRemaining == nil {
+				tt.wantRemaining = []string{}
+			}
+			if !reflect.DeepEqual(result.Remaining, tt.wantRemaining) {
+				t.Errorf("Parse(%q) remaining = %q, want %q", tt.args, result.Remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestFlagsEnd(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		stopAfter int
+		want      int
+	}{
+		{name: "no separator", args: []string{"--verbose", "file"}, want: 2},
+		{name: "separator", args: []string{"--verbose", "--", "file"}, want: 1},
+		{name: "stop after positional", args: []string{"-n", "web", "cmd", "--all"}, stopAfter: 1, want: 3},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := newScanTestParser(false)
+			parser.SetStopAfter(tt.stopAfter)
+			if got := parser.FlagsEnd(tt.args); got != tt.want {
+				t.Errorf("FlagsEnd(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopAfter(t *testing.T) {
+	tests := []struct {
+		name             string
+		posixOrder       bool
+		passthroughIndex int
+		want             int
+	}{
+		{name: "flags anywhere", passthroughIndex: -1, want: 0},
+		{name: "posix order", posixOrder: true, passthroughIndex: -1, want: 1},
+		{name: "passthrough first", passthroughIndex: 0, want: 1},
+		{name: "passthrough after positionals", passthroughIndex: 2, want: 2},
+		{name: "posix order with passthrough", posixOrder: true, passthroughIndex: 2, want: 1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StopAfter(tt.posixOrder, tt.passthroughIndex); got != tt.want {
+				t.Errorf("StopAfter(%v, %d) = %d, want %d", tt.posixOrder, tt.passthroughIndex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNegativeNumber(t *testing.T) {
+	tests := map[string]bool{
+		"-5":   true,
+		"-1.5": true,
+		"-0":   true,
+		"-":    false,
+		"-v":   false,
+		"-5x":  false,
+		"5":    false,
+		"--5":  false,
+	}
+	
+	for arg, want := range tests {
+		if got := isNegativeNumber(arg); got != want {
+			t.Errorf("isNegativeNumber(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
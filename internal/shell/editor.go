@@ -0,0 +1,334 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxHistory is the number of history lines kept and loaded from the history file
+const maxHistory = 1000
+
+// Editor reads lines from a terminal with editing, history and tab completion.
+// When the input is not a terminal it reads plain lines.
+type Editor struct {
+	in       *os.File
+	out      io.Writer
+	reader   *bufio.Reader
+	terminal bool
+	
+	history     []string
+	historyFile string
+	
+	// Complete returns the candidates for the word ending at the end of line
+	Complete func(line string) []string
+}
+
+// NewEditor creates an editor reading from in and echoing to out
+func NewEditor(in *os.File, out io.Writer) *Editor {
+	info, err := in.Stat()
+	return &Editor{
+		in:       in,
+		out:      out,
+		reader:   bufio.NewReader(in),
+		terminal: err == nil && info.Mode()&os.ModeCharDevice != 0,
+	}
+}
+
+// IsTerminal reports whether the editor reads from a terminal
+func (e *Editor) IsTerminal() bool {
+	return e.terminal
+}
+
+// LoadHistory reads the history file and appends the lines read later to it. A
+// missing file is not an error.
+func (e *Editor) LoadHistory(path string) error {
+	e.historyFile = path
+	
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+	return nil
+}
+
+// AddHistory adds a line to the history, skipping repeats of the previous line
+func (e *Editor) AddHistory(line string) error {
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return nil
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+	
+	if e.historyFile == "" {
+		return nil
+	}
+	file, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer file.Close()
+	
+	_, err = fmt.Fprintln(file, line)
+	return err
+}
+
+// ReadLine shows the prompt and returns the line entered, without the newline. It
+// returns io.EOF for Ctrl-D on an empty line or the end of the input.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.terminal {
+		return e.readPlain()
+	}
+	
+	restore, err := makeRaw(e.in)
+	if err != nil {
+		fmt.Fprint(e.out, prompt)
+		return e.readPlain()
+	}
+	defer restore()
+	
+	return e.readEdited(prompt)
+}
+
+// readPlain reads a line without editing
+func (e *Editor) readPlain() (string, error) {
+	line, err := e.reader.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// lineState is the line being edited
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+// readEdited reads a line in raw mode, handling editing keys
+func (e *Editor) readEdited(prompt string) (string, error) {
+	s := &lineState{prompt: prompt}
+	historyPos := len(e.history)
+	saved := ""
+	e.refresh(s)
+	
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\n")
+			return string(s.buf), nil
+		case 3: // Ctrl-C discards the line
+			fmt.Fprint(e.out, "^C\n")
+			s.buf, s.pos = nil, 0
+		case 4: // Ctrl-D ends the input on an empty line, else deletes
+			if len(s.buf) == 0 {
+				fmt.Fprint(e.out, "\n")
+				return "", io.EOF
+			}
+			s.delete()
+		case '\t':
+			e.complete(s)
+		case 127, 8: // Backspace
+			if s.pos > 0 {
+				s.pos--
+				s.delete()
+			}
+		case 1: // Ctrl-A
+			s.pos = 0
+		case 5: // Ctrl-E
+			s.pos = len(s.buf)
+		case 2: // Ctrl-B
+			s.move(-1)
+		case 6: // Ctrl-F
+			s.move(1)
+		case 11: // Ctrl-K deletes to the end of the line
+			s.buf = s.buf[:s.pos]
+		case 21: // Ctrl-U deletes to the start of the line
+			s.buf = s.buf[s.pos:]
+			s.pos = 0
+		case 23: // Ctrl-W deletes the previous word
+			start := s.wordStart()
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case 12: // Ctrl-L clears the screen
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case 16, 14: // Ctrl-P and Ctrl-N move through the history
+			historyPos, saved = e.browse(s, historyPos, saved, r == 16)
+		case 27:
+			switch e.readEscape() {
+			case 'A':
+				historyPos, saved = e.browse(s, historyPos, saved, true)
+			case 'B':
+				historyPos, saved = e.browse(s, historyPos, saved, false)
+			case 'C':
+				s.move(1)
+			case 'D':
+				s.move(-1)
+			case 'H':
+				s.pos = 0
+			case 'F':
+				s.pos = len(s.buf)
+			case '~':
+				s.delete()
+			}
+		default:
+			if unicode.IsPrint(r) {
+				s.insert([]rune{r})
+			}
+		}
+		
+		e.refresh(s)
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns its final byte, or 0.
+// The delete key, ESC [ 3 ~, is returned as ~.
+func (e *Editor) readEscape() byte {
+	b, err := e.reader.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return 0
+	}
+	for {
+		b, err = e.reader.ReadByte()
+		if err != nil {
+			return 0
+		}
+		if (b < '0' || b > '9') && b != ';' {
+			return b
+		}
+	}
+}
+
+// browse replaces the line with the previous or next history entry, keeping the
+// line being typed to return to
+func (e *Editor) browse(s *lineState, historyPos int, saved string, back bool) (int, string) {
+	if historyPos == len(e.history) {
+		saved = string(s.buf)
+	}
+	
+	switch {
+	case back && historyPos > 0:
+		historyPos--
+	case !back && historyPos < len(e.history):
+		historyPos++
+	default:
+		return historyPos, saved
+	}
+	
+	line := saved
+	if historyPos < len(e.history) {
+		line = e.history[historyPos]
+	}
+	s.buf = []rune(line)
+	s.pos = len(s.buf)
+	return historyPos, saved
+}
+
+// complete completes the word before the cursor. A single candidate replaces the
+// word, several are extended to their common prefix or else listed.
+func (e *Editor) complete(s *lineState) {
+	if e.Complete == nil {
+		return
+	}
+	
+	start := s.pos
+	for start > 0 && s.buf[start-1] != ' ' {
+		start--
+	}
+	word := string(s.buf[start:s.pos])
+	candidates := e.Complete(string(s.buf[:s.pos]))
+	if len(candidates) == 0 {
+		return
+	}
+	
+	replacement := commonPrefix(candidates)
+	if len(candidates) == 1 && !strings.HasSuffix(replacement, "/") && !strings.HasSuffix(replacement, "=") {
+		replacement += " "
+	}
+	if len(candidates) > 1 && replacement == word {
+		fmt.Fprint(e.out, "\n"+strings.Join(candidates, "  ")+"\n")
+		return
+	}
+	
+	rest := append([]rune{}, s.buf[s.pos:]...)
+	s.buf = append(s.buf[:start], []rune(replacement)...)
+	s.pos = len(s.buf)
+	s.buf = append(s.buf, rest...)
+}
+
+// refresh redraws the prompt and the line with the cursor in place
+func (e *Editor) refresh(s *lineState) {
+	var b strings.Builder
+	b.WriteString("\r" + s.prompt + string(s.buf) + "\x1b[K")
+	if back := len(s.buf) - s.pos; back > 0 {
+		fmt.Fprintf(&b, "\x1b[%dD", back)
+	}
+	fmt.Fprint(e.out, b.String())
+}
+
+// insert inserts runes at the cursor
+func (s *lineState) insert(runes []rune) {
+	s.buf = append(s.buf[:s.pos], append(runes, s.buf[s.pos:]...)...)
+	s.pos += len(runes)
+}
+
+// delete deletes the rune under the cursor
+func (s *lineState) delete() {
+	if s.pos < len(s.buf) {
+		s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+	}
+}
+
+// move moves the cursor within the line
+func (s *lineState) move(delta int) {
+	s.pos = max(0, min(len(s.buf), s.pos+delta))
+}
+
+// wordStart returns the start of the word before the cursor
+func (s *lineState) wordStart() int {
+	start := s.pos
+	for start > 0 && s.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && s.buf[start-1] != ' ' {
+		start--
+	}
+	return start
+}
+
+// commonPrefix returns the longest prefix shared by all candidates
+func commonPrefix(candidates []string) string {
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,214 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadEdited(t *testing.T) {
+	complete := func(line string) []string {
+		words := strings.Fields(line)
+		word := ""
+		if len(words) > 0 && !strings.HasSuffix(line, " ") {
+			word = words[len(words)-1]
+		}
+		var candidates []string
+		for _, name := range []string{"deploy", "delete", "status", "config="} {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name)
+			}
+		}
+		return candidates
+	}
+	
+	tests := []struct {
+		name    string
+		keys    string
+		history []string
+		want    string
+		wantErr error
+	}{
+		{name: "typing", keys: "deploy web\r", want: "deploy web"},
+		{name: "newline ends the line", keys: "status\n", want: "status"},
+		{name: "backspace", keys: "deployy\x7f\r", want: "deploy"},
+		{name: "backspace at the start", keys: "\x7fab\r", want: "ab"},
+		{name: "insert at the start", keys: "ploy\x01de\r", want: "deploy"},
+		{name: "end of line", keys: "ab\x01\x05c\r", want: "abc"},
+		{name: "cursor keys", keys: "dploy\x1b[D\x1b[D\x1b[D\x1b[De\r", want: "deploy"},
+		{name: "ctrl-b and ctrl-f", keys: "ac\x02b\x06d\r", want: "abcd"},
+		{name: "home and end keys", keys: "b\x1b[Ha\x1b[Fc\r", want: "abc"},
+		{name: "delete key", keys: "abc\x1b[D\x1b[D\x1b[3~\r", want: "ac"},
+		{name: "kill to end", keys: "deploy web\x01\x06\x06\x06\x06\x06\x06\x0b\r", want: "deploy"},
+		{name: "kill to start", keys: "deploy web\x1b[D\x1b[D\x1b[D\x15\r", want: "web"},
+		{name: "delete word", keys: "deploy web  \x17\r", want: "deploy "},
+		{name: "ctrl-c discards the line", keys: "deploy\x03status\r", want: "status"},
+		{name: "ctrl-d on an empty line", keys: "\x04", wantErr: io.EOF},
+		{name: "ctrl-d deletes", keys: "ab\x01\x04\r", want: "b"},
+		{name: "end of input", keys: "dep", wantErr: io.EOF},
+		{name: "non printable keys are ignored", keys: "a\x00\x1bxb\r", want: "ab"},
+		{name: "unicode", keys: "héllo\x7f\x7fp\r", want: "hélp"},
+		{name: "previous history", keys: "\x1b[A\x1b[A\r", history: []string{"one", "two"}, want: "one"},
+		{name: "history stops at the oldest", keys: "\x10\x10\x10\r", history: []string{"one", "two"}, want: "one"},
+		{name: "back to the typed line", keys: "new\x1b[A\x1b[B\r", history: []string{"one"}, want: "new"},
+		{name: "next history", keys: "\x10\x10\x0e\r", history: []string{"one", "two"}, want: "two"},
+		{name: "complete a single candidate", keys: "st\t\r", want: "status "},
+		{name: "complete the common prefix", keys: "d\t\r", want: "de"},
+		{name: "list ambiguous candidates", keys: "de\tp\t\r", want: "deploy "},
+		{name: "complete before the cursor", keys: "st web\x01\x06\x06\t\r", want: "status  web"},
+		{name: "no space after =", keys: "co\t\r", want: "config="},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			editor := &Editor{
+				out:      &out,
+				reader:   bufio.NewReader(strings.NewReader(tt.keys)),
+				history:  tt.history,
+				Complete: complete,
+			}
+			
+			got, err := editor.readEdited("> ")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("readEdited(%q) error = %v, want %v", tt.keys, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readEdited(%q) error = %v", tt.keys, err)
+			}
+			if got != tt.want {
+				t.Errorf("readEdited(%q) = %q, want %q", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadEditedListsCandidates(t *testing.T) {
+	var out strings.Builder
+	editor := &Editor{
+		out:      &out,
+		reader:   bufio.NewReader(strings.NewReader("de\t\r")),
+		Complete: func(line string) []string { return []string{"delete", "deploy"} },
+	}
+	
+	if _, err := editor.readEdited("> "); err != nil {
+		t.Fatalf("readEdited() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "\ndelete  deploy\n") {
+		t.Errorf("output %q does not list the candidates", out.String())
+	}
+}
+
+func TestReadLinePlain(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	defer reader.Close()
+	
+	go func() {
+		fmt.Fprint(writer, "deploy web\r\n\nstatus")
+		writer.Close()
+	}()
+	
+	editor := NewEditor(reader, io.Discard)
+	if editor.IsTerminal() {
+		t.Fatal("IsTerminal() = true for a pipe")
+	}
+	
+	var lines []string
+	for {
+		line, err := editor.ReadLine("> ")
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadLine() error = %v", err)
+		}
+		lines = append(lines, line)
+	}
+	if want := []string{"deploy web", "", "status"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("ReadLine() lines = %q, want %q", lines, want)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	
+	editor := &Editor{}
+	if err := editor.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory() of a missing file error = %v", err)
+	}
+	for _, line := range []string{"deploy", "deploy", "", "status", "deploy"} {
+		if err := editor.AddHistory(line); err != nil {
+			t.Fatalf("AddHistory(%q) error = %v", line, err)
+		}
+	}
+	
+	want := []string{"deploy", "status", "deploy"}
+	if !reflect.DeepEqual(editor.history, want) {
+		t.Errorf("history = %q, want %q", editor.history, want)
+	}
+	
+	// A new editor continues with the persisted history
+	loaded := &Editor{}
+	if err := loaded.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.history, want) {
+		t.Errorf("loaded history = %q, want %q", loaded.history, want)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	var lines strings.Builder
+	for i := range maxHistory + 10 {
+		fmt.Fprintf(&lines, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(lines.String()), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	
+	editor := &Editor{}
+	if err := editor.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if len(editor.history) != maxHistory || editor.history[0] != "line 10" {
+		t.Fatalf("loaded %d lines starting with %q, want %d starting with line 10", len(editor.history), editor.history[0], maxHistory)
+	}
+	
+	if err := editor.AddHistory("latest"); err != nil {
+		t.Fatalf("AddHistory() error = %v", err)
+	}
+	if len(editor.history) != maxHistory || editor.history[0] != "line 11" || editor.history[maxHistory-1] != "latest" {
+		t.Errorf("history has %d lines from %q to %q after adding", len(editor.history), editor.history[0], editor.history[len(editor.history)-1])
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		candidates []string
+		want       string
+	}{
+		{candidates: []string{"deploy"}, want: "deploy"},
+		{candidates: []string{"deploy", "delete"}, want: "de"},
+		{candidates: []string{"status", "deploy"}, want: ""},
+		{candidates: []string{"héllo", "hélp"}, want: "hél"},
+	}
+	
+	for _, tt := range tests {
+		if got := commonPrefix(tt.candidates); got != tt.want {
+			t.Errorf("commonPrefix(%q) = %q, want %q", tt.candidates, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package shell
+
+import (
+	"fmt"
+	"os"
+)
+
+// makeRaw is not supported on this platform, so lines are read without editing
+func makeRaw(terminal *os.File) (func(), error) {
+	return nil, fmt.Errorf("line editing is not supported on this platform")
+}
@@ -0,0 +1,44 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package shell
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw puts the terminal in raw mode for reading keys one at a time and returns
+// a function restoring the previous mode. Output processing is kept, so a newline
+// still starts a new line.
+func makeRaw(terminal *os.File) (func(), error) {
+	fd := terminal.Fd()
+	
+	var old syscall.Termios
+	if err := ioctlTermios(fd, ioctlGetTermios, &old); err != nil {
+		return nil, fmt.Errorf("failed to read terminal mode: %w", err)
+	}
+	
+	raw := old
+	raw.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctlTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, fmt.Errorf("failed to set terminal mode: %w", err)
+	}
+	
+	return func() {
+		ioctlTermios(fd, ioctlSetTermios, &old)
+	}, nil
+}
+
+// ioctlTermios reads or sets the mode of a terminal
+func ioctlTermios(fd uintptr, request uintptr, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}